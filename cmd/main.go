@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"runtime/debug"
 	"syscall"
+	"time"
 
 	"github.com/urfave/cli/v2"
 
@@ -31,17 +32,35 @@ func main() {
 	app.Name = "nssf"
 	app.Usage = "5G Network Slice Selection Function (NSSF)"
 	app.Action = action
+	app.Commands = []*cli.Command{
+		selectCommand,
+		validateCommand,
+		schemaCommand,
+	}
 	app.Flags = []cli.Flag{
 		&cli.StringFlag{
 			Name:    "config",
 			Aliases: []string{"c"},
 			Usage:   "Load configuration from `FILE`",
 		},
+		&cli.StringSliceFlag{
+			Name:  "config-dir",
+			Usage: "Merge configuration fragments in `DIR` over the configuration file, in the order of file names",
+		},
+		&cli.BoolFlag{
+			Name:  "print-config-sources",
+			Usage: "Log every effective configuration value with its source at startup",
+		},
 		&cli.StringSliceFlag{
 			Name:    "log",
 			Aliases: []string{"l"},
 			Usage:   "Output NF log to `FILE`",
 		},
+		&cli.DurationFlag{
+			Name:  "log-signal-duration",
+			Usage: "Revert log level raised by SIGUSR1 after `DURATION`, or keep it if 0",
+			Value: 10 * time.Minute,
+		},
 	}
 	if err := app.Run(os.Args); err != nil {
 		logger.MainLog.Errorf("NSSF Run Error: %v\n", err)
@@ -55,11 +74,16 @@ func action(cliCtx *cli.Context) error {
 	}
 
 	logger.MainLog.Infoln("NSSF version: ", version.GetVersion())
-	cfg, err := factory.ReadConfig(cliCtx.String("config"))
+	cfg, err := factory.ReadConfig(cliCtx.String("config"), cliCtx.StringSlice("config-dir")...)
 	if err != nil {
 		return err
 	}
 	factory.NssfConfig = cfg
+	if cliCtx.Bool("print-config-sources") {
+		for _, source := range cfg.GetConfigSources() {
+			logger.CfgLog.Infof("%s = %s (%s)", source.Path, source.Value, source.Source)
+		}
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	sigCh := make(chan os.Signal, 1)
@@ -70,6 +94,10 @@ func action(cliCtx *cli.Context) error {
 		cancel() // Notify each goroutine and wait them stopped
 	}()
 
+	logSigCh := make(chan os.Signal, 1)
+	signal.Notify(logSigCh, syscall.SIGUSR1, syscall.SIGUSR2)
+	go handleLogSignals(logSigCh, cliCtx.Duration("log-signal-duration"))
+
 	nssf, err := service.NewApp(ctx, cfg, tlsKeyLogPath)
 	if err != nil {
 		return err
@@ -81,6 +109,25 @@ func action(cliCtx *cli.Context) error {
 	return nil
 }
 
+// Raise the global log level one step towards trace temporarily on SIGUSR1, so that it could be raised
+// repeatedly, and revert all temporary log levels on SIGUSR2
+func handleLogSignals(sigCh <-chan os.Signal, d time.Duration) {
+	for sig := range sigCh {
+		switch sig {
+		case syscall.SIGUSR1:
+			lvl := logger.RaiseLevel(d)
+			if d > 0 {
+				logger.MainLog.Infof("Log level is raised to [%s] for %s", lvl, d)
+			} else {
+				logger.MainLog.Infof("Log level is raised to [%s]", lvl)
+			}
+		case syscall.SIGUSR2:
+			logger.RevertLevels()
+			logger.MainLog.Infof("Temporary log levels are reverted, log level is [%s]", logger.GetLevel("").Level)
+		}
+	}
+}
+
 func initLogFile(logNfPath []string) (string, error) {
 	logTlsKeyPath := ""
 
@@ -101,6 +148,8 @@ func initLogFile(logNfPath []string) (string, error) {
 		_, name := filepath.Split(factory.NssfDefaultTLSKeyLogPath)
 		logTlsKeyPath = filepath.Join(tmpDir, name)
 	}
+	// Log files follow the levels of categories as well
+	logger.FilterHooks()
 
 	return logTlsKeyPath, nil
 }
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"os"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/free5gc/nssf/pkg/factory"
+)
+
+var schemaCommand = &cli.Command{
+	Name:  "schema",
+	Usage: "Print JSON Schema of the configuration",
+	Description: "The schema is generated for configuration version " + factory.NssfExpectedConfigVersion +
+		", and could be used by editors and CI to validate configuration files written in YAML.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "output",
+			Aliases: []string{"o"},
+			Usage:   "Write the schema to `FILE` instead of stdout",
+		},
+	},
+	Action: schemaAction,
+}
+
+func schemaAction(cliCtx *cli.Context) error {
+	schema, err := factory.JSONSchema()
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+	schema = append(schema, '\n')
+
+	if output := cliCtx.String("output"); output != "" {
+		if err = os.WriteFile(output, schema, 0o644); err != nil {
+			return cli.Exit(err, 1)
+		}
+		return nil
+	}
+	if _, err = os.Stdout.Write(schema); err != nil {
+		return cli.Exit(err, 1)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/free5gc/nssf/pkg/factory"
+)
+
+func TestSchemaOutput(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "nssfcfg.schema.json")
+	app := &cli.App{Commands: []*cli.Command{schemaCommand}}
+	if err := app.Run([]string{"nssf", "schema", "--output", output}); err != nil {
+		t.Fatalf("Error running schema command: %v", err)
+	}
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Error reading schema: %v", err)
+	}
+	if !strings.HasSuffix(string(content), "}\n") {
+		t.Errorf("Expected schema to end with a newline")
+	}
+	var schema map[string]any
+	if err = json.Unmarshal(content, &schema); err != nil {
+		t.Fatalf("Error unmarshalling schema: %v", err)
+	}
+	if id := schema["$id"]; id != "urn:free5gc:nssf:nssfcfg:"+factory.NssfExpectedConfigVersion {
+		t.Errorf("Expected schema of version %s, got: %v", factory.NssfExpectedConfigVersion, id)
+	}
+}
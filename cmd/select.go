@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli/v2"
+
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/nsac"
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/pkg/factory"
+)
+
+// Query parameters of NSSelection Get, which are also the flags of select command
+var selectQueryParameters = []string{
+	"nf-type",
+	"nf-id",
+	"slice-info-request-for-registration",
+	"slice-info-request-for-pdu-session",
+	"home-plmn-id",
+	"tai",
+	"supported-features",
+}
+
+var selectCommand = &cli.Command{
+	Name:  "select",
+	Usage: "Run network slice selection against the configuration without starting NSSF",
+	Description: "The NSSelection query is given as flags named after the query parameters, " +
+		"and/or as a JSON object in the file given by --query. Flags take precedence over the JSON object. " +
+		"Structured parameters are given in JSON, as in the query string of NSSelection Get. " +
+		"Authorized Network Slice Info, or Problem Details on failure, is printed to stdout.",
+	Flags:  selectFlags(),
+	Action: selectAction,
+}
+
+func selectFlags() []cli.Flag {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Usage:   "Load configuration from `FILE`",
+		},
+		&cli.StringSliceFlag{
+			Name:  "config-dir",
+			Usage: "Merge configuration fragments in `DIR` over the configuration file, in the order of file names",
+		},
+		&cli.StringFlag{
+			Name:  "query",
+			Usage: "Load NSSelection query as JSON object from `FILE`, \"-\" for stdin",
+		},
+		&cli.BoolFlag{
+			Name:  "explain",
+			Usage: "Print the explanation of network slice selection along with Authorized Network Slice Info",
+		},
+	}
+	for _, name := range selectQueryParameters {
+		flags = append(flags, &cli.StringFlag{
+			Name:  name,
+			Usage: "Query parameter " + name + " of NSSelection Get",
+		})
+	}
+	return flags
+}
+
+func selectAction(cliCtx *cli.Context) error {
+	// Keep stdout for the result, and logs other than failures out of it
+	logger.Log.SetOutput(os.Stderr)
+	logger.Log.SetLevel(logrus.WarnLevel)
+
+	cfg, err := factory.ReadConfig(cliCtx.String("config"), cliCtx.StringSlice("config-dir")...)
+	if err != nil {
+		return cli.Exit(err, 2)
+	}
+	factory.NssfConfig = cfg
+
+	query, err := selectQuery(cliCtx)
+	if err != nil {
+		return cli.Exit(err, 2)
+	}
+
+	// Only the selection is run, which does not need the running NSSF
+	p := processor.NewProcessor(nil)
+	if cfg.IsNsacEnabled() {
+		if cfg.GetNsacMode() == factory.NsacModeNsacf {
+			logger.MainLog.Warnln("Network slice admission control by NSACF is skipped in offline selection")
+		} else {
+			p.SetAdmissionController(nsac.NewLocalController())
+		}
+	}
+
+	status, body, err := runSelection(p, query, cliCtx.Bool("explain"))
+	if err != nil {
+		return cli.Exit(err, 2)
+	}
+	if _, err = os.Stdout.Write(body); err != nil {
+		return cli.Exit(err, 2)
+	}
+	if status >= http.StatusBadRequest {
+		return cli.Exit(fmt.Sprintf("Network slice selection failed with status %d", status), 1)
+	}
+	return nil
+}
+
+// Build the query string of NSSelection Get from the JSON object and flags
+func selectQuery(cliCtx *cli.Context) (url.Values, error) {
+	query := url.Values{}
+
+	if path := cliCtx.String("query"); path != "" {
+		var data []byte
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read query %s failed: %w", path, err)
+		}
+
+		var parameters map[string]json.RawMessage
+		if err = json.Unmarshal(data, &parameters); err != nil {
+			return nil, fmt.Errorf("decode query %s failed: %w", path, err)
+		}
+		for name, value := range parameters {
+			// Strings are given as is, and others are given in JSON
+			var s string
+			if err = json.Unmarshal(value, &s); err == nil {
+				query.Set(name, s)
+			} else {
+				query.Set(name, string(value))
+			}
+		}
+	}
+
+	for _, name := range selectQueryParameters {
+		if cliCtx.IsSet(name) {
+			query.Set(name, cliCtx.String(name))
+		}
+	}
+
+	// The NF consumer is an AMF unknown to NSSF unless given
+	if query.Get("nf-type") == "" {
+		query.Set("nf-type", "AMF")
+	}
+	if query.Get("nf-id") == "" {
+		query.Set("nf-id", uuid.New().String())
+	}
+	return query, nil
+}
+
+// Run NSSelection Get as it is handled by the SBI server, and return the status and body of the response
+func runSelection(p *processor.Processor, query url.Values, explain bool) (int, []byte, error) {
+	gin.SetMode(gin.ReleaseMode)
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	c.Request = httptest.NewRequest(http.MethodGet,
+		factory.NssfNsselectResUriPrefix+"/network-slice-information?"+query.Encode(), nil)
+	if explain {
+		c.Request.Header.Set(processor.ExplainHeader, "true")
+	}
+
+	var param processor.NetworkSliceInformationGetQuery
+	if err := c.ShouldBindQuery(&param); err != nil {
+		return 0, nil, fmt.Errorf("invalid NSSelection query: %w", err)
+	}
+	p.NSSelectionSliceInformationGet(c, param)
+
+	body, err := selectionResult(httpRecorder)
+	if err != nil {
+		return 0, nil, err
+	}
+	return httpRecorder.Code, body, nil
+}
+
+// Indent JSON response, or combine the parts of explained response into a JSON object keyed by Content-Id
+func selectionResult(httpRecorder *httptest.ResponseRecorder) ([]byte, error) {
+	result := json.RawMessage(httpRecorder.Body.Bytes())
+
+	mediaType, params, err := mime.ParseMediaType(httpRecorder.Header().Get("Content-Type"))
+	if err == nil && mediaType == "multipart/related" {
+		parts := map[string]json.RawMessage{}
+		reader := multipart.NewReader(httpRecorder.Body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("read explained response failed: %w", err)
+			}
+			data, err := io.ReadAll(part)
+			if err != nil {
+				return nil, fmt.Errorf("read explained response failed: %w", err)
+			}
+			parts[part.Header.Get("Content-Id")] = bytes.TrimSpace(data)
+		}
+		if result, err = json.Marshal(parts); err != nil {
+			return nil, err
+		}
+	}
+
+	out := &bytes.Buffer{}
+	if err := json.Indent(out, result, "", "  "); err != nil {
+		return nil, fmt.Errorf("invalid response of network slice selection: %w", err)
+	}
+	out.WriteString("\n")
+	return out.Bytes(), nil
+}
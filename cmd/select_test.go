@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+const (
+	selectTestAmfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+	selectTestTai   = `{"plmnId":{"mcc":"208","mnc":"93"},"tac":"33456"}`
+)
+
+func TestRunSelection(t *testing.T) {
+	// The sample configuration is shared with the tests of configuration
+	cfg, err := factory.ReadConfig(filepath.Join("..", "pkg", "factory", "testdata", "nssfcfg.yaml"))
+	if err != nil {
+		t.Fatalf("Error reading configuration: %v", err)
+	}
+	origin := factory.NssfConfig
+	factory.NssfConfig = cfg
+	t.Cleanup(func() {
+		factory.NssfConfig = origin
+	})
+	p := processor.NewProcessor(nil)
+
+	testCases := []struct {
+		name      string
+		query     url.Values
+		explain   bool
+		expectErr bool
+		status    int
+		check     func(t *testing.T, body []byte)
+	}{
+		{
+			name: "Registration",
+			query: url.Values{
+				"nf-type": {"AMF"},
+				"nf-id":   {selectTestAmfId},
+				"tai":     {selectTestTai},
+				"slice-info-request-for-registration": {`{"subscribedNssai":[` +
+					`{"subscribedSnssai":{"sst":1,"sd":"010203"},"defaultIndication":true},` +
+					`{"subscribedSnssai":{"sst":2}}],"requestedNssai":[{"sst":1,"sd":"010203"}]}`},
+			},
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var info models.AuthorizedNetworkSliceInfo
+				if err := json.Unmarshal(body, &info); err != nil {
+					t.Fatalf("Error unmarshalling result: %v", err)
+				}
+				if len(info.AllowedNssaiList) != 1 || len(info.AllowedNssaiList[0].AllowedSnssaiList) != 1 {
+					t.Fatalf("Expected 1 allowed S-NSSAI, got: %+v", info.AllowedNssaiList)
+				}
+				allowed := info.AllowedNssaiList[0].AllowedSnssaiList[0].AllowedSnssai
+				if allowed.Sst != 1 || allowed.Sd != "010203" {
+					t.Errorf("Expected allowed S-NSSAI 1/010203, got: %+v", allowed)
+				}
+			},
+		},
+		{
+			name: "Registration with requested S-NSSAI not subscribed",
+			query: url.Values{
+				"nf-type": {"AMF"},
+				"nf-id":   {selectTestAmfId},
+				"tai":     {selectTestTai},
+				"slice-info-request-for-registration": {`{"subscribedNssai":[` +
+					`{"subscribedSnssai":{"sst":2},"defaultIndication":true}],` +
+					`"requestedNssai":[{"sst":1,"sd":"010203"}]}`},
+			},
+			status: http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var info models.AuthorizedNetworkSliceInfo
+				if err := json.Unmarshal(body, &info); err != nil {
+					t.Fatalf("Error unmarshalling result: %v", err)
+				}
+				if len(info.RejectedNssaiInPlmn) != 1 || info.RejectedNssaiInPlmn[0].Sd != "010203" {
+					t.Errorf("Expected S-NSSAI 1/010203 to be rejected in PLMN, got: %+v", info.RejectedNssaiInPlmn)
+				}
+			},
+		},
+		{
+			name: "PDU session with explanation",
+			query: url.Values{
+				"nf-type": {"AMF"},
+				"nf-id":   {selectTestAmfId},
+				"tai":     {selectTestTai},
+				"slice-info-request-for-pdu-session": {
+					`{"sNssai":{"sst":1,"sd":"010203"},"roamingIndication":"NON_ROAMING"}`,
+				},
+			},
+			explain: true,
+			status:  http.StatusOK,
+			check: func(t *testing.T, body []byte) {
+				var result struct {
+					AuthorizedNetworkSliceInfo models.AuthorizedNetworkSliceInfo `json:"authorizedNetworkSliceInfo"`
+					Explanation                *processor.Explanation            `json:"explanation"`
+				}
+				if err := json.Unmarshal(body, &result); err != nil {
+					t.Fatalf("Error unmarshalling result: %v", err)
+				}
+				nsiInformation := result.AuthorizedNetworkSliceInfo.NsiInformation
+				if nsiInformation == nil || nsiInformation.NsiId != "10" {
+					t.Errorf("Expected NSI 10 to be selected, got: %+v", nsiInformation)
+				}
+				if result.Explanation == nil || len(result.Explanation.Steps) == 0 {
+					t.Errorf("Expected explanation of the selection, got: %+v", result.Explanation)
+				}
+			},
+		},
+		{
+			name: "Without slice information request",
+			query: url.Values{
+				"nf-type": {"AMF"},
+				"nf-id":   {selectTestAmfId},
+				"tai":     {selectTestTai},
+			},
+			status: http.StatusBadRequest,
+			check: func(t *testing.T, body []byte) {
+				var problemDetails models.ProblemDetails
+				if err := json.Unmarshal(body, &problemDetails); err != nil {
+					t.Fatalf("Error unmarshalling result: %v", err)
+				}
+				if problemDetails.Status != http.StatusBadRequest {
+					t.Errorf("Expected problemDetails.Status to be %d, got: %d",
+						http.StatusBadRequest, problemDetails.Status)
+				}
+			},
+		},
+		{
+			name: "Malformed TAI",
+			query: url.Values{
+				"nf-type": {"AMF"},
+				"nf-id":   {selectTestAmfId},
+				"tai":     {"{"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body, err := runSelection(p, tc.query, tc.explain)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected selection to fail, got status %d: %s", status, body)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error running selection: %v", err)
+			}
+			if status != tc.status {
+				t.Fatalf("Expected status code %d, got: %d\n%s", tc.status, status, body)
+			}
+			if len(body) == 0 || body[len(body)-1] != '\n' {
+				t.Errorf("Expected result to end with a newline")
+			}
+			tc.check(t, body)
+		})
+	}
+}
+
+func TestSelectQuery(t *testing.T) {
+	queryFile := filepath.Join(t.TempDir(), "query.json")
+	content := `{"nf-id":"` + selectTestAmfId + `","tai":` + selectTestTai + `,"home-plmn-id":{"mcc":"208","mnc":"93"}}`
+	if err := os.WriteFile(queryFile, []byte(content), 0o600); err != nil {
+		t.Fatalf("Error writing query: %v", err)
+	}
+
+	var query url.Values
+	app := &cli.App{
+		Commands: []*cli.Command{
+			{
+				Name:  "select",
+				Flags: selectFlags(),
+				Action: func(cliCtx *cli.Context) error {
+					var err error
+					query, err = selectQuery(cliCtx)
+					return err
+				},
+			},
+		},
+	}
+	tai := `{"plmnId":{"mcc":"208","mnc":"93"},"tac":"33457"}`
+	if err := app.Run([]string{"nssf", "select", "--query", queryFile, "--tai", tai}); err != nil {
+		t.Fatalf("Error building query: %v", err)
+	}
+
+	expected := url.Values{
+		"nf-type":      {"AMF"},
+		"nf-id":        {selectTestAmfId},
+		"tai":          {tai},
+		"home-plmn-id": {`{"mcc":"208","mnc":"93"}`},
+	}
+	for name, value := range expected {
+		if query.Get(name) != value[0] {
+			t.Errorf("Expected %s to be %s, got: %s", name, value[0], query.Get(name))
+		}
+	}
+	if len(query) != len(expected) {
+		t.Errorf("Expected %d query parameters, got: %v", len(expected), query)
+	}
+}
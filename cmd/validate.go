@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/free5gc/nssf/pkg/factory"
+)
+
+var validateCommand = &cli.Command{
+	Name:  "validate",
+	Usage: "Validate and lint the configuration without starting NSSF",
+	Description: "Problems found by lint are printed to stdout with their YAML paths. " +
+		"Exits with status 1 if the configuration is invalid or any problem is found.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "config",
+			Aliases: []string{"c"},
+			Usage:   "Load configuration from `FILE`",
+		},
+		&cli.StringSliceFlag{
+			Name:  "config-dir",
+			Usage: "Merge configuration fragments in `DIR` over the configuration file, in the order of file names",
+		},
+	},
+	Action: validateAction,
+}
+
+func validateAction(cliCtx *cli.Context) error {
+	cfg, err := factory.ReadConfig(cliCtx.String("config"), cliCtx.StringSlice("config-dir")...)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
+	problems := cfg.Lint()
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) != 0 {
+		return cli.Exit(fmt.Sprintf("%d problem(s) found in configuration", len(problems)), 1)
+	}
+	return nil
+}
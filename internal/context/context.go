@@ -13,7 +13,9 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 
 	"github.com/free5gc/nssf/internal/logger"
@@ -24,11 +26,13 @@ import (
 
 const NRF_PORT = 29510
 
-var nssfContext = NSSFContext{}
+var nssfContext = NSSFContext{
+	Subscriptions: NewSubscriptionRegistry(),
+}
 
 // Initialize NSSF context with default value
 func Init() {
-	nssfContext.NfId = uuid.New().String()
+	nssfContext.SetNfId(uuid.New().String())
 
 	nssfContext.Name = "NSSF"
 
@@ -52,7 +56,8 @@ type NFContext interface {
 var _ NFContext = &NSSFContext{}
 
 type NSSFContext struct {
-	NfId         string
+	// ID of NF instance, which is replaced by the one assigned by NRF on registration
+	nfId         atomic.Pointer[string]
 	Name         string
 	UriScheme    models.UriScheme
 	RegisterIPv4 string
@@ -63,7 +68,14 @@ type NSSFContext struct {
 	NrfUri            string
 	NrfCertPem        string
 	SupportedPlmnList []models.PlmnId
-	OAuth2Required    bool
+	OAuth2Required    atomic.Bool
+	Subscriptions     *SubscriptionRegistry
+	// Subscription to NF status of AMFs in NRF
+	NrfAmfStatusSubscription NrfSubscription
+	// Whether NSSF is registered to NRF
+	NrfRegistered atomic.Bool
+	// Whether NSSF is shutting down, in which case it is no longer ready to serve
+	ShuttingDown atomic.Bool
 }
 
 // Initialize NSSF context with configuration factory
@@ -73,14 +85,15 @@ func InitNssfContext() {
 		nssfContext.Name = nssfConfig.Configuration.NssfName
 	}
 
-	nssfContext.NfId = nssfConfig.GetNfInstanceId()
+	nssfContext.SetNfId(nssfConfig.GetNfInstanceId())
 	nssfContext.Name = "NSSF"
 	nssfContext.UriScheme = nssfConfig.Configuration.Sbi.Scheme
 	nssfContext.RegisterIPv4 = nssfConfig.Configuration.Sbi.RegisterIPv4
 	nssfContext.SBIPort = nssfConfig.Configuration.Sbi.Port
 	nssfContext.BindingIPv4 = os.Getenv(nssfConfig.Configuration.Sbi.BindingIPv4)
 	if nssfContext.BindingIPv4 != "" {
-		logger.CtxLog.Info("Parsing ServerIPv4 address from ENV Variable.")
+		logger.CtxLog.Warnf("Parsing ServerIPv4 address from ENV Variable %s is deprecated, use %s instead",
+			nssfConfig.Configuration.Sbi.BindingIPv4, factory.NssfConfigEnvPrefix+"CONFIGURATION_SBI_BINDINGIPV4")
 	} else {
 		nssfContext.BindingIPv4 = nssfConfig.Configuration.Sbi.BindingIPv4
 		if nssfContext.BindingIPv4 == "" {
@@ -99,6 +112,7 @@ func InitNssfContext() {
 	}
 	nssfContext.NrfCertPem = nssfConfig.Configuration.NrfCertPem
 	nssfContext.SupportedPlmnList = nssfConfig.Configuration.SupportedPlmnList
+	nssfContext.Subscriptions.Load(nssfConfig.Subscriptions)
 }
 
 func initNfService(serviceName []models.ServiceName, version string) (
@@ -140,18 +154,29 @@ func GetSelf() *NSSFContext {
 	return &nssfContext
 }
 
+func (c *NSSFContext) NfId() string {
+	if nfId := c.nfId.Load(); nfId != nil {
+		return *nfId
+	}
+	return ""
+}
+
+func (c *NSSFContext) SetNfId(nfId string) {
+	c.nfId.Store(&nfId)
+}
+
 func (c *NSSFContext) GetTokenCtx(serviceName models.ServiceName, targetNF models.NrfNfManagementNfType) (
 	context.Context, *models.ProblemDetails, error,
 ) {
-	if !c.OAuth2Required {
+	if !c.OAuth2Required.Load() {
 		return context.TODO(), nil, nil
 	}
 	return oauth.GetTokenCtx(models.NrfNfManagementNfType_NSSF, targetNF,
-		c.NfId, c.NrfUri, string(serviceName))
+		c.NfId(), c.NrfUri, string(serviceName))
 }
 
 func (c *NSSFContext) AuthorizationCheck(token string, serviceName models.ServiceName) error {
-	if !c.OAuth2Required {
+	if !c.OAuth2Required.Load() {
 		logger.UtilLog.Debugf("NSSFContext::AuthorizationCheck: OAuth2 not required\n")
 		return nil
 	}
@@ -159,3 +184,52 @@ func (c *NSSFContext) AuthorizationCheck(token string, serviceName models.Servic
 	logger.UtilLog.Debugf("NSSFContext::AuthorizationCheck: token[%s] serviceName[%s]\n", token, serviceName)
 	return oauth.VerifyOAuth(token, string(serviceName), c.NrfCertPem)
 }
+
+// Get NF instance ID of the NF service consumer from the access token, which is verified by AuthorizationCheck,
+// and return empty string if OAuth2 is not required, since the consumer is not authenticated then
+func (c *NSSFContext) ConsumerNfId(token string) string {
+	if !c.OAuth2Required.Load() {
+		return ""
+	}
+
+	authFields := strings.Fields(token)
+	if len(authFields) < 2 {
+		return ""
+	}
+	var claims models.NrfAccessTokenAccessTokenClaims
+	if _, _, err := jwt.NewParser().ParseUnverified(authFields[1], &claims); err != nil {
+		logger.UtilLog.Debugf("NSSFContext::ConsumerNfId: Parse access token failed: %+v", err)
+		return ""
+	}
+	// Subject of the access token is the NF instance ID of the consumer
+	if _, err := uuid.Parse(claims.Sub); err != nil {
+		return ""
+	}
+	return claims.Sub
+}
+
+// Get NF instance ID of the NF service consumer from the headers of the request defined in TS 29.500,
+// i.e. srcinst of 3gpp-Sbi-NF-Peer-Info, or "<NF type>-<NF instance ID>" of User-Agent,
+// and return empty string if neither identifies the consumer
+// Unlike ConsumerNfId, the consumer is not authenticated, so that it is only used to tell which NF instance
+// a resource belongs to, e.g. for subscriptions to be removed along with the AMF
+func PeerNfId(peerInfo string, userAgent string) string {
+	for _, param := range strings.Split(peerInfo, ";") {
+		name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if !found || name != "srcinst" {
+			continue
+		}
+		if _, err := uuid.Parse(value); err == nil {
+			return value
+		}
+	}
+
+	if fields := strings.Fields(userAgent); len(fields) != 0 {
+		if _, nfId, found := strings.Cut(fields[0], "-"); found {
+			if _, err := uuid.Parse(nfId); err == nil {
+				return nfId
+			}
+		}
+	}
+	return ""
+}
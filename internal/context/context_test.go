@@ -0,0 +1,141 @@
+package context
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/free5gc/openapi/models"
+)
+
+func TestConsumerNfId(t *testing.T) {
+	const nfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+
+	token := func(sub string) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, models.NrfAccessTokenAccessTokenClaims{
+			Iss:   "nrf",
+			Sub:   sub,
+			Scope: string(models.ServiceName_NNSSF_NSSAIAVAILABILITY),
+		}).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatalf("Sign token failed: %+v", err)
+		}
+		return "Bearer " + signed
+	}
+
+	testCases := []struct {
+		name           string
+		oauth2Required bool
+		authorization  string
+		expectNfId     string
+	}{
+		{
+			name:           "NF instance ID from subject of access token",
+			oauth2Required: true,
+			authorization:  token(nfId),
+			expectNfId:     nfId,
+		},
+		{
+			name:           "Consumer is not authenticated without OAuth2",
+			oauth2Required: false,
+			authorization:  token(nfId),
+			expectNfId:     "",
+		},
+		{
+			name:           "Subject is not NF instance ID",
+			oauth2Required: true,
+			authorization:  token("AMF"),
+			expectNfId:     "",
+		},
+		{
+			name:           "Missing access token",
+			oauth2Required: true,
+			authorization:  "",
+			expectNfId:     "",
+		},
+		{
+			name:           "Malformed access token",
+			oauth2Required: true,
+			authorization:  "Bearer not-a-token",
+			expectNfId:     "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var c NSSFContext
+			c.OAuth2Required.Store(tc.oauth2Required)
+			if nfId := c.ConsumerNfId(tc.authorization); nfId != tc.expectNfId {
+				t.Errorf("Expected NF instance ID '%s', got: '%s'", tc.expectNfId, nfId)
+			}
+		})
+	}
+}
+
+func TestPeerNfId(t *testing.T) {
+	const nfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+
+	testCases := []struct {
+		name       string
+		peerInfo   string
+		userAgent  string
+		expectNfId string
+	}{
+		{"Source instance of peer info", "srcinst=" + nfId + "; dstinst=d6f8a1e4-9e36-4b2b-8f69-5c5a1c7e9a10", "", nfId},
+		{"Source instance after other parameters", "dstscp=scp.example.com;srcinst=" + nfId, "", nfId},
+		{"NF instance of User-Agent", "", "AMF-" + nfId, nfId},
+		{"Peer info preferred to User-Agent", "srcinst=" + nfId, "AMF-b9e6e2cb-5ce8-4cb6-9173-a266dd9a2f0c", nfId},
+		{"User-Agent without NF instance", "", "AMF", ""},
+		{"User-Agent of a library", "", "Go-http-client/1.1", ""},
+		{"Source instance is not NF instance ID", "srcinst=amf1", "", ""},
+		{"No headers", "", "", ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if nfId := PeerNfId(tc.peerInfo, tc.userAgent); nfId != tc.expectNfId {
+				t.Errorf("Expected NF instance ID '%s', got: '%s'", tc.expectNfId, nfId)
+			}
+		})
+	}
+}
+
+func TestNrfSubscriptionAuthenticate(t *testing.T) {
+	var s NrfSubscription
+	if s.Authenticate("", "") {
+		t.Errorf("Expected notification to be rejected without subscription")
+	}
+
+	s.Set("1", "token-1", time.Time{})
+	testCases := []struct {
+		name           string
+		notifyToken    string
+		subscriptionId string
+		expect         bool
+	}{
+		{"Token of subscription", "token-1", "", true},
+		{"Token and ID of subscription", "token-1", "1", true},
+		{"ID of another subscription", "token-1", "2", false},
+		{"Wrong token", "token-2", "1", false},
+		{"Empty token", "", "", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if ok := s.Authenticate(tc.notifyToken, tc.subscriptionId); ok != tc.expect {
+				t.Errorf("Expected authentication result %v, got: %v", tc.expect, ok)
+			}
+		})
+	}
+
+	// Subscription replaced by renewal is not cleared
+	s.Set("2", "token-2", time.Time{})
+	s.Clear("1")
+	if !s.Authenticate("token-2", "2") {
+		t.Errorf("Expected renewed subscription to be kept")
+	}
+	s.Clear("2")
+	if s.Authenticate("token-2", "") {
+		t.Errorf("Expected notification to be rejected after subscription is cleared")
+	}
+}
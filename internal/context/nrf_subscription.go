@@ -0,0 +1,66 @@
+package context
+
+import (
+	"crypto/subtle"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscription of NSSF in NRF to NF status of AMFs
+type NrfSubscription struct {
+	mu sync.RWMutex
+	id string
+	// Random token in the notification URI given to NRF only, which authenticates notifications,
+	// since NRF sends notifications without access token
+	notifyToken string
+	// Time when NRF expires the subscription, zero if it does not expire
+	validityTime time.Time
+}
+
+// Generate a token for the notification URI of a new subscription
+func NewNotifyToken() string {
+	return uuid.New().String()
+}
+
+// Set the subscription created in NRF, replacing the previous one
+func (s *NrfSubscription) Set(id, notifyToken string, validityTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.id, s.notifyToken, s.validityTime = id, notifyToken, validityTime
+}
+
+// Clear the subscription if it is still the one of the ID, i.e. not replaced
+func (s *NrfSubscription) Clear(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.id == id {
+		s.id, s.notifyToken, s.validityTime = "", "", time.Time{}
+	}
+}
+
+// Get the ID of the subscription, empty if there is no subscription
+func (s *NrfSubscription) Id() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.id
+}
+
+// Get the time when NRF expires the subscription, zero if it does not expire
+func (s *NrfSubscription) ValidityTime() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.validityTime
+}
+
+// Whether a notification is of the subscription, i.e. it is sent to the notification URI of the subscription,
+// and its subscription ID, which is optional in notifications, is the one of the subscription if present
+func (s *NrfSubscription) Authenticate(notifyToken, subscriptionId string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.id == "" || subtle.ConstantTimeCompare([]byte(notifyToken), []byte(s.notifyToken)) != 1 {
+		return false
+	}
+	return subscriptionId == "" || subscriptionId == s.id
+}
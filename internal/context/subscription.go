@@ -0,0 +1,200 @@
+package context
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/metrics/business"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+// Registry of NSSAI availability subscriptions indexed by subscription ID
+type SubscriptionRegistry struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*factory.Subscription
+	// Subscriptions whose latest notification is not delivered
+	undeliverable map[string]*DeliveryFailure
+	// NF instance ID of the subscriber, if known, indexed by subscription ID
+	owners map[string]string
+}
+
+// Failure of notification delivery to a subscriber
+type DeliveryFailure struct {
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	LastFailureTime     time.Time `json:"lastFailureTime"`
+	LastFailureCause    string    `json:"lastFailureCause"`
+}
+
+func NewSubscriptionRegistry() *SubscriptionRegistry {
+	return &SubscriptionRegistry{
+		subscriptions: make(map[string]*factory.Subscription),
+		undeliverable: make(map[string]*DeliveryFailure),
+		owners:        make(map[string]string),
+	}
+}
+
+// Try to acquire read lock on the registry, which is used to check that the registry is not wedged
+func (r *SubscriptionRegistry) TryRLock() bool {
+	return r.mu.TryRLock()
+}
+
+func (r *SubscriptionRegistry) RUnlock() {
+	r.mu.RUnlock()
+}
+
+// Load subscriptions provisioned in configuration, whose IDs are kept as is
+func (r *SubscriptionRegistry) Load(subscriptions []factory.Subscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, subscription := range subscriptions {
+		if subscription.SubscriptionId == "" || subscription.SubscriptionData == nil {
+			logger.CtxLog.Warnf("Ignore invalid subscription %+v in configuration", subscription)
+			continue
+		}
+		if _, exist := r.subscriptions[subscription.SubscriptionId]; exist {
+			logger.CtxLog.Warnf("Ignore duplicated subscription ID '%s' in configuration", subscription.SubscriptionId)
+			continue
+		}
+		r.subscriptions[subscription.SubscriptionId] = copySubscription(subscription)
+	}
+	business.SetNssaiAvailabilitySubscriptionGauge(len(r.subscriptions))
+}
+
+// Add a subscription with a newly allocated opaque ID
+// The subscription is owned by the NF instance of the subscriber if its ID is given
+func (r *SubscriptionRegistry) Add(
+	createData models.NssfEventSubscriptionCreateData, ownerNfId string,
+) factory.Subscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	subscriptionId := uuid.New().String()
+	for {
+		if _, exist := r.subscriptions[subscriptionId]; !exist {
+			break
+		}
+		subscriptionId = uuid.New().String()
+	}
+
+	subscription := &factory.Subscription{
+		SubscriptionId:   subscriptionId,
+		SubscriptionData: new(models.NssfEventSubscriptionCreateData),
+	}
+	*subscription.SubscriptionData = createData
+	r.subscriptions[subscriptionId] = subscription
+	if ownerNfId != "" {
+		r.owners[subscriptionId] = ownerNfId
+	}
+	business.SetNssaiAvailabilitySubscriptionGauge(len(r.subscriptions))
+
+	return *copySubscription(*subscription)
+}
+
+func (r *SubscriptionRegistry) Get(subscriptionId string) (factory.Subscription, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subscription, ok := r.subscriptions[subscriptionId]
+	if !ok {
+		return factory.Subscription{}, false
+	}
+	return *copySubscription(*subscription), true
+}
+
+// Remove the subscription, and return false if it does not exist
+func (r *SubscriptionRegistry) Remove(subscriptionId string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subscriptions[subscriptionId]; !ok {
+		return false
+	}
+	delete(r.subscriptions, subscriptionId)
+	delete(r.undeliverable, subscriptionId)
+	delete(r.owners, subscriptionId)
+	business.SetNssaiAvailabilitySubscriptionGauge(len(r.subscriptions))
+	return true
+}
+
+// Remove all subscriptions owned by the NF instance, and return IDs of the removed subscriptions
+func (r *SubscriptionRegistry) RemoveByOwner(nfId string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var removed []string
+	for subscriptionId, ownerNfId := range r.owners {
+		if ownerNfId != nfId {
+			continue
+		}
+		delete(r.subscriptions, subscriptionId)
+		delete(r.undeliverable, subscriptionId)
+		delete(r.owners, subscriptionId)
+		removed = append(removed, subscriptionId)
+	}
+	business.SetNssaiAvailabilitySubscriptionGauge(len(r.subscriptions))
+	return removed
+}
+
+// Record the result of notification delivery to the subscriber, where nil error means delivered
+func (r *SubscriptionRegistry) RecordDelivery(subscriptionId string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subscriptions[subscriptionId]; !ok {
+		return
+	}
+
+	if err == nil {
+		delete(r.undeliverable, subscriptionId)
+		return
+	}
+
+	failure, ok := r.undeliverable[subscriptionId]
+	if !ok {
+		failure = &DeliveryFailure{}
+		r.undeliverable[subscriptionId] = failure
+	}
+	failure.ConsecutiveFailures++
+	failure.LastFailureTime = time.Now()
+	failure.LastFailureCause = err.Error()
+}
+
+// Get the delivery failure of the subscription, and return false if the latest notification is delivered
+func (r *SubscriptionRegistry) GetDeliveryFailure(subscriptionId string) (DeliveryFailure, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	failure, ok := r.undeliverable[subscriptionId]
+	if !ok {
+		return DeliveryFailure{}, false
+	}
+	return *failure, true
+}
+
+// Number of subscriptions
+func (r *SubscriptionRegistry) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.subscriptions)
+}
+
+// List all subscriptions in no particular order
+func (r *SubscriptionRegistry) List() []factory.Subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subscriptions := make([]factory.Subscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, *copySubscription(*subscription))
+	}
+	return subscriptions
+}
+
+func copySubscription(subscription factory.Subscription) *factory.Subscription {
+	c := &factory.Subscription{
+		SubscriptionId: subscription.SubscriptionId,
+	}
+	if subscription.SubscriptionData != nil {
+		c.SubscriptionData = new(models.NssfEventSubscriptionCreateData)
+		*c.SubscriptionData = *subscription.SubscriptionData
+	}
+	return c
+}
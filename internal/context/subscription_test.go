@@ -0,0 +1,156 @@
+package context
+
+import (
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+func TestSubscriptionRegistryAdd(t *testing.T) {
+	r := NewSubscriptionRegistry()
+	createData := models.NssfEventSubscriptionCreateData{
+		NfNssaiAvailabilityUri: "https://amf.example.com/callback",
+		Event:                  models.NssfEventType_SNSSAI_STATUS_CHANGE_REPORT,
+	}
+
+	first := r.Add(createData, "")
+	second := r.Add(createData, "")
+	// Subscription IDs are opaque UUIDs which are never reused
+	for _, subscriptionId := range []string{first.SubscriptionId, second.SubscriptionId} {
+		if _, err := uuid.Parse(subscriptionId); err != nil {
+			t.Errorf("Expected subscription ID '%s' to be a UUID: %+v", subscriptionId, err)
+		}
+	}
+	if first.SubscriptionId == second.SubscriptionId {
+		t.Errorf("Expected distinct subscription IDs, got: '%s'", first.SubscriptionId)
+	}
+	if r.Count() != 2 {
+		t.Errorf("Expected 2 subscriptions, got: %d", r.Count())
+	}
+
+	subscription, ok := r.Get(first.SubscriptionId)
+	if !ok {
+		t.Fatalf("Expected subscription '%s' to exist", first.SubscriptionId)
+	}
+	if subscription.SubscriptionData.NfNssaiAvailabilityUri != createData.NfNssaiAvailabilityUri {
+		t.Errorf("Expected callback URI '%s', got: '%s'", createData.NfNssaiAvailabilityUri,
+			subscription.SubscriptionData.NfNssaiAvailabilityUri)
+	}
+
+	// The returned subscription is a copy
+	subscription.SubscriptionData.NfNssaiAvailabilityUri = "https://other.example.com/callback"
+	if s, _ := r.Get(first.SubscriptionId); s.SubscriptionData.NfNssaiAvailabilityUri !=
+		createData.NfNssaiAvailabilityUri {
+		t.Errorf("Expected stored subscription to be unchanged, got: '%s'", s.SubscriptionData.NfNssaiAvailabilityUri)
+	}
+
+	if _, ok := r.Get("unknown"); ok {
+		t.Errorf("Expected unknown subscription not to exist")
+	}
+}
+
+func TestSubscriptionRegistryRemove(t *testing.T) {
+	r := NewSubscriptionRegistry()
+	createData := models.NssfEventSubscriptionCreateData{
+		NfNssaiAvailabilityUri: "https://amf.example.com/callback",
+	}
+	owned1 := r.Add(createData, "nf1")
+	owned2 := r.Add(createData, "nf1")
+	other := r.Add(createData, "nf2")
+	anonymous := r.Add(createData, "")
+
+	removed := r.RemoveByOwner("nf1")
+	sort.Strings(removed)
+	expected := []string{owned1.SubscriptionId, owned2.SubscriptionId}
+	sort.Strings(expected)
+	if len(removed) != 2 || removed[0] != expected[0] || removed[1] != expected[1] {
+		t.Errorf("Expected subscriptions %v to be removed, got: %v", expected, removed)
+	}
+	if removed := r.RemoveByOwner("nf1"); len(removed) != 0 {
+		t.Errorf("Expected no subscription to be removed again, got: %v", removed)
+	}
+	if r.Count() != 2 {
+		t.Errorf("Expected 2 subscriptions, got: %d", r.Count())
+	}
+
+	if !r.Remove(anonymous.SubscriptionId) {
+		t.Errorf("Expected subscription '%s' to be removed", anonymous.SubscriptionId)
+	}
+	if r.Remove(anonymous.SubscriptionId) {
+		t.Errorf("Expected subscription '%s' not to be removed again", anonymous.SubscriptionId)
+	}
+
+	list := r.List()
+	if len(list) != 1 || list[0].SubscriptionId != other.SubscriptionId {
+		t.Errorf("Expected subscription '%s' only, got: %+v", other.SubscriptionId, list)
+	}
+}
+
+func TestSubscriptionRegistryLoad(t *testing.T) {
+	r := NewSubscriptionRegistry()
+	createData := &models.NssfEventSubscriptionCreateData{
+		NfNssaiAvailabilityUri: "https://amf.example.com/callback",
+	}
+
+	r.Load([]factory.Subscription{
+		{SubscriptionId: "1", SubscriptionData: createData},
+		{SubscriptionId: "", SubscriptionData: createData},
+		{SubscriptionId: "2", SubscriptionData: nil},
+		{SubscriptionId: "1", SubscriptionData: &models.NssfEventSubscriptionCreateData{}},
+	})
+
+	// Invalid and duplicated subscriptions are ignored
+	if r.Count() != 1 {
+		t.Fatalf("Expected 1 subscription, got: %d", r.Count())
+	}
+	subscription, ok := r.Get("1")
+	if !ok {
+		t.Fatalf("Expected subscription '1' to exist")
+	}
+	if subscription.SubscriptionData.NfNssaiAvailabilityUri != createData.NfNssaiAvailabilityUri {
+		t.Errorf("Expected the first subscription '1' to be kept, got: %+v", subscription.SubscriptionData)
+	}
+}
+
+func TestSubscriptionRegistryRecordDelivery(t *testing.T) {
+	r := NewSubscriptionRegistry()
+	subscription := r.Add(models.NssfEventSubscriptionCreateData{
+		NfNssaiAvailabilityUri: "https://amf.example.com/callback",
+	}, "")
+
+	if _, ok := r.GetDeliveryFailure(subscription.SubscriptionId); ok {
+		t.Errorf("Expected no delivery failure of new subscription")
+	}
+
+	r.RecordDelivery(subscription.SubscriptionId, errors.New("connection refused"))
+	r.RecordDelivery(subscription.SubscriptionId, errors.New("timeout"))
+	failure, ok := r.GetDeliveryFailure(subscription.SubscriptionId)
+	if !ok {
+		t.Fatalf("Expected delivery failure to be recorded")
+	}
+	if failure.ConsecutiveFailures != 2 || failure.LastFailureCause != "timeout" || failure.LastFailureTime.IsZero() {
+		t.Errorf("Expected 2 consecutive failures caused by timeout, got: %+v", failure)
+	}
+
+	// Delivery resets the failure
+	r.RecordDelivery(subscription.SubscriptionId, nil)
+	if _, ok := r.GetDeliveryFailure(subscription.SubscriptionId); ok {
+		t.Errorf("Expected delivery failure to be cleared")
+	}
+
+	// Failure of unknown or removed subscription is not recorded
+	r.RecordDelivery("unknown", errors.New("timeout"))
+	if _, ok := r.GetDeliveryFailure("unknown"); ok {
+		t.Errorf("Expected no delivery failure of unknown subscription")
+	}
+	r.RecordDelivery(subscription.SubscriptionId, errors.New("timeout"))
+	r.Remove(subscription.SubscriptionId)
+	if _, ok := r.GetDeliveryFailure(subscription.SubscriptionId); ok {
+		t.Errorf("Expected delivery failure to be removed with the subscription")
+	}
+}
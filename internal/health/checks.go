@@ -0,0 +1,146 @@
+/*
+ * NSSF Health
+ *
+ * Checks of NSSF state
+ */
+
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+// Time after which an in-flight request is considered to be handled by a wedged goroutine
+const wedgedRequestThreshold = time.Minute
+
+// Readiness check that the configuration is loaded and indexed for lookups
+// The configuration is validated when loaded, and every update of NSSAI availability is validated before applied
+func ConfigCheck(cfg *factory.Config) Check {
+	return Check{
+		Name: "config",
+		Run: func(ctx context.Context) error {
+			if cfg == nil {
+				return errors.New("configuration is not loaded")
+			}
+			if !LockAvailable(ctx, cfg) {
+				return errors.New("configuration is locked")
+			}
+			cfg.RLock()
+			loaded := cfg.Configuration != nil && cfg.Configuration.Sbi != nil
+			cfg.RUnlock()
+			if !loaded {
+				return errors.New("configuration is not loaded")
+			}
+			if cfg.Snapshot() == nil {
+				return errors.New("configuration is not indexed")
+			}
+			return nil
+		},
+	}
+}
+
+// Readiness check that NSSF is registered to NRF, so that it is discoverable by NF service consumers
+func NrfRegistrationCheck(nssfCtx *nssf_context.NSSFContext) Check {
+	return Check{
+		Name: "nrfRegistration",
+		Run: func(ctx context.Context) error {
+			if !nssfCtx.NrfRegistered.Load() {
+				return fmt.Errorf("not registered to NRF %s", nssfCtx.NrfUri)
+			}
+			return nil
+		},
+	}
+}
+
+// Readiness check that NSSF is not shutting down, so that no more traffic is routed to it while draining
+func ShutdownCheck(nssfCtx *nssf_context.NSSFContext) Check {
+	return Check{
+		Name: "shutdown",
+		Run: func(ctx context.Context) error {
+			if nssfCtx.ShuttingDown.Load() {
+				return errors.New("NSSF is shutting down")
+			}
+			return nil
+		},
+	}
+}
+
+// Readiness check that the stores of NSSAI availability and subscriptions could be accessed
+// It is not a liveness check, since a store could be locked for long by a legitimate update,
+// in which case NSSF should only be taken out of service until the update is done instead of restarted
+func StoreCheck(cfg *factory.Config, nssfCtx *nssf_context.NSSFContext) Check {
+	return Check{
+		Name: "store",
+		Run: func(ctx context.Context) error {
+			if !LockAvailable(ctx, cfg) {
+				return errors.New("NSSAI availability store is locked")
+			}
+			if !LockAvailable(ctx, nssfCtx.Subscriptions) {
+				return errors.New("subscription store is locked")
+			}
+			return nil
+		},
+	}
+}
+
+// Readiness check that the certificate and key of SBI server could be loaded, and the certificate is not expired
+func TlsCheck(cfg *factory.Config) Check {
+	return Check{
+		Name: "tls",
+		Run: func(ctx context.Context) error {
+			cfg.RLock()
+			sbiConfig := *cfg.Configuration.Sbi
+			cfg.RUnlock()
+			if sbiConfig.Scheme != models.UriScheme_HTTPS {
+				return nil
+			}
+
+			pemPath, keyPath := factory.NssfDefaultCertPemPath, factory.NssfDefaultPrivateKeyPath
+			if sbiConfig.Tls != nil {
+				if sbiConfig.Tls.Pem != "" {
+					pemPath = sbiConfig.Tls.Pem
+				}
+				if sbiConfig.Tls.Key != "" {
+					keyPath = sbiConfig.Tls.Key
+				}
+			}
+
+			certificate, err := tls.LoadX509KeyPair(pemPath, keyPath)
+			if err != nil {
+				return fmt.Errorf("load certificate %s and key %s failed: %w", pemPath, keyPath, err)
+			}
+			leaf, err := x509.ParseCertificate(certificate.Certificate[0])
+			if err != nil {
+				return fmt.Errorf("parse certificate %s failed: %w", pemPath, err)
+			}
+			now := time.Now()
+			if now.Before(leaf.NotBefore) || now.After(leaf.NotAfter) {
+				return fmt.Errorf("certificate %s is valid from %s to %s", pemPath,
+					leaf.NotBefore.Format(time.RFC3339), leaf.NotAfter.Format(time.RFC3339))
+			}
+			return nil
+		},
+	}
+}
+
+// Liveness check that no request has been handled for so long that its goroutine is considered wedged
+func RequestsCheck(tracker *InflightTracker) Check {
+	return Check{
+		Name: "requests",
+		Run: func(ctx context.Context) error {
+			if oldest := tracker.Oldest(); oldest > wedgedRequestThreshold {
+				return fmt.Errorf("a request has been in flight for %s", oldest.Truncate(time.Second))
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ * NSSF Health
+ *
+ * Checks behind liveness and readiness probes
+ */
+
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status of a check or of all checks of a probe
+const (
+	StatusUp   = "UP"
+	StatusDown = "DOWN"
+)
+
+// Time allowed for a check, a check taking longer is considered failed
+const checkTimeout = 2 * time.Second
+
+// Check of NSSF health, which returns an error describing the failure
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Result of all checks of a probe, which is up only if all checks are up
+type Report struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// Run the checks concurrently, and report the results in the order of checks
+func Run(ctx context.Context, checks []Check) Report {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	report := Report{
+		Status: StatusUp,
+		Checks: make([]CheckResult, len(checks)),
+	}
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			report.Checks[i] = CheckResult{Name: check.Name, Status: StatusUp}
+			if err := check.Run(ctx); err != nil {
+				report.Checks[i].Status = StatusDown
+				report.Checks[i].Detail = err.Error()
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+	}
+	return report
+}
+
+// Interval of attempts to acquire a lock held by others
+const lockRetryInterval = 10 * time.Millisecond
+
+// Read lock which could be tried without blocking, such as sync.RWMutex
+type TryRLocker interface {
+	TryRLock() bool
+	RUnlock()
+}
+
+// Check that read lock could be acquired before ctx is done, which fails if it is held by a wedged goroutine
+// The lock is tried without blocking, so that no goroutine is left blocked on a wedged lock
+func LockAvailable(ctx context.Context, locker TryRLocker) bool {
+	ticker := time.NewTicker(lockRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		if locker.TryRLock() {
+			locker.RUnlock()
+			return true
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRun(t *testing.T) {
+	up := Check{Name: "up", Run: func(ctx context.Context) error { return nil }}
+	down := Check{Name: "down", Run: func(ctx context.Context) error { return errors.New("failed") }}
+	wedged := Check{Name: "wedged", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	testCases := []struct {
+		name     string
+		checks   []Check
+		status   string
+		expected []CheckResult
+	}{
+		{
+			name:     "No checks",
+			status:   StatusUp,
+			expected: []CheckResult{},
+		},
+		{
+			name:     "All checks up",
+			checks:   []Check{up, up},
+			status:   StatusUp,
+			expected: []CheckResult{{Name: "up", Status: StatusUp}, {Name: "up", Status: StatusUp}},
+		},
+		{
+			name:   "Failed check",
+			checks: []Check{up, down},
+			status: StatusDown,
+			expected: []CheckResult{
+				{Name: "up", Status: StatusUp},
+				{Name: "down", Status: StatusDown, Detail: "failed"},
+			},
+		},
+		{
+			name:   "Check exceeding time allowed",
+			checks: []Check{wedged, up},
+			status: StatusDown,
+			expected: []CheckResult{
+				{Name: "wedged", Status: StatusDown, Detail: context.DeadlineExceeded.Error()},
+				{Name: "up", Status: StatusUp},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			report := Run(ctx, tc.checks)
+			if report.Status != tc.status {
+				t.Errorf("Expected status %s, got: %s", tc.status, report.Status)
+			}
+			if len(report.Checks) != len(tc.expected) {
+				t.Fatalf("Expected %d check results, got: %+v", len(tc.expected), report.Checks)
+			}
+			for i, result := range report.Checks {
+				if result != tc.expected[i] {
+					t.Errorf("Expected check result %+v, got: %+v", tc.expected[i], result)
+				}
+			}
+		})
+	}
+}
+
+func TestLockAvailable(t *testing.T) {
+	var mu sync.RWMutex
+	if !LockAvailable(context.Background(), &mu) {
+		t.Errorf("Expected free lock to be available")
+	}
+
+	mu.RLock()
+	if !LockAvailable(context.Background(), &mu) {
+		t.Errorf("Expected read-locked lock to be available")
+	}
+	mu.RUnlock()
+
+	goroutines := runtime.NumGoroutine()
+	mu.Lock()
+	for i := 0; i < 10; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		if LockAvailable(ctx, &mu) {
+			t.Errorf("Expected write-locked lock not to be available")
+		}
+		cancel()
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Errorf("Expected no goroutine left blocked on the lock, got %d more", n-goroutines)
+	}
+
+	// Lock released before ctx is done
+	time.AfterFunc(20*time.Millisecond, mu.Unlock)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if !LockAvailable(ctx, &mu) {
+		t.Errorf("Expected lock to be available once released")
+	}
+}
+
+func TestInflightTracker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tracker := NewInflightTracker()
+	router := gin.New()
+	router.Use(tracker.Middleware())
+	release := make(chan struct{})
+	router.GET("/", func(c *gin.Context) {
+		<-release
+		c.Status(http.StatusNoContent)
+	})
+
+	if err := tracker.Wait(context.Background()); err != nil {
+		t.Errorf("Expected no wait without in-flight requests, got: %+v", err)
+	}
+	if oldest := tracker.Oldest(); oldest != 0 {
+		t.Errorf("Expected zero age without in-flight requests, got: %s", oldest)
+	}
+
+	done := make(chan struct{})
+	for i := 0; i < 2; i++ {
+		go func() {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			done <- struct{}{}
+		}()
+	}
+	deadline := time.Now().Add(time.Second)
+	for tracker.Count() != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout waiting for requests to be in flight")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if oldest := tracker.Oldest(); oldest < 20*time.Millisecond {
+		t.Errorf("Expected age of oldest request to be at least 20ms, got: %s", oldest)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := tracker.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected wait to be timed out with requests in flight, got: %+v", err)
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- tracker.Wait(context.Background())
+	}()
+	close(release)
+	<-done
+	<-done
+	select {
+	case err := <-waited:
+		if err != nil {
+			t.Errorf("Expected wait to return once requests are done, got: %+v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Timeout waiting for requests to be drained")
+	}
+	if count := tracker.Count(); count != 0 {
+		t.Errorf("Expected no in-flight request, got: %d", count)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ * NSSF Health
+ *
+ * Tracking of in-flight requests
+ */
+
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tracker of requests being handled, to find requests whose handling goroutines are wedged
+type InflightTracker struct {
+	mu       sync.Mutex
+	nextId   uint64
+	inflight map[uint64]time.Time
+	// Closed when no request is in flight
+	idle chan struct{}
+}
+
+func NewInflightTracker() *InflightTracker {
+	idle := make(chan struct{})
+	close(idle)
+	return &InflightTracker{
+		inflight: make(map[uint64]time.Time),
+		idle:     idle,
+	}
+}
+
+// Middleware tracking requests from their arrival until their handlers return
+func (t *InflightTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := t.start()
+		defer t.done(id)
+		c.Next()
+	}
+}
+
+func (t *InflightTracker) start() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.inflight) == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.nextId++
+	t.inflight[t.nextId] = time.Now()
+	return t.nextId
+}
+
+func (t *InflightTracker) done(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.inflight, id)
+	if len(t.inflight) == 0 {
+		close(t.idle)
+	}
+}
+
+// Wait until no request is in flight, or ctx is done
+func (t *InflightTracker) Wait(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		idle := t.idle
+		t.mu.Unlock()
+
+		select {
+		case <-idle:
+			// Requests might have arrived in the meantime
+			if t.Count() == 0 {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Get the number of in-flight requests
+func (t *InflightTracker) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.inflight)
+}
+
+// Get how long the oldest in-flight request has been handled, zero if there is none
+func (t *InflightTracker) Oldest() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var oldest time.Duration
+	now := time.Now()
+	for _, start := range t.inflight {
+		if d := now.Sub(start); d > oldest {
+			oldest = d
+		}
+	}
+	return oldest
+}
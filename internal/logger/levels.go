@@ -0,0 +1,266 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	logger_util "github.com/free5gc/util/logger"
+)
+
+// Levels of logging, globally and per category
+// A level is either kept until it is changed, or temporary and reverted after a duration
+// The level of Log is set to the most verbose level in effect, and while any category has its own level,
+// entries are dropped by the formatter and filtered hooks of Log if they are above the level of their category
+var levels = struct {
+	sync.RWMutex
+	// Level kept globally
+	global logrus.Level
+	// Levels kept per category, overriding the global level
+	categories map[string]logrus.Level
+	// Temporary levels overriding the kept ones, keyed by category and "" for the global level
+	temporary map[string]*temporaryLevel
+}{
+	categories: make(map[string]logrus.Level),
+	temporary:  make(map[string]*temporaryLevel),
+}
+
+type temporaryLevel struct {
+	level    logrus.Level
+	revertAt time.Time
+	timer    *time.Timer
+}
+
+// Level in effect globally or of a category
+type LevelInfo struct {
+	// Empty for the global level
+	Category string
+	Level    logrus.Level
+	// Whether the category has its own level, always false for the global level
+	Overridden bool
+	// Time when the temporary level is reverted, zero if the level is kept
+	RevertAt time.Time
+}
+
+// Get the names of log categories
+func Categories() []string {
+	return append([]string(nil), categories...)
+}
+
+// Find the category by its name case-insensitively, with or without the suffix "Log",
+// so that a category could also be given by the name of its logger, e.g. NsselLog
+func LookupCategory(name string) (string, error) {
+	for _, category := range categories {
+		if strings.EqualFold(name, category) || strings.EqualFold(name, category+"Log") {
+			return category, nil
+		}
+	}
+	return "", fmt.Errorf("unknown log category %q, which should be one of %s", name, strings.Join(categories, ", "))
+}
+
+// Set the level globally if category is empty, or of the category
+// The level is reverted to the one kept before after d if d is positive, otherwise it is kept until changed
+func SetLevel(category string, level logrus.Level, d time.Duration) {
+	levels.Lock()
+	defer levels.Unlock()
+	setLevel(category, level, d)
+}
+
+func setLevel(category string, level logrus.Level, d time.Duration) {
+	if temp, ok := levels.temporary[category]; ok {
+		temp.timer.Stop()
+		delete(levels.temporary, category)
+	}
+
+	if d > 0 {
+		temp := &temporaryLevel{
+			level:    level,
+			revertAt: time.Now().Add(d),
+		}
+		temp.timer = time.AfterFunc(d, func() {
+			revertLevel(category, temp)
+		})
+		levels.temporary[category] = temp
+	} else if category == "" {
+		levels.global = level
+	} else {
+		levels.categories[category] = level
+	}
+	applyLevels()
+}
+
+// Clear the level of the category, both kept and temporary, so that the global level is in effect for it
+func ResetLevel(category string) {
+	levels.Lock()
+	defer levels.Unlock()
+
+	if temp, ok := levels.temporary[category]; ok {
+		temp.timer.Stop()
+		delete(levels.temporary, category)
+	}
+	delete(levels.categories, category)
+	applyLevels()
+}
+
+// Revert all temporary levels immediately
+func RevertLevels() {
+	levels.Lock()
+	defer levels.Unlock()
+
+	for category, temp := range levels.temporary {
+		temp.timer.Stop()
+		delete(levels.temporary, category)
+	}
+	applyLevels()
+}
+
+func revertLevel(category string, temp *temporaryLevel) {
+	levels.Lock()
+	// The temporary level may have been replaced before the timer fires
+	if levels.temporary[category] != temp {
+		levels.Unlock()
+		return
+	}
+	delete(levels.temporary, category)
+	applyLevels()
+	info := levelInfo(category)
+	levels.Unlock()
+
+	if category == "" {
+		MainLog.Infof("Temporary log level is reverted to [%s]", info.Level)
+	} else if info.Overridden {
+		MainLog.Infof("Temporary log level of [%s] is reverted to [%s]", category, info.Level)
+	} else {
+		MainLog.Infof("Temporary log level of [%s] is reverted to global level [%s]", category, info.Level)
+	}
+}
+
+// Get the level in effect globally if category is empty, or of the category
+func GetLevel(category string) LevelInfo {
+	levels.RLock()
+	defer levels.RUnlock()
+	return levelInfo(category)
+}
+
+// Get the levels in effect globally and of every category, with the global level first
+func GetLevels() []LevelInfo {
+	levels.RLock()
+	defer levels.RUnlock()
+
+	infos := []LevelInfo{levelInfo("")}
+	for _, category := range categories {
+		infos = append(infos, levelInfo(category))
+	}
+	return infos
+}
+
+func levelInfo(category string) LevelInfo {
+	info := LevelInfo{Category: category}
+	if temp, ok := levels.temporary[category]; ok {
+		info.Level, info.Overridden, info.RevertAt = temp.level, category != "", temp.revertAt
+		return info
+	}
+	if category == "" {
+		info.Level = levels.global
+		return info
+	}
+	if level, ok := levels.categories[category]; ok {
+		info.Level, info.Overridden = level, true
+		return info
+	}
+	global := levelInfo("")
+	info.Level, info.RevertAt = global.Level, global.RevertAt
+	return info
+}
+
+// Whether any category has its own level, kept or temporary
+func filtering() bool {
+	if len(levels.categories) != 0 {
+		return true
+	}
+	for category := range levels.temporary {
+		if category != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Set Log to the most verbose level in effect
+func applyLevels() {
+	level := levelInfo("").Level
+	for _, category := range categories {
+		if l := levelInfo(category).Level; l > level {
+			level = l
+		}
+	}
+	Log.SetLevel(level)
+}
+
+// Filter the hooks of Log, such as log files, by the levels of categories
+// It should be called once hooks are added at startup, since hooks added afterwards are not filtered
+func FilterHooks() {
+	// Hooks are taken and replaced under the lock of Log
+	hooks := Log.ReplaceHooks(make(logrus.LevelHooks))
+	filtered := make(logrus.LevelHooks)
+	for lvl, levelHooks := range hooks {
+		for _, hook := range levelHooks {
+			if _, ok := hook.(*levelFilterHook); !ok {
+				hook = &levelFilterHook{Hook: hook}
+			}
+			filtered[lvl] = append(filtered[lvl], hook)
+		}
+	}
+	Log.ReplaceHooks(filtered)
+}
+
+// Whether the entry is within the level of its category
+func levelEnabled(entry *logrus.Entry) bool {
+	levels.RLock()
+	defer levels.RUnlock()
+
+	if !filtering() {
+		return true
+	}
+	// Entries without category, e.g. of NfLog, are filtered by the global level
+	category, _ := entry.Data[logger_util.FieldCategory].(string)
+	return entry.Level <= levelInfo(category).Level
+}
+
+type levelFilterFormatter struct {
+	logrus.Formatter
+}
+
+func (f *levelFilterFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !levelEnabled(entry) {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+type levelFilterHook struct {
+	logrus.Hook
+}
+
+func (h *levelFilterHook) Fire(entry *logrus.Entry) error {
+	if !levelEnabled(entry) {
+		return nil
+	}
+	return h.Hook.Fire(entry)
+}
+
+// Raise the global level by one step towards trace for d, and return the level in effect
+func RaiseLevel(d time.Duration) logrus.Level {
+	levels.Lock()
+	defer levels.Unlock()
+
+	level := levelInfo("").Level
+	if level < logrus.TraceLevel {
+		level++
+	}
+	setLevel("", level, d)
+	return level
+}
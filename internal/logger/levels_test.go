@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Output shared with the reverts of temporary levels, which log on their own goroutines
+type syncBuffer struct {
+	sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Reset() {
+	b.Lock()
+	defer b.Unlock()
+	b.buf.Reset()
+}
+
+func (b *syncBuffer) String() string {
+	b.Lock()
+	defer b.Unlock()
+	return b.buf.String()
+}
+
+func TestCategoryLevels(t *testing.T) {
+	out := &syncBuffer{}
+	origOut := Log.Out
+	Log.SetOutput(out)
+	SetLevel("", logrus.InfoLevel, 0)
+	defer func() {
+		RevertLevels()
+		for _, category := range Categories() {
+			ResetLevel(category)
+		}
+		SetLevel("", logrus.InfoLevel, 0)
+		Log.SetOutput(origOut)
+	}()
+
+	logged := func(entry *logrus.Entry, msg string) bool {
+		out.Reset()
+		entry.Debug(msg)
+		return strings.Contains(out.String(), msg)
+	}
+
+	nssel, err := LookupCategory("NsselLog")
+	if err != nil {
+		t.Fatalf("LookupCategory failed: %+v", err)
+	}
+	SetLevel(nssel, logrus.DebugLevel, 0)
+	if !logged(NsselLog, "nssel debug") {
+		t.Errorf("Expected debug log of category %s", nssel)
+	}
+	if logged(ConsumerLog, "consumer debug") {
+		t.Errorf("Expected no debug log of other categories")
+	}
+
+	SetLevel("", logrus.DebugLevel, 50*time.Millisecond)
+	SetLevel(nssel, logrus.WarnLevel, 50*time.Millisecond)
+	if !logged(ConsumerLog, "consumer debug") {
+		t.Errorf("Expected debug log of other categories with temporary global level")
+	}
+	if logged(NsselLog, "nssel debug") {
+		t.Errorf("Expected no debug log of category %s with temporary level", nssel)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if info := GetLevel(""); info.Level != logrus.InfoLevel || !info.RevertAt.IsZero() {
+		t.Errorf("Expected global level to be reverted to %s, got: %+v", logrus.InfoLevel, info)
+	}
+	if info := GetLevel(nssel); info.Level != logrus.DebugLevel || !info.Overridden {
+		t.Errorf("Expected level of category %s to be reverted to %s, got: %+v", nssel, logrus.DebugLevel, info)
+	}
+	if logged(ConsumerLog, "consumer debug") {
+		t.Errorf("Expected no debug log of other categories after revert")
+	}
+
+	ResetLevel(nssel)
+	if logged(NsselLog, "nssel debug") {
+		t.Errorf("Expected no debug log of category %s after reset", nssel)
+	}
+}
+
+type countingHook struct {
+	sync.Mutex
+	count int
+}
+
+func (h *countingHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h *countingHook) Fire(entry *logrus.Entry) error {
+	h.Lock()
+	defer h.Unlock()
+	h.count++
+	return nil
+}
+
+func (h *countingHook) Count() int {
+	h.Lock()
+	defer h.Unlock()
+	return h.count
+}
+
+func TestFilterHooks(t *testing.T) {
+	origOut, origHooks := Log.Out, Log.ReplaceHooks(make(logrus.LevelHooks))
+	Log.SetOutput(&syncBuffer{})
+	hook := &countingHook{}
+	Log.AddHook(hook)
+	FilterHooks()
+	SetLevel("", logrus.InfoLevel, 0)
+	defer func() {
+		ResetLevel("Consumer")
+		SetLevel("", logrus.InfoLevel, 0)
+		Log.ReplaceHooks(origHooks)
+		Log.SetOutput(origOut)
+	}()
+
+	SetLevel("Consumer", logrus.DebugLevel, 0)
+	NsselLog.Debug("nssel debug")
+	ConsumerLog.Debug("consumer debug")
+	if count := hook.Count(); count != 1 {
+		t.Errorf("Expected hook to be fired for debug log of category Consumer only, got %d times", count)
+	}
+
+	// Levels are changed while logging
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ConsumerLog.Debug("consumer debug")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				SetLevel("Consumer", logrus.DebugLevel, 0)
+			}
+		}()
+	}
+	wg.Wait()
+	if count := hook.Count(); count != 401 {
+		t.Errorf("Expected hook to be fired for every debug log of category Consumer, got %d times", count)
+	}
+}
+
+func TestRaiseLevelConcurrently(t *testing.T) {
+	SetLevel("", logrus.PanicLevel, 0)
+	defer SetLevel("", logrus.InfoLevel, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			RaiseLevel(0)
+		}()
+	}
+	wg.Wait()
+	if level := GetLevel("").Level; level != logrus.InfoLevel {
+		t.Errorf("Expected every raise to take effect to %s, got: %s", logrus.InfoLevel, level)
+	}
+}
@@ -20,8 +20,18 @@ var (
 	NsselLog      *logrus.Entry
 	NssaiavailLog *logrus.Entry
 	UtilLog       *logrus.Entry
+	CallbackLog   *logrus.Entry
+	TracingLog    *logrus.Entry
 )
 
+// Names of log categories, in the order they are created
+var categories []string
+
+func newCategoryLog(category string) *logrus.Entry {
+	categories = append(categories, category)
+	return NfLog.WithField(logger_util.FieldCategory, category)
+}
+
 func init() {
 	fieldsOrder := []string{
 		logger_util.FieldNF,
@@ -29,15 +39,21 @@ func init() {
 	}
 	Log = logger_util.New(fieldsOrder)
 	NfLog = Log.WithField(logger_util.FieldNF, "NSSF")
-	MainLog = NfLog.WithField(logger_util.FieldCategory, "Main")
-	InitLog = NfLog.WithField(logger_util.FieldCategory, "Init")
-	CfgLog = NfLog.WithField(logger_util.FieldCategory, "CFG")
-	CtxLog = NfLog.WithField(logger_util.FieldCategory, "CTX")
-	GinLog = NfLog.WithField(logger_util.FieldCategory, "GIN")
-	SBILog = NfLog.WithField(logger_util.FieldCategory, "SBI")
-	ConsumerLog = NfLog.WithField(logger_util.FieldCategory, "Consumer")
-	ProcLog = NfLog.WithField(logger_util.FieldCategory, "Proc")
-	NsselLog = NfLog.WithField(logger_util.FieldCategory, "NsSel")
-	NssaiavailLog = NfLog.WithField(logger_util.FieldCategory, "NssaiAvail")
-	UtilLog = NfLog.WithField(logger_util.FieldCategory, "Util")
+	MainLog = newCategoryLog("Main")
+	InitLog = newCategoryLog("Init")
+	CfgLog = newCategoryLog("CFG")
+	CtxLog = newCategoryLog("CTX")
+	GinLog = newCategoryLog("GIN")
+	SBILog = newCategoryLog("SBI")
+	ConsumerLog = newCategoryLog("Consumer")
+	ProcLog = newCategoryLog("Proc")
+	NsselLog = newCategoryLog("NsSel")
+	NssaiavailLog = newCategoryLog("NssaiAvail")
+	UtilLog = newCategoryLog("Util")
+	CallbackLog = newCategoryLog("Callback")
+	TracingLog = newCategoryLog("Tracing")
+
+	// Entries are filtered by the levels of their categories before they are formatted or sent to hooks
+	Log.SetFormatter(&levelFilterFormatter{Formatter: Log.Formatter})
+	levels.global = Log.GetLevel()
 }
@@ -0,0 +1,119 @@
+package business
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/util/metrics/utils"
+)
+
+func TestBusinessMetrics(t *testing.T) {
+	// Metrics are not recorded before business metrics are enabled, when collectors may not be created
+	IncrNsselectionSnssaiCounter(OutcomeAllowed, &models.Snssai{Sst: 1}, nil)
+	IncrNsselectionNsiCounter("1")
+	ObserveNsselectionCandidateAmfListSize(1)
+	SetNssaiAvailabilityAmfGauge(1)
+	SetNssaiAvailabilitySubscriptionGauge(1)
+	SetNrfRegistrationGauge(true)
+
+	registry := prometheus.NewRegistry()
+	for _, collector := range GetBusinessMetrics("free5gc") {
+		if err := registry.Register(collector); err != nil {
+			t.Fatalf("Register collector failed: %+v", err)
+		}
+	}
+	utils.EnableBusinessMetrics()
+
+	plmnId := &models.PlmnId{Mcc: "208", Mnc: "93"}
+	IncrNsselectionSnssaiCounter(OutcomeAllowed, &models.Snssai{Sst: 1}, plmnId)
+	IncrNsselectionSnssaiCounter(OutcomeAllowed, &models.Snssai{Sst: 1, Sd: "ABCDEF"}, plmnId)
+	IncrNsselectionSnssaiCounter(OutcomeAllowed, &models.Snssai{Sst: 1, Sd: "abcdef"}, plmnId)
+	IncrNsselectionSnssaiCounter(OutcomeRejectedInPlmn, nil, plmnId)
+	IncrNsselectionSnssaiCounter(OutcomeRejectedInPlmn, nil, nil)
+	IncrNsselectionSnssaiCounter(OutcomeRejectedInTa, &models.Snssai{Sst: 2}, nil)
+
+	snssaiTestCases := []struct {
+		outcome string
+		snssai  string
+		plmnId  string
+		expect  float64
+	}{
+		{OutcomeAllowed, "1", "20893", 1},
+		{OutcomeAllowed, "1-abcdef", "20893", 2},
+		{OutcomeRejectedInPlmn, OTHER_LABEL_VALUE, "20893", 1},
+		{OutcomeRejectedInPlmn, OTHER_LABEL_VALUE, OTHER_LABEL_VALUE, 1},
+		{OutcomeRejectedInTa, "2", OTHER_LABEL_VALUE, 1},
+	}
+	for _, tc := range snssaiTestCases {
+		value := testutil.ToFloat64(NsselectionSnssaiCounter.With(prometheus.Labels{
+			OUTCOME_LABEL: tc.outcome,
+			SNSSAI_LABEL:  tc.snssai,
+			PLMN_ID_LABEL: tc.plmnId,
+		}))
+		if value != tc.expect {
+			t.Errorf("Expected %v S-NSSAIs %s %s in PLMN %s, got: %v", tc.expect, tc.snssai, tc.outcome, tc.plmnId, value)
+		}
+	}
+	if count := testutil.CollectAndCount(NsselectionSnssaiCounter); count != len(snssaiTestCases) {
+		t.Errorf("Expected %d series of S-NSSAIs, got: %d", len(snssaiTestCases), count)
+	}
+
+	IncrNsselectionNsiCounter("1")
+	IncrNsselectionNsiCounter("1")
+	IncrNsselectionNsiCounter("2")
+	if value := testutil.ToFloat64(NsselectionNsiCounter.WithLabelValues("1")); value != 2 {
+		t.Errorf("Expected NSI 1 selected 2 times, got: %v", value)
+	}
+	if value := testutil.ToFloat64(NsselectionNsiCounter.WithLabelValues("2")); value != 1 {
+		t.Errorf("Expected NSI 2 selected 1 time, got: %v", value)
+	}
+
+	ObserveNsselectionCandidateAmfListSize(0)
+	ObserveNsselectionCandidateAmfListSize(3)
+	if count := testutil.CollectAndCount(NsselectionCandidateAmfHist); count != 1 {
+		t.Errorf("Expected 1 histogram of candidate AMFs, got: %d", count)
+	}
+
+	SetNssaiAvailabilityAmfGauge(3)
+	if value := testutil.ToFloat64(NssaiAvailabilityAmfGauge); value != 3 {
+		t.Errorf("Expected 3 AMFs, got: %v", value)
+	}
+	SetNssaiAvailabilitySubscriptionGauge(5)
+	SetNssaiAvailabilitySubscriptionGauge(4)
+	if value := testutil.ToFloat64(NssaiAvailabilitySubscrGauge); value != 4 {
+		t.Errorf("Expected 4 subscriptions, got: %v", value)
+	}
+	SetNrfRegistrationGauge(true)
+	if value := testutil.ToFloat64(NrfRegistrationGauge); value != 1 {
+		t.Errorf("Expected registered to NRF, got: %v", value)
+	}
+	SetNrfRegistrationGauge(false)
+	if value := testutil.ToFloat64(NrfRegistrationGauge); value != 0 {
+		t.Errorf("Expected deregistered from NRF, got: %v", value)
+	}
+
+	// Metrics are exposed with the namespace and subsystem
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather metrics failed: %+v", err)
+	}
+	names := make(map[string]bool)
+	for _, family := range families {
+		names[family.GetName()] = true
+	}
+	for _, name := range []string{
+		"free5gc_nssf_" + NSSELECTION_SNSSAI_COUNTER_NAME,
+		"free5gc_nssf_" + NSSELECTION_NSI_COUNTER_NAME,
+		"free5gc_nssf_" + NSSELECTION_CANDIDATE_AMF_HIST_NAME,
+		"free5gc_nssf_" + NSSAIAVAILABILITY_AMF_GAUGE_NAME,
+		"free5gc_nssf_" + NSSAIAVAILABILITY_SUBSCRIPTION_GAUGE_NAME,
+		"free5gc_nssf_" + NRF_REGISTRATION_GAUGE_NAME,
+	} {
+		if !names[name] {
+			t.Errorf("Expected metric %s to be gathered, got: %v", name, names)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package business
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/free5gc/util/metrics/utils"
+)
+
+func GetNrfMetrics(namespace string) []prometheus.Collector {
+	var metrics []prometheus.Collector
+
+	NrfRegistrationGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: SUBSYSTEM_NAME,
+			Name:      NRF_REGISTRATION_GAUGE_NAME,
+			Help:      NRF_REGISTRATION_GAUGE_DESC,
+		},
+	)
+
+	metrics = append(metrics, NrfRegistrationGauge)
+
+	return metrics
+}
+
+func SetNrfRegistrationGauge(registered bool) {
+	if utils.IsBusinessMetricsEnabled() {
+		if registered {
+			NrfRegistrationGauge.Set(1)
+		} else {
+			NrfRegistrationGauge.Set(0)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package business
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/free5gc/util/metrics/utils"
+)
+
+func GetNssaiAvailabilityMetrics(namespace string) []prometheus.Collector {
+	var metrics []prometheus.Collector
+
+	NssaiAvailabilityAmfGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: SUBSYSTEM_NAME,
+			Name:      NSSAIAVAILABILITY_AMF_GAUGE_NAME,
+			Help:      NSSAIAVAILABILITY_AMF_GAUGE_DESC,
+		},
+	)
+
+	metrics = append(metrics, NssaiAvailabilityAmfGauge)
+
+	NssaiAvailabilitySubscrGauge = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: SUBSYSTEM_NAME,
+			Name:      NSSAIAVAILABILITY_SUBSCRIPTION_GAUGE_NAME,
+			Help:      NSSAIAVAILABILITY_SUBSCRIPTION_GAUGE_DESC,
+		},
+	)
+
+	metrics = append(metrics, NssaiAvailabilitySubscrGauge)
+
+	return metrics
+}
+
+func SetNssaiAvailabilityAmfGauge(count int) {
+	if utils.IsBusinessMetricsEnabled() {
+		NssaiAvailabilityAmfGauge.Set(float64(count))
+	}
+}
+
+func SetNssaiAvailabilitySubscriptionGauge(count int) {
+	if utils.IsBusinessMetricsEnabled() {
+		NssaiAvailabilitySubscrGauge.Set(float64(count))
+	}
+}
@@ -0,0 +1,98 @@
+package business
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/util/metrics/utils"
+)
+
+func GetNsselectionMetrics(namespace string) []prometheus.Collector {
+	var metrics []prometheus.Collector
+
+	NsselectionSnssaiCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: SUBSYSTEM_NAME,
+			Name:      NSSELECTION_SNSSAI_COUNTER_NAME,
+			Help:      NSSELECTION_SNSSAI_COUNTER_DESC,
+		},
+		[]string{OUTCOME_LABEL, SNSSAI_LABEL, PLMN_ID_LABEL},
+	)
+
+	metrics = append(metrics, NsselectionSnssaiCounter)
+
+	NsselectionNsiCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: SUBSYSTEM_NAME,
+			Name:      NSSELECTION_NSI_COUNTER_NAME,
+			Help:      NSSELECTION_NSI_COUNTER_DESC,
+		},
+		[]string{NSI_ID_LABEL},
+	)
+
+	metrics = append(metrics, NsselectionNsiCounter)
+
+	NsselectionCandidateAmfHist = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: SUBSYSTEM_NAME,
+			Name:      NSSELECTION_CANDIDATE_AMF_HIST_NAME,
+			Help:      NSSELECTION_CANDIDATE_AMF_HIST_DESC,
+			Buckets:   []float64{0, 1, 2, 4, 8, 16, 32},
+		},
+	)
+
+	metrics = append(metrics, NsselectionCandidateAmfHist)
+
+	return metrics
+}
+
+// Count the S-NSSAI in the PLMN, where nil S-NSSAI or PLMN ID is counted as other
+// The caller should give only configured S-NSSAIs and PLMNs, and nil for the others
+func IncrNsselectionSnssaiCounter(outcome string, snssai *models.Snssai, plmnId *models.PlmnId) {
+	if utils.IsBusinessMetricsEnabled() {
+		NsselectionSnssaiCounter.With(prometheus.Labels{
+			OUTCOME_LABEL: outcome,
+			SNSSAI_LABEL:  formatSnssai(snssai),
+			PLMN_ID_LABEL: formatPlmnId(plmnId),
+		}).Add(1)
+	}
+}
+
+func IncrNsselectionNsiCounter(nsiId string) {
+	if utils.IsBusinessMetricsEnabled() {
+		NsselectionNsiCounter.With(prometheus.Labels{
+			NSI_ID_LABEL: nsiId,
+		}).Add(1)
+	}
+}
+
+func ObserveNsselectionCandidateAmfListSize(size int) {
+	if utils.IsBusinessMetricsEnabled() {
+		NsselectionCandidateAmfHist.Observe(float64(size))
+	}
+}
+
+// Format S-NSSAI as "<SST>" or "<SST>-<SD>", with SD in lower case since it is compared case-insensitively
+func formatSnssai(snssai *models.Snssai) string {
+	if snssai == nil {
+		return OTHER_LABEL_VALUE
+	}
+	if snssai.Sd == "" {
+		return fmt.Sprintf("%d", snssai.Sst)
+	}
+	return fmt.Sprintf("%d-%s", snssai.Sst, strings.ToLower(snssai.Sd))
+}
+
+// Format PLMN ID as "<MCC><MNC>"
+func formatPlmnId(plmnId *models.PlmnId) string {
+	if plmnId == nil {
+		return OTHER_LABEL_VALUE
+	}
+	return plmnId.Mcc + plmnId.Mnc
+}
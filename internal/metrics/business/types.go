@@ -0,0 +1,80 @@
+package business
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/free5gc/util/metrics/utils"
+)
+
+const (
+	SUBSYSTEM_NAME = "nssf"
+)
+
+// NSSelection metrics
+const (
+	NSSELECTION_SNSSAI_COUNTER_NAME = "nsselection_snssai_total"
+	NSSELECTION_SNSSAI_COUNTER_DESC = "Total number of S-NSSAIs allowed or rejected in network slice selection"
+
+	NSSELECTION_NSI_COUNTER_NAME = "nsselection_nsi_total"
+	NSSELECTION_NSI_COUNTER_DESC = "Total number of network slice instances selected"
+
+	NSSELECTION_CANDIDATE_AMF_HIST_NAME = "nsselection_candidate_amf_list_size"
+	NSSELECTION_CANDIDATE_AMF_HIST_DESC = "Histogram of the number of candidate AMFs in network slice selection"
+)
+
+// NSSAI availability metrics
+const (
+	NSSAIAVAILABILITY_AMF_GAUGE_NAME = "nssaiavailability_amf_records"
+	NSSAIAVAILABILITY_AMF_GAUGE_DESC = "Number of AMFs with NSSAI availability information held by NSSF"
+
+	NSSAIAVAILABILITY_SUBSCRIPTION_GAUGE_NAME = "nssaiavailability_subscriptions"
+	NSSAIAVAILABILITY_SUBSCRIPTION_GAUGE_DESC = "Number of NSSAI availability subscriptions"
+)
+
+// NRF metrics
+const (
+	NRF_REGISTRATION_GAUGE_NAME = "nrf_registered"
+	NRF_REGISTRATION_GAUGE_DESC = "Whether NSSF is registered to NRF, 1 if registered and 0 otherwise"
+)
+
+// Label names of the business metrics
+const (
+	OUTCOME_LABEL = "outcome"
+	SNSSAI_LABEL  = "snssai"
+	PLMN_ID_LABEL = "plmn_id"
+	NSI_ID_LABEL  = "nsi_id"
+)
+
+// Label value of S-NSSAIs and PLMNs which are not configured, so that the label values are bounded by
+// configuration rather than by what consumers request
+const OTHER_LABEL_VALUE = "other"
+
+// Outcomes of S-NSSAIs in network slice selection
+const (
+	OutcomeAllowed        = "allowed"
+	OutcomeRejectedInTa   = "rejected_in_ta"
+	OutcomeRejectedInPlmn = "rejected_in_plmn"
+)
+
+var (
+	NsselectionSnssaiCounter     *prometheus.CounterVec
+	NsselectionNsiCounter        *prometheus.CounterVec
+	NsselectionCandidateAmfHist  prometheus.Histogram
+	NssaiAvailabilityAmfGauge    prometheus.Gauge
+	NssaiAvailabilitySubscrGauge prometheus.Gauge
+	NrfRegistrationGauge         prometheus.Gauge
+)
+
+// Key of NSSF business metrics in custom collectors
+const BUSINESS utils.MetricTypeEnabled = "business"
+
+// Get all NSSF business metrics in the namespace
+func GetBusinessMetrics(namespace string) []prometheus.Collector {
+	var metrics []prometheus.Collector
+
+	metrics = append(metrics, GetNsselectionMetrics(namespace)...)
+	metrics = append(metrics, GetNssaiAvailabilityMetrics(namespace)...)
+	metrics = append(metrics, GetNrfMetrics(namespace)...)
+
+	return metrics
+}
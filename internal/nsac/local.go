@@ -0,0 +1,187 @@
+/*
+ * NSSF Network Slice Admission Control
+ *
+ * Local counting of UEs and PDU sessions holding network slices
+ */
+
+package nsac
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+// LocalController counts holders of network slices in the NSSF itself
+// A holder admitted again, e.g. a UE registering again, is not counted twice.
+// NSSF is informed of neither deregistration of UEs nor release of PDU sessions, so holders are only released
+// once the NF service consumer which requested their admissions leaves the network.
+// It is intended for standalone deployments and testing, otherwise quotas should be enforced by the NSACF
+type LocalController struct {
+	mu sync.Mutex
+	// Holders admitted to S-NSSAIs, indexed by S-NSSAI, TA and type of admission
+	// Only admissions subject to quotas are recorded
+	holders map[string]map[Holder]struct{}
+}
+
+var (
+	_ Controller = &LocalController{}
+	_ Releaser   = &LocalController{}
+)
+
+func NewLocalController() *LocalController {
+	return &LocalController{
+		holders: make(map[string]map[Holder]struct{}),
+	}
+}
+
+func snssaiKey(snssai models.Snssai) string {
+	return fmt.Sprintf("%d-%s", snssai.Sst, strings.ToLower(snssai.Sd))
+}
+
+func taKey(snssai models.Snssai, tai models.Tai) string {
+	var plmn string
+	if tai.PlmnId != nil {
+		plmn = tai.PlmnId.Mcc + tai.PlmnId.Mnc
+	}
+	return fmt.Sprintf("%s@%s-%s", snssaiKey(snssai), plmn, tai.Tac)
+}
+
+// Get quota of the S-NSSAI in the PLMN and at the TAI from configuration
+func getQuotaFromConfig(snssai models.Snssai, tai *models.Tai) (*factory.SnssaiQuota, *factory.TaQuota) {
+	factory.NssfConfig.RLock()
+	defer factory.NssfConfig.RUnlock()
+	if factory.NssfConfig.Configuration.Nsac == nil {
+		return nil, nil
+	}
+
+	for i := range factory.NssfConfig.Configuration.Nsac.QuotaList {
+		quota := &factory.NssfConfig.Configuration.Nsac.QuotaList[i]
+		if quota.Snssai.Sst != snssai.Sst || !strings.EqualFold(quota.Snssai.Sd, snssai.Sd) {
+			continue
+		}
+		if tai == nil {
+			return quota, nil
+		}
+		for j := range quota.TaQuotaList {
+			if reflect.DeepEqual(*quota.TaQuotaList[j].Tai, *tai) {
+				return quota, &quota.TaQuotaList[j]
+			}
+		}
+		return quota, nil
+	}
+	return nil, nil
+}
+
+func (l *LocalController) AdmitUe(holder Holder, snssai models.Snssai, tai *models.Tai) (Result, error) {
+	quota, taQuota := getQuotaFromConfig(snssai, tai)
+	var limit, taLimit int
+	if quota != nil {
+		limit = quota.MaxNumOfUes
+	}
+	if taQuota != nil {
+		taLimit = taQuota.MaxNumOfUes
+	}
+
+	result := l.admit("ue", holder, snssai, tai, limit, taLimit)
+	if !result.Admitted {
+		result.Cause = CauseMaxNumOfUesReached
+		logger.NsselLog.Infof("Maximum number of UEs of S-NSSAI %+v reached in %s", snssai, result.Scope)
+	}
+	return result, nil
+}
+
+func (l *LocalController) AdmitPduSession(holder Holder, snssai models.Snssai, tai *models.Tai) (Result, error) {
+	quota, taQuota := getQuotaFromConfig(snssai, tai)
+	var limit, taLimit int
+	if quota != nil {
+		limit = quota.MaxNumOfPduSessions
+	}
+	if taQuota != nil {
+		taLimit = taQuota.MaxNumOfPduSessions
+	}
+
+	result := l.admit("pdu", holder, snssai, tai, limit, taLimit)
+	if !result.Admitted {
+		result.Cause = CauseMaxNumOfPduSessionsReached
+		logger.NsselLog.Infof("Maximum number of PDU sessions of S-NSSAI %+v reached in %s", snssai, result.Scope)
+	}
+	return result, nil
+}
+
+// Admit the holder to the S-NSSAI if holders of the kind are below the limits in the PLMN and the TA,
+// where a limit of zero is unlimited, and record the holder against the limits
+func (l *LocalController) admit(kind string, holder Holder, snssai models.Snssai, tai *models.Tai,
+	limit, taLimit int,
+) Result {
+	if limit == 0 && taLimit == 0 {
+		return Result{Admitted: true}
+	}
+
+	key := kind + "/" + snssaiKey(snssai)
+	var taKeyOfKind string
+	if tai != nil {
+		taKeyOfKind = kind + "/" + taKey(snssai, *tai)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.exceeds(key, holder, limit) {
+		return Result{Scope: ScopePlmn}
+	}
+	if l.exceeds(taKeyOfKind, holder, taLimit) {
+		return Result{Scope: ScopeTa}
+	}
+
+	if limit != 0 {
+		l.hold(key, holder)
+	}
+	if taLimit != 0 {
+		l.hold(taKeyOfKind, holder)
+	}
+	return Result{Admitted: true}
+}
+
+// Whether admitting the holder exceeds the limit of the key, where a holder already admitted is not counted again
+func (l *LocalController) exceeds(key string, holder Holder, limit int) bool {
+	if limit == 0 {
+		return false
+	}
+	holders := l.holders[key]
+	if _, ok := holders[holder]; ok {
+		return false
+	}
+	return len(holders) >= limit
+}
+
+func (l *LocalController) hold(key string, holder Holder) {
+	holders, ok := l.holders[key]
+	if !ok {
+		holders = make(map[Holder]struct{})
+		l.holders[key] = holders
+	}
+	holders[holder] = struct{}{}
+}
+
+// Release the holders admitted on request of the NF service consumer
+func (l *LocalController) ReleaseNf(nfId string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, holders := range l.holders {
+		for holder := range holders {
+			if holder.NfId == nfId {
+				delete(holders, holder)
+			}
+		}
+		if len(holders) == 0 {
+			delete(l.holders, key)
+		}
+	}
+}
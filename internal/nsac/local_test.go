@@ -0,0 +1,145 @@
+package nsac_test
+
+import (
+	"testing"
+
+	"github.com/free5gc/nssf/internal/nsac"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+func TestLocalControllerAdmitUe(t *testing.T) {
+	snssai := models.Snssai{Sst: 1, Sd: "010203"}
+	tai := models.Tai{
+		PlmnId: &models.PlmnId{Mcc: "466", Mnc: "92"},
+		Tac:    "000001",
+	}
+	otherTai := models.Tai{
+		PlmnId: &models.PlmnId{Mcc: "466", Mnc: "92"},
+		Tac:    "000002",
+	}
+	ue1 := nsac.Holder{NfId: "amf1", Id: "imsi-466920000000001"}
+	ue2 := nsac.Holder{NfId: "amf2", Id: "imsi-466920000000002"}
+	ue3 := nsac.Holder{NfId: "amf2", Id: "imsi-466920000000003"}
+	ue4 := nsac.Holder{NfId: "amf2", Id: "imsi-466920000000004"}
+
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			Nsac: &factory.NsacConfig{
+				Enable: true,
+				Mode:   factory.NsacModeLocal,
+				QuotaList: []factory.SnssaiQuota{
+					{
+						Snssai:      &snssai,
+						MaxNumOfUes: 3,
+						TaQuotaList: []factory.TaQuota{
+							{
+								Tai:         &tai,
+								MaxNumOfUes: 1,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	controller := nsac.NewLocalController()
+
+	// Test case 1: Admit a UE within the quota of the TA
+	result, err := controller.AdmitUe(ue1, snssai, &tai)
+	if err != nil || !result.Admitted {
+		t.Fatalf("Expected UE to be admitted, got: %+v, %v", result, err)
+	}
+
+	// Test case 2: Admit the same UE again without counting it twice
+	result, err = controller.AdmitUe(ue1, snssai, &tai)
+	if err != nil || !result.Admitted {
+		t.Fatalf("Expected UE to be admitted again, got: %+v, %v", result, err)
+	}
+
+	// Test case 3: Reject another UE exceeding the quota of the TA
+	result, err = controller.AdmitUe(ue2, snssai, &tai)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Admitted || result.Scope != nsac.ScopeTa || result.Cause != nsac.CauseMaxNumOfUesReached {
+		t.Errorf("Expected UE to be rejected in TA with cause '%s', got: %+v", nsac.CauseMaxNumOfUesReached, result)
+	}
+
+	// Test case 4: Reject a UE exceeding the quota of the PLMN
+	for _, ue := range []nsac.Holder{ue2, ue3} {
+		if result, err = controller.AdmitUe(ue, snssai, &otherTai); err != nil || !result.Admitted {
+			t.Fatalf("Expected UE to be admitted, got: %+v, %v", result, err)
+		}
+	}
+	result, err = controller.AdmitUe(ue4, snssai, &otherTai)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Admitted || result.Scope != nsac.ScopePlmn {
+		t.Errorf("Expected UE to be rejected in PLMN, got: %+v", result)
+	}
+
+	// Test case 5: Admit a UE again once UEs admitted through another AMF are released
+	controller.ReleaseNf("amf2")
+	result, err = controller.AdmitUe(ue4, snssai, &otherTai)
+	if err != nil || !result.Admitted {
+		t.Errorf("Expected UE to be admitted after release, got: %+v, %v", result, err)
+	}
+	result, err = controller.AdmitUe(ue2, snssai, &tai)
+	if err != nil || result.Admitted {
+		t.Errorf("Expected UE to be rejected in TA still held by another AMF, got: %+v, %v", result, err)
+	}
+
+	// Test case 6: S-NSSAI without quota is not limited
+	result, err = controller.AdmitUe(ue1, models.Snssai{Sst: 2}, nil)
+	if err != nil || !result.Admitted {
+		t.Errorf("Expected UE to be admitted, got: %+v, %v", result, err)
+	}
+}
+
+func TestLocalControllerAdmitPduSession(t *testing.T) {
+	snssai := models.Snssai{Sst: 1, Sd: "010203"}
+	tai := models.Tai{
+		PlmnId: &models.PlmnId{Mcc: "466", Mnc: "92"},
+		Tac:    "000001",
+	}
+
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			Nsac: &factory.NsacConfig{
+				Enable: true,
+				Mode:   factory.NsacModeLocal,
+				QuotaList: []factory.SnssaiQuota{
+					{
+						Snssai:              &snssai,
+						MaxNumOfUes:         1,
+						MaxNumOfPduSessions: 1,
+					},
+				},
+			},
+		},
+	}
+	controller := nsac.NewLocalController()
+	ue := nsac.Holder{NfId: "amf1", Id: "imsi-466920000000001"}
+
+	// Test case 1: Admissions of UEs are not counted against the quota of PDU sessions
+	if result, err := controller.AdmitUe(ue, snssai, &tai); err != nil || !result.Admitted {
+		t.Fatalf("Expected UE to be admitted, got: %+v, %v", result, err)
+	}
+	if result, err := controller.AdmitPduSession(nsac.Holder{NfId: "amf1", Id: "request1"}, snssai, &tai); err != nil ||
+		!result.Admitted {
+		t.Fatalf("Expected PDU session to be admitted, got: %+v, %v", result, err)
+	}
+
+	// Test case 2: Reject another PDU session exceeding the quota of the PLMN
+	result, err := controller.AdmitPduSession(nsac.Holder{NfId: "amf1", Id: "request2"}, snssai, &tai)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Admitted || result.Scope != nsac.ScopePlmn || result.Cause != nsac.CauseMaxNumOfPduSessionsReached {
+		t.Errorf("Expected PDU session to be rejected in PLMN with cause '%s', got: %+v",
+			nsac.CauseMaxNumOfPduSessionsReached, result)
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ * NSSF Network Slice Admission Control
+ *
+ * Admission of UEs and PDU sessions to network slices subject to the quotas of the slices
+ */
+
+package nsac
+
+import (
+	"github.com/free5gc/openapi/models"
+)
+
+// Causes of S-NSSAIs rejected by Network Slice Admission Control
+const (
+	CauseMaxNumOfUesReached         = "MAXIMUM_NUMBER_OF_UES_REACHED"
+	CauseMaxNumOfPduSessionsReached = "MAXIMUM_NUMBER_OF_PDU_SESSIONS_REACHED"
+)
+
+// Scope in which the quota of an S-NSSAI is reached
+type Scope int
+
+const (
+	ScopePlmn Scope = iota
+	ScopeTa
+)
+
+func (s Scope) String() string {
+	if s == ScopeTa {
+		return "TA"
+	}
+	return "PLMN"
+}
+
+type Result struct {
+	Admitted bool
+	// Only meaningful if not admitted
+	Scope Scope
+	Cause string
+}
+
+// Holder of a network slice, which is counted once against the quota however many times it is admitted
+// NfId is the NF service consumer requesting the admission, and Id identifies the UE or the PDU session,
+// or the request itself if the consumer does not identify them
+type Holder struct {
+	NfId string
+	Id   string
+}
+
+// Controller decides whether a UE or a PDU session could be admitted to the network slice
+// The TAI may be nil if UE's current TA is unknown, then only quotas of the S-NSSAI in the PLMN are checked
+type Controller interface {
+	AdmitUe(holder Holder, snssai models.Snssai, tai *models.Tai) (Result, error)
+	AdmitPduSession(holder Holder, snssai models.Snssai, tai *models.Tai) (Result, error)
+}
+
+// Releaser is implemented by controllers counting holders in NSSF, whose holders are released
+// once the NF service consumer which requested their admissions leaves the network
+type Releaser interface {
+	ReleaseNf(nfId string)
+}
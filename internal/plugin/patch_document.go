@@ -5,7 +5,497 @@
 package plugin
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
 	"github.com/free5gc/openapi/models"
 )
 
 type PatchDocument []models.PatchItem
+
+// PatchError describes why a patch could not be applied
+// Status is the HTTP status code to be responded:
+//   - 400 Bad Request if the patch document is malformed or does not match the schema
+//   - 409 Conflict if the patch document could not be applied to the current resource
+//   - 422 Unprocessable Entity if the patched resource is semantically invalid
+type PatchError struct {
+	Status int
+	// Index of the failing operation, or -1 if the error is not caused by a single operation
+	OpIndex int
+	// Location of the failing member, e.g. "[1].path" for the path of the second operation
+	Param  string
+	Detail string
+}
+
+func (e *PatchError) Error() string {
+	return e.Detail
+}
+
+func newPatchError(status int, param string, format string, a ...any) *PatchError {
+	return &PatchError{
+		Status:  status,
+		OpIndex: -1,
+		Param:   param,
+		Detail:  fmt.Sprintf(format, a...),
+	}
+}
+
+// Apply the JSON Patch (RFC 6902) to NSSAI availability data
+// Each operation is validated against the schema of SupportedNssaiAvailabilityData, whose document root is the list
+// of SupportedNssaiAvailabilityData. The given data is never modified, and either all or none of the operations apply
+func (p PatchDocument) ApplyToSupportedNssaiAvailabilityData(data []models.SupportedNssaiAvailabilityData) (
+	[]models.SupportedNssaiAvailabilityData, *PatchError,
+) {
+	var doc []models.SupportedNssaiAvailabilityData
+	if err := deepCopy(data, &doc); err != nil {
+		return nil, newPatchError(http.StatusInternalServerError, "", "copy document failed: %+v", err)
+	}
+
+	root := reflect.ValueOf(&doc).Elem()
+	for i, item := range p {
+		if patchErr := applyPatchItem(root, item); patchErr != nil {
+			patchErr.OpIndex = i
+			patchErr.Param = fmt.Sprintf("[%d].%s", i, patchErr.Param)
+			patchErr.Detail = fmt.Sprintf("operation %d (%s): %s", i, item.Op, patchErr.Detail)
+			return nil, patchErr
+		}
+	}
+
+	if patchErr := validateSupportedNssaiAvailabilityData(doc); patchErr != nil {
+		return nil, patchErr
+	}
+	return doc, nil
+}
+
+// Apply the JSON Merge Patch (RFC 7396) to NSSAI availability data
+// Since the document root is a list, a valid merge patch replaces the list as a whole,
+// and the result is validated against the schema of SupportedNssaiAvailabilityData as JSON Patch
+func ApplyMergePatchToSupportedNssaiAvailabilityData(
+	data []models.SupportedNssaiAvailabilityData, mergePatch []byte,
+) ([]models.SupportedNssaiAvailabilityData, *PatchError) {
+	var patch any
+	if err := json.Unmarshal(mergePatch, &patch); err != nil {
+		return nil, newPatchError(http.StatusBadRequest, "", "malformed merge patch document: %+v", err)
+	}
+
+	var target any
+	if err := deepCopy(data, &target); err != nil {
+		return nil, newPatchError(http.StatusInternalServerError, "", "copy document failed: %+v", err)
+	}
+
+	merged, err := json.Marshal(mergePatchValue(target, patch))
+	if err != nil {
+		return nil, newPatchError(http.StatusBadRequest, "", "malformed merge patch document: %+v", err)
+	}
+
+	var doc []models.SupportedNssaiAvailabilityData
+	if err = decodeStrict(merged, &doc); err != nil {
+		return nil, newPatchError(http.StatusBadRequest, "", "merge patch does not match the schema: %+v", err)
+	}
+
+	if patchErr := validateSupportedNssaiAvailabilityData(doc); patchErr != nil {
+		return nil, patchErr
+	}
+	return doc, nil
+}
+
+func mergePatchValue(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = make(map[string]any)
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatchValue(targetObject[key], value)
+		}
+	}
+	return targetObject
+}
+
+func applyPatchItem(root reflect.Value, item models.PatchItem) *PatchError {
+	tokens, err := parsePointer(item.Path)
+	if err != nil {
+		return newPatchError(http.StatusBadRequest, "path", "%+v", err)
+	}
+	targetType, patchErr := schemaType(root.Type(), tokens)
+	if patchErr != nil {
+		patchErr.Param = "path"
+		return patchErr
+	}
+
+	switch item.Op {
+	case models.PatchOperation_ADD, models.PatchOperation_REPLACE, models.PatchOperation_TEST:
+		if item.Value == nil {
+			return newPatchError(http.StatusBadRequest, "value", "value is required")
+		}
+		value, decodeErr := decodeValue(item.Value, targetType)
+		if decodeErr != nil {
+			return newPatchError(http.StatusBadRequest, "value", "value does not match the schema of %s: %+v",
+				item.Path, decodeErr)
+		}
+
+		switch item.Op {
+		case models.PatchOperation_ADD:
+			patchErr = addValue(root, tokens, value)
+		case models.PatchOperation_REPLACE:
+			patchErr = replaceValue(root, tokens, value)
+		default:
+			patchErr = testValue(root, tokens, value)
+		}
+	case models.PatchOperation_REMOVE:
+		patchErr = removeValue(root, tokens)
+	case models.PatchOperation_MOVE, models.PatchOperation_COPY:
+		fromTokens, parseErr := parsePointer(item.From)
+		if parseErr != nil {
+			return newPatchError(http.StatusBadRequest, "from", "%+v", parseErr)
+		}
+		fromType, fromErr := schemaType(root.Type(), fromTokens)
+		if fromErr != nil {
+			fromErr.Param = "from"
+			return fromErr
+		}
+		if fromType != targetType {
+			return newPatchError(http.StatusBadRequest, "from", "type of %s does not match the type of %s",
+				item.From, item.Path)
+		}
+		if item.Op == models.PatchOperation_MOVE && isProperPrefix(fromTokens, tokens) {
+			return newPatchError(http.StatusBadRequest, "from", "%s could not be moved into one of its children",
+				item.From)
+		}
+
+		from, getErr := getValue(root, fromTokens)
+		if getErr != nil {
+			getErr.Param = "from"
+			return getErr
+		}
+		value := reflect.New(fromType).Elem()
+		if err = deepCopy(from.Interface(), value.Addr().Interface()); err != nil {
+			return newPatchError(http.StatusInternalServerError, "from", "copy value failed: %+v", err)
+		}
+
+		if item.Op == models.PatchOperation_MOVE {
+			if patchErr = removeValue(root, fromTokens); patchErr != nil {
+				patchErr.Param = "from"
+				return patchErr
+			}
+		}
+		patchErr = addValue(root, tokens, value)
+	default:
+		return newPatchError(http.StatusBadRequest, "op", "unsupported operation '%s'", item.Op)
+	}
+
+	if patchErr != nil && patchErr.Param == "" {
+		patchErr.Param = "path"
+	}
+	return patchErr
+}
+
+// Parse JSON Pointer (RFC 6901) into reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer '%s'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func isProperPrefix(prefix, tokens []string) bool {
+	if len(prefix) >= len(tokens) {
+		return false
+	}
+	for i := range prefix {
+		if prefix[i] != tokens[i] {
+			return false
+		}
+	}
+	return true
+}
+
+var arrayIndexPattern = regexp.MustCompile(`^(0|[1-9][0-9]*)$`)
+
+// Get the type referenced by the tokens according to the schema
+func schemaType(t reflect.Type, tokens []string) (reflect.Type, *PatchError) {
+	for i, token := range tokens {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Slice:
+			if token != "-" && !arrayIndexPattern.MatchString(token) {
+				return nil, newPatchError(http.StatusBadRequest, "", "'%s' is not an array index", token)
+			}
+			if token == "-" && i != len(tokens)-1 {
+				return nil, newPatchError(http.StatusBadRequest, "", "'-' is only allowed as the last token")
+			}
+			t = t.Elem()
+		case reflect.Struct:
+			field, ok := structFieldByJsonName(t, token)
+			if !ok {
+				return nil, newPatchError(http.StatusBadRequest, "", "'%s' is not a member of %s", token, t.Name())
+			}
+			t = field.Type
+		default:
+			return nil, newPatchError(http.StatusBadRequest, "", "'%s' could not be referenced in %s", token, t.Kind())
+		}
+	}
+	return t, nil
+}
+
+func structFieldByJsonName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == name {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// Get the value referenced by the tokens
+// Members of an object which are absent are regarded as existing with zero value
+func getValue(root reflect.Value, tokens []string) (reflect.Value, *PatchError) {
+	v := root
+	for _, token := range tokens {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, newPatchError(http.StatusConflict, "", "parent of '%s' does not exist", token)
+			}
+			v = v.Elem()
+		}
+
+		switch v.Kind() {
+		case reflect.Slice:
+			if token == "-" {
+				return reflect.Value{}, newPatchError(http.StatusConflict, "", "'-' does not reference an element")
+			}
+			idx, _ := strconv.Atoi(token)
+			if idx >= v.Len() {
+				return reflect.Value{}, newPatchError(http.StatusConflict, "", "array index %d is out of range", idx)
+			}
+			v = v.Index(idx)
+		case reflect.Struct:
+			field, _ := structFieldByJsonName(v.Type(), token)
+			v = v.FieldByIndex(field.Index)
+		}
+	}
+	return v, nil
+}
+
+// Get the parent container of the value referenced by the tokens, allocating the parent object if absent
+func getParent(root reflect.Value, tokens []string) (reflect.Value, *PatchError) {
+	parent, patchErr := getValue(root, tokens[:len(tokens)-1])
+	if patchErr != nil {
+		return reflect.Value{}, patchErr
+	}
+
+	if parent.Kind() == reflect.Ptr {
+		if parent.IsNil() {
+			if parent.Type().Elem().Kind() != reflect.Struct {
+				return reflect.Value{}, newPatchError(http.StatusConflict, "", "parent of '%s' does not exist",
+					tokens[len(tokens)-1])
+			}
+			parent.Set(reflect.New(parent.Type().Elem()))
+		}
+		parent = parent.Elem()
+	}
+	return parent, nil
+}
+
+func addValue(root reflect.Value, tokens []string, value reflect.Value) *PatchError {
+	if len(tokens) == 0 {
+		root.Set(value)
+		return nil
+	}
+
+	parent, patchErr := getParent(root, tokens)
+	if patchErr != nil {
+		return patchErr
+	}
+
+	token := tokens[len(tokens)-1]
+	switch parent.Kind() {
+	case reflect.Slice:
+		if token == "-" {
+			parent.Set(reflect.Append(parent, value))
+			return nil
+		}
+		idx, _ := strconv.Atoi(token)
+		if idx > parent.Len() {
+			return newPatchError(http.StatusConflict, "", "array index %d is out of range", idx)
+		}
+		result := reflect.MakeSlice(parent.Type(), 0, parent.Len()+1)
+		result = reflect.AppendSlice(result, parent.Slice(0, idx))
+		result = reflect.Append(result, value)
+		result = reflect.AppendSlice(result, parent.Slice(idx, parent.Len()))
+		parent.Set(result)
+	case reflect.Struct:
+		field, _ := structFieldByJsonName(parent.Type(), token)
+		parent.FieldByIndex(field.Index).Set(value)
+	}
+	return nil
+}
+
+func removeValue(root reflect.Value, tokens []string) *PatchError {
+	if len(tokens) == 0 {
+		return newPatchError(http.StatusBadRequest, "", "the whole document could not be removed")
+	}
+
+	parent, patchErr := getValue(root, tokens[:len(tokens)-1])
+	if patchErr != nil {
+		return patchErr
+	}
+	if parent.Kind() == reflect.Ptr {
+		if parent.IsNil() {
+			return newPatchError(http.StatusConflict, "", "parent of '%s' does not exist", tokens[len(tokens)-1])
+		}
+		parent = parent.Elem()
+	}
+
+	token := tokens[len(tokens)-1]
+	switch parent.Kind() {
+	case reflect.Slice:
+		if token == "-" {
+			return newPatchError(http.StatusConflict, "", "'-' does not reference an element")
+		}
+		idx, _ := strconv.Atoi(token)
+		if idx >= parent.Len() {
+			return newPatchError(http.StatusConflict, "", "array index %d is out of range", idx)
+		}
+		result := reflect.MakeSlice(parent.Type(), 0, parent.Len()-1)
+		result = reflect.AppendSlice(result, parent.Slice(0, idx))
+		result = reflect.AppendSlice(result, parent.Slice(idx+1, parent.Len()))
+		parent.Set(result)
+	case reflect.Struct:
+		field, _ := structFieldByJsonName(parent.Type(), token)
+		target := parent.FieldByIndex(field.Index)
+		if !memberExists(field, target) {
+			return newPatchError(http.StatusConflict, "", "'%s' does not exist", token)
+		}
+		target.Set(reflect.Zero(target.Type()))
+	}
+	return nil
+}
+
+func replaceValue(root reflect.Value, tokens []string, value reflect.Value) *PatchError {
+	target, patchErr := getValue(root, tokens)
+	if patchErr != nil {
+		return patchErr
+	}
+	// Target location of replace must exist, as for remove
+	if len(tokens) != 0 {
+		parent, _ := getValue(root, tokens[:len(tokens)-1])
+		for parent.Kind() == reflect.Ptr {
+			parent = parent.Elem()
+		}
+		if parent.Kind() == reflect.Struct {
+			field, _ := structFieldByJsonName(parent.Type(), tokens[len(tokens)-1])
+			if !memberExists(field, target) {
+				return newPatchError(http.StatusConflict, "", "'%s' does not exist", tokens[len(tokens)-1])
+			}
+		}
+	}
+	target.Set(value)
+	return nil
+}
+
+// Whether the member of an object is present in its JSON, where optional members with zero value are absent
+func memberExists(field reflect.StructField, v reflect.Value) bool {
+	_, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return !strings.Contains(options, "omitempty") || !v.IsZero()
+}
+
+func testValue(root reflect.Value, tokens []string, value reflect.Value) *PatchError {
+	target, patchErr := getValue(root, tokens)
+	if patchErr != nil {
+		return patchErr
+	}
+
+	current, err := json.Marshal(target.Interface())
+	if err != nil {
+		return newPatchError(http.StatusInternalServerError, "", "marshal value failed: %+v", err)
+	}
+	expected, err := json.Marshal(value.Interface())
+	if err != nil {
+		return newPatchError(http.StatusInternalServerError, "", "marshal value failed: %+v", err)
+	}
+	if !bytes.Equal(current, expected) {
+		return newPatchError(http.StatusConflict, "value", "test failed, current value is %s", current)
+	}
+	return nil
+}
+
+func decodeValue(value any, t reflect.Type) (reflect.Value, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	v := reflect.New(t)
+	if err = decodeStrict(b, v.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return v.Elem(), nil
+}
+
+func decodeStrict(b []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+func deepCopy(src any, dst any) error {
+	b, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, dst)
+}
+
+var sdPattern = regexp.MustCompile(`^[A-Fa-f0-9]{6}$`)
+
+// Check the patched NSSAI availability data against mandatory IEs and formats
+func validateSupportedNssaiAvailabilityData(doc []models.SupportedNssaiAvailabilityData) *PatchError {
+	for i, s := range doc {
+		if s.Tai == nil || s.Tai.PlmnId == nil {
+			return newPatchError(http.StatusUnprocessableEntity, fmt.Sprintf("/%d/tai", i),
+				"tai or tai.plmnId is missing in supportedNssaiAvailabilityData[%d]", i)
+		}
+		if s.Tai.Tac == "" {
+			return newPatchError(http.StatusUnprocessableEntity, fmt.Sprintf("/%d/tai/tac", i),
+				"tai.tac is missing in supportedNssaiAvailabilityData[%d]", i)
+		}
+		for j, snssai := range s.SupportedSnssaiList {
+			if snssai.Sst < 0 || snssai.Sst > 255 {
+				return newPatchError(http.StatusUnprocessableEntity, fmt.Sprintf("/%d/supportedSnssaiList/%d/sst", i, j),
+					"invalid sst %d in supportedNssaiAvailabilityData[%d]", snssai.Sst, i)
+			}
+			if snssai.Sd != "" && !sdPattern.MatchString(snssai.Sd) {
+				return newPatchError(http.StatusUnprocessableEntity, fmt.Sprintf("/%d/supportedSnssaiList/%d/sd", i, j),
+					"invalid sd '%s' in supportedNssaiAvailabilityData[%d]", snssai.Sd, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package plugin_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/free5gc/nssf/internal/plugin"
+	"github.com/free5gc/openapi/models"
+)
+
+func TestApplyToSupportedNssaiAvailabilityData(t *testing.T) {
+	const current = `[
+		{"tai": {"plmnId": {"mcc": "466", "mnc": "92"}, "tac": "33456"},
+		 "supportedSnssaiList": [{"sst": 1}, {"sst": 1, "sd": "000001"}]},
+		{"tai": {"plmnId": {"mcc": "466", "mnc": "92"}, "tac": "33457"},
+		 "supportedSnssaiList": [{"sst": 2}]}
+	]`
+
+	testCases := []struct {
+		name  string
+		patch string
+		// Status of the patch error, or zero if the patch is applied
+		expectStatus int
+		expectParam  string
+		// Patched data if the patch is applied
+		expect string
+	}{
+		{
+			name:  "Add S-NSSAI to the end of list",
+			patch: `[{"op": "add", "path": "/1/supportedSnssaiList/-", "value": {"sst": 3}}]`,
+			expect: `[
+				{"tai": {"plmnId": {"mcc": "466", "mnc": "92"}, "tac": "33456"},
+				 "supportedSnssaiList": [{"sst": 1}, {"sst": 1, "sd": "000001"}]},
+				{"tai": {"plmnId": {"mcc": "466", "mnc": "92"}, "tac": "33457"},
+				 "supportedSnssaiList": [{"sst": 2}, {"sst": 3}]}
+			]`,
+		},
+		{
+			name:  "Add S-NSSAI at index",
+			patch: `[{"op": "add", "path": "/1/supportedSnssaiList/0", "value": {"sst": 3}}]`,
+			expect: `[
+				{"tai": {"plmnId": {"mcc": "466", "mnc": "92"}, "tac": "33456"},
+				 "supportedSnssaiList": [{"sst": 1}, {"sst": 1, "sd": "000001"}]},
+				{"tai": {"plmnId": {"mcc": "466", "mnc": "92"}, "tac": "33457"},
+				 "supportedSnssaiList": [{"sst": 3}, {"sst": 2}]}
+			]`,
+		},
+		{
+			name: "Remove, replace and test in order",
+			patch: `[
+				{"op": "remove", "path": "/0/supportedSnssaiList/1/sd"},
+				{"op": "test", "path": "/0/supportedSnssaiList/1", "value": {"sst": 1}},
+				{"op": "replace", "path": "/1/tai/tac", "value": "33458"},
+				{"op": "remove", "path": "/0"}
+			]`,
+			expect: `[
+				{"tai": {"plmnId": {"mcc": "466", "mnc": "92"}, "tac": "33458"},
+				 "supportedSnssaiList": [{"sst": 2}]}
+			]`,
+		},
+		{
+			name: "Move and copy",
+			patch: `[
+				{"op": "copy", "from": "/0/supportedSnssaiList/1", "path": "/1/supportedSnssaiList/-"},
+				{"op": "move", "from": "/0/supportedSnssaiList/0", "path": "/1/supportedSnssaiList/0"}
+			]`,
+			expect: `[
+				{"tai": {"plmnId": {"mcc": "466", "mnc": "92"}, "tac": "33456"},
+				 "supportedSnssaiList": [{"sst": 1, "sd": "000001"}]},
+				{"tai": {"plmnId": {"mcc": "466", "mnc": "92"}, "tac": "33457"},
+				 "supportedSnssaiList": [{"sst": 1}, {"sst": 2}, {"sst": 1, "sd": "000001"}]}
+			]`,
+		},
+		{
+			name:         "Unsupported operation",
+			patch:        `[{"op": "merge", "path": "/0"}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].op",
+		},
+		{
+			name:         "Path is not a JSON pointer",
+			patch:        `[{"op": "remove", "path": "0/tai"}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Path is not a member of the schema",
+			patch:        `[{"op": "add", "path": "/0/unknown", "value": 1}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Path is not an array index",
+			patch:        `[{"op": "remove", "path": "/first"}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Negative array index",
+			patch:        `[{"op": "remove", "path": "/-1"}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Value is missing",
+			patch:        `[{"op": "add", "path": "/0/supportedSnssaiList/-"}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].value",
+		},
+		{
+			name:         "Value does not match the schema",
+			patch:        `[{"op": "add", "path": "/0/supportedSnssaiList/-", "value": {"sst": "one"}}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].value",
+		},
+		{
+			name:         "Value with unknown member",
+			patch:        `[{"op": "add", "path": "/0/supportedSnssaiList/-", "value": {"sst": 1, "unknown": 1}}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].value",
+		},
+		{
+			name:         "Remove the whole document",
+			patch:        `[{"op": "remove", "path": ""}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Move into its own child",
+			patch:        `[{"op": "move", "from": "/0/supportedSnssaiList", "path": "/0/supportedSnssaiList/0"}]`,
+			expectStatus: http.StatusBadRequest,
+			expectParam:  "[0].from",
+		},
+		{
+			name:         "Remove absent member",
+			patch:        `[{"op": "remove", "path": "/0/supportedSnssaiList/0/sd"}]`,
+			expectStatus: http.StatusConflict,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Replace absent member",
+			patch:        `[{"op": "replace", "path": "/1/taiList", "value": []}]`,
+			expectStatus: http.StatusConflict,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Remove out of range",
+			patch:        `[{"op": "remove", "path": "/2"}]`,
+			expectStatus: http.StatusConflict,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Replace out of range",
+			patch:        `[{"op": "replace", "path": "/0/supportedSnssaiList/2", "value": {"sst": 3}}]`,
+			expectStatus: http.StatusConflict,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Add out of range",
+			patch:        `[{"op": "add", "path": "/0/supportedSnssaiList/3", "value": {"sst": 3}}]`,
+			expectStatus: http.StatusConflict,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Remove the end of list",
+			patch:        `[{"op": "remove", "path": "/0/supportedSnssaiList/-"}]`,
+			expectStatus: http.StatusConflict,
+			expectParam:  "[0].path",
+		},
+		{
+			name:         "Copy from out of range",
+			patch:        `[{"op": "copy", "from": "/5", "path": "/-"}]`,
+			expectStatus: http.StatusConflict,
+			expectParam:  "[0].from",
+		},
+		{
+			name: "Failed test discards previous operations",
+			patch: `[
+				{"op": "remove", "path": "/1"},
+				{"op": "test", "path": "/0/tai/tac", "value": "33457"}
+			]`,
+			expectStatus: http.StatusConflict,
+			expectParam:  "[1].value",
+		},
+		{
+			name:         "Patched TAI without PLMN ID",
+			patch:        `[{"op": "replace", "path": "/0/tai", "value": {"tac": "33456"}}]`,
+			expectStatus: http.StatusUnprocessableEntity,
+			expectParam:  "/0/tai",
+		},
+		{
+			name:         "Patched TAI without TAC",
+			patch:        `[{"op": "replace", "path": "/1/tai/tac", "value": ""}]`,
+			expectStatus: http.StatusUnprocessableEntity,
+			expectParam:  "/1/tai/tac",
+		},
+		{
+			name:         "Patched SST out of range",
+			patch:        `[{"op": "replace", "path": "/1/supportedSnssaiList/0/sst", "value": 256}]`,
+			expectStatus: http.StatusUnprocessableEntity,
+			expectParam:  "/1/supportedSnssaiList/0/sst",
+		},
+		{
+			name:         "Patched SD of invalid format",
+			patch:        `[{"op": "add", "path": "/1/supportedSnssaiList/0/sd", "value": "xyz"}]`,
+			expectStatus: http.StatusUnprocessableEntity,
+			expectParam:  "/1/supportedSnssaiList/0/sd",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var data []models.SupportedNssaiAvailabilityData
+			if err := json.Unmarshal([]byte(current), &data); err != nil {
+				t.Fatalf("Unmarshal current data failed: %+v", err)
+			}
+			var original []models.SupportedNssaiAvailabilityData
+			if err := json.Unmarshal([]byte(current), &original); err != nil {
+				t.Fatalf("Unmarshal current data failed: %+v", err)
+			}
+			var patchDocument plugin.PatchDocument
+			if err := json.Unmarshal([]byte(tc.patch), &patchDocument); err != nil {
+				t.Fatalf("Unmarshal patch document failed: %+v", err)
+			}
+
+			patched, patchErr := patchDocument.ApplyToSupportedNssaiAvailabilityData(data)
+			if !reflect.DeepEqual(data, original) {
+				t.Errorf("Expected current data not to be modified, got: %+v", data)
+			}
+
+			if tc.expectStatus != 0 {
+				if patchErr == nil {
+					t.Fatalf("Expected patch error with status %d, got patched data: %+v", tc.expectStatus, patched)
+				}
+				if patchErr.Status != tc.expectStatus {
+					t.Errorf("Expected status %d, got: %d (%s)", tc.expectStatus, patchErr.Status, patchErr.Detail)
+				}
+				if patchErr.Param != tc.expectParam {
+					t.Errorf("Expected param %q, got: %q", tc.expectParam, patchErr.Param)
+				}
+				return
+			}
+
+			if patchErr != nil {
+				t.Fatalf("Expected patch to be applied, got: %+v", patchErr)
+			}
+			var expect []models.SupportedNssaiAvailabilityData
+			if err := json.Unmarshal([]byte(tc.expect), &expect); err != nil {
+				t.Fatalf("Unmarshal expected data failed: %+v", err)
+			}
+			if !reflect.DeepEqual(patched, expect) {
+				t.Errorf("Expected patched data %+v, got: %+v", expect, patched)
+			}
+		})
+	}
+}
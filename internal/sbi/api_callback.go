@@ -0,0 +1,59 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/util/metrics/sbi"
+)
+
+func (s *Server) getCallbackRoutes() []Route {
+	return []Route{
+		{
+			"NfStatusNotify",
+			http.MethodPost,
+			"/nf-status-notify/:notifyToken",
+			s.HTTPNfStatusNotify,
+		},
+	}
+}
+
+// HTTPNfStatusNotify - Handles NF status notification of AMFs from NRF
+func (s *Server) HTTPNfStatusNotify(c *gin.Context) {
+	var notification models.NrfNfManagementNotificationData
+
+	requestBody, err := c.GetRawData()
+	if err != nil {
+		problemDetail := &models.ProblemDetails{
+			Title:  "System failure",
+			Status: http.StatusInternalServerError,
+			Detail: err.Error(),
+			Cause:  "SYSTEM_FAILURE",
+		}
+		logger.CallbackLog.Errorf("Get Request Body error: %+v", err)
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetail.Cause)
+		util.GinProblemJson(c, problemDetail)
+		return
+	}
+
+	err = openapi.Deserialize(&notification, requestBody, "application/json")
+	if err != nil {
+		problemDetail := "[Request Body] " + err.Error()
+		rsp := &models.ProblemDetails{
+			Title:  "Malformed request syntax",
+			Status: http.StatusBadRequest,
+			Detail: problemDetail,
+		}
+		logger.CallbackLog.Errorln(problemDetail)
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, rsp.Title)
+		util.GinProblemJson(c, rsp)
+		return
+	}
+
+	s.Processor().NfStatusNotify(c, c.Params.ByName("notifyToken"), notification)
+}
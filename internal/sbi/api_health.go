@@ -0,0 +1,57 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/free5gc/nssf/internal/health"
+)
+
+func (s *Server) getHealthRoutes() []Route {
+	return []Route{
+		{
+			"Liveness",
+			http.MethodGet,
+			"/livez",
+			s.HTTPLiveness,
+		},
+
+		{
+			"Readiness",
+			http.MethodGet,
+			"/readyz",
+			s.HTTPReadiness,
+		},
+	}
+}
+
+// HTTPLiveness - Check whether NSSF is alive, which fails if any request handling goroutine is wedged
+// Locks are checked by readiness only, so that NSSF is not restarted while a lock is held by a long update
+func (s *Server) HTTPLiveness(c *gin.Context) {
+	report := health.Run(c.Request.Context(), []health.Check{
+		health.RequestsCheck(s.inflight),
+	})
+	healthJson(c, report)
+}
+
+// HTTPReadiness - Check whether NSSF is ready to serve NF service consumers
+func (s *Server) HTTPReadiness(c *gin.Context) {
+	report := health.Run(c.Request.Context(), []health.Check{
+		health.ShutdownCheck(s.Context()),
+		health.ConfigCheck(s.Config()),
+		health.NrfRegistrationCheck(s.Context()),
+		health.StoreCheck(s.Config(), s.Context()),
+		health.TlsCheck(s.Config()),
+	})
+	healthJson(c, report)
+}
+
+// Respond with the report, with 503 status if any check fails so that the probe fails
+func healthJson(c *gin.Context, report health.Report) {
+	status := http.StatusOK
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
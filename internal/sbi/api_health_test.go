@@ -0,0 +1,60 @@
+package sbi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/free5gc/nssf/internal/health"
+)
+
+func TestHealthJson(t *testing.T) {
+	testCases := []struct {
+		name   string
+		report health.Report
+		status int
+	}{
+		{
+			name: "Up",
+			report: health.Report{
+				Status: health.StatusUp,
+				Checks: []health.CheckResult{{Name: "config", Status: health.StatusUp}},
+			},
+			status: http.StatusOK,
+		},
+		{
+			name: "Down",
+			report: health.Report{
+				Status: health.StatusDown,
+				Checks: []health.CheckResult{
+					{Name: "config", Status: health.StatusUp},
+					{Name: "nrfRegistration", Status: health.StatusDown, Detail: "not registered to NRF"},
+				},
+			},
+			status: http.StatusServiceUnavailable,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			healthJson(c, tc.report)
+
+			if httpRecorder.Code != tc.status {
+				t.Errorf("Expected status %d, got: %d", tc.status, httpRecorder.Code)
+			}
+			var report health.Report
+			if err := json.Unmarshal(httpRecorder.Body.Bytes(), &report); err != nil {
+				t.Fatalf("Unmarshal report failed: %+v", err)
+			}
+			if report.Status != tc.report.Status || len(report.Checks) != len(tc.report.Checks) {
+				t.Errorf("Expected report %+v, got: %+v", tc.report, report)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package sbi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/util/metrics/sbi"
+)
+
+func (s *Server) getManagementRoutes() []Route {
+	return []Route{
+		{
+			"ExplainModeGet",
+			http.MethodGet,
+			"/explain",
+			s.HTTPExplainModeGet,
+		},
+
+		{
+			"ExplainModeUpdate",
+			http.MethodPut,
+			"/explain",
+			s.HTTPExplainModeUpdate,
+		},
+
+		{
+			"LoggingGet",
+			http.MethodGet,
+			"/logging",
+			s.HTTPLoggingGet,
+		},
+
+		{
+			"LoggingUpdate",
+			http.MethodPut,
+			"/logging",
+			s.HTTPLoggingUpdate,
+		},
+
+		{
+			"LogLevelUpdate",
+			http.MethodPut,
+			"/logging/level",
+			s.HTTPLogLevelUpdate,
+		},
+
+		{
+			"CategoryLogLevelUpdate",
+			http.MethodPut,
+			"/logging/categories/:category",
+			s.HTTPLogLevelUpdate,
+		},
+
+		{
+			"CategoryLogLevelReset",
+			http.MethodDelete,
+			"/logging/categories/:category",
+			s.HTTPCategoryLogLevelReset,
+		},
+	}
+}
+
+// HTTPExplainModeGet - Get explain mode of network slice selection
+func (s *Server) HTTPExplainModeGet(c *gin.Context) {
+	s.Processor().ExplainModeGet(c)
+}
+
+// HTTPExplainModeUpdate - Enable or disable explain mode of network slice selection
+func (s *Server) HTTPExplainModeUpdate(c *gin.Context) {
+	var explainMode processor.ExplainMode
+	if !deserializeManagementRequest(c, &explainMode) {
+		return
+	}
+
+	s.Processor().ExplainModeUpdate(c, explainMode)
+}
+
+// HTTPLoggingGet - Get logging control, with log levels in effect globally and of every category
+func (s *Server) HTTPLoggingGet(c *gin.Context) {
+	s.Processor().LoggingGet(c)
+}
+
+// HTTPLoggingUpdate - Enable or disable logging, and reporting of callers
+func (s *Server) HTTPLoggingUpdate(c *gin.Context) {
+	var output processor.LoggingOutput
+	if !deserializeManagementRequest(c, &output) {
+		return
+	}
+
+	s.Processor().LoggingUpdate(c, output)
+}
+
+// HTTPLogLevelUpdate - Set log level globally or of a category, until changed or for a duration
+func (s *Server) HTTPLogLevelUpdate(c *gin.Context) {
+	var update processor.LogLevelUpdate
+	if !deserializeManagementRequest(c, &update) {
+		return
+	}
+
+	s.Processor().LogLevelUpdate(c, c.Params.ByName("category"), update)
+}
+
+// HTTPCategoryLogLevelReset - Reset log level of a category to the global level
+func (s *Server) HTTPCategoryLogLevelReset(c *gin.Context) {
+	s.Processor().LogLevelReset(c, c.Params.ByName("category"))
+}
+
+// Deserialize the JSON body of request into v, or respond with Problem Details on failure
+func deserializeManagementRequest(c *gin.Context, v any) bool {
+	requestBody, err := c.GetRawData()
+	if err != nil {
+		problemDetails := &models.ProblemDetails{
+			Status: http.StatusInternalServerError,
+			Cause:  "SYSTEM_FAILURE",
+		}
+		logger.SBILog.Errorf("Get Request Body error: %+v", err)
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Cause)
+		util.GinProblemJson(c, problemDetails)
+		return false
+	}
+
+	if err = openapi.Deserialize(v, requestBody, "application/json"); err != nil {
+		problemDetails := &models.ProblemDetails{
+			Title:  util.INVALID_REQUEST,
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+		}
+		logger.SBILog.Errorf("Deserialize management request error: %+v", err)
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,182 @@
+package sbi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+
+	"github.com/free5gc/nssf/internal/health"
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+const testManagementToken = "0123456789abcdef"
+
+type testNssfApp struct {
+	*app.MockNssfApp
+
+	processor *processor.Processor
+}
+
+func (a *testNssfApp) Processor() *processor.Processor {
+	return a.processor
+}
+
+func newTestServer(t *testing.T) *Server {
+	mockNssfApp := app.NewMockNssfApp(gomock.NewController(t))
+	cfg := &factory.Config{
+		Configuration: &factory.Configuration{
+			ServiceNameList: []models.ServiceName{models.ServiceName_NNSSF_NSSELECTION},
+			Management:      &factory.Management{Enable: true},
+		},
+	}
+	mockNssfApp.EXPECT().Config().Return(cfg).AnyTimes()
+	nssf := &testNssfApp{
+		MockNssfApp: mockNssfApp,
+		processor:   processor.NewProcessor(mockNssfApp),
+	}
+	return &Server{
+		nssfApp:   nssf,
+		processor: nssf.processor,
+		inflight:  health.NewInflightTracker(),
+	}
+}
+
+func TestManagementAuthorization(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := newTestServer(t)
+	router := newManagementRouter(s, testManagementToken)
+
+	testCases := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{
+			name:   "Without token",
+			status: http.StatusUnauthorized,
+		},
+		{
+			name:          "Wrong token",
+			authorization: "Bearer fedcba9876543210",
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:          "Token without bearer scheme",
+			authorization: testManagementToken,
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:          "Prefix of token",
+			authorization: "Bearer " + testManagementToken[:8],
+			status:        http.StatusUnauthorized,
+		},
+		{
+			name:          "Valid token",
+			authorization: "Bearer " + testManagementToken,
+			status:        http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, req := range []*http.Request{
+				httptest.NewRequest(http.MethodGet, factory.NssfManagementResUriPrefix+"/explain", nil),
+				httptest.NewRequest(http.MethodPut, factory.NssfManagementResUriPrefix+"/explain",
+					strings.NewReader(`{"enable":false}`)),
+			} {
+				req.Header.Set("Content-Type", "application/json")
+				if tc.authorization != "" {
+					req.Header.Set("Authorization", tc.authorization)
+				}
+				httpRecorder := httptest.NewRecorder()
+				router.ServeHTTP(httpRecorder, req)
+				if httpRecorder.Code != tc.status {
+					t.Errorf("Expected status %d of %s %s, got: %d", tc.status, req.Method, req.URL.Path,
+						httpRecorder.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestManagementNotServedOnSbi(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := newTestServer(t)
+	router := newRouter(s)
+
+	req := httptest.NewRequest(http.MethodPut, factory.NssfManagementResUriPrefix+"/explain",
+		strings.NewReader(`{"enable":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testManagementToken)
+	httpRecorder := httptest.NewRecorder()
+	router.ServeHTTP(httpRecorder, req)
+	if httpRecorder.Code != http.StatusNotFound {
+		t.Errorf("Expected management API not to be served on SBI, got status: %d", httpRecorder.Code)
+	}
+	if s.Processor().ExplainMode() {
+		t.Errorf("Expected explain mode not to be changed through SBI")
+	}
+}
+
+func TestLoadManagementToken(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(tokenFile, []byte(testManagementToken+"\n"), 0o600); err != nil {
+		t.Fatalf("Write token failed: %+v", err)
+	}
+	token, err := util.LoadManagementToken(tokenFile)
+	if err != nil || token != testManagementToken {
+		t.Errorf("Expected token %q, got: %q, %+v", testManagementToken, token, err)
+	}
+
+	emptyFile := filepath.Join(dir, "empty")
+	if err = os.WriteFile(emptyFile, []byte("\n"), 0o600); err != nil {
+		t.Fatalf("Write token failed: %+v", err)
+	}
+	if _, err = util.LoadManagementToken(emptyFile); err == nil {
+		t.Errorf("Expected empty token to be rejected")
+	}
+	if _, err = util.LoadManagementToken(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("Expected missing token file to be rejected")
+	}
+}
+
+func TestManagementRoutesRequireToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := newTestServer(t)
+	router := newManagementRouter(s, testManagementToken)
+	level := logger.GetLevel("")
+
+	for _, route := range s.getManagementRoutes() {
+		t.Run(route.Name, func(t *testing.T) {
+			req := httptest.NewRequest(route.Method,
+				factory.NssfManagementResUriPrefix+strings.ReplaceAll(route.Pattern, ":category", "NsSel"),
+				strings.NewReader(`{"enable":false,"reportCaller":true,"level":"trace"}`))
+			req.Header.Set("Content-Type", "application/json")
+			httpRecorder := httptest.NewRecorder()
+			router.ServeHTTP(httpRecorder, req)
+			if httpRecorder.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status %d of %s %s without token, got: %d", http.StatusUnauthorized,
+					route.Method, route.Pattern, httpRecorder.Code)
+			}
+		})
+	}
+
+	if after := logger.GetLevel(""); after.Level != level.Level {
+		t.Errorf("Expected log level not to be changed without token, got: %s", after.Level)
+	}
+	if info := logger.GetLevel("NsSel"); info.Overridden {
+		t.Errorf("Expected log level of category NsSel not to be changed without token")
+	}
+}
@@ -1,12 +1,15 @@
 package sbi
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	nssf_context "github.com/free5gc/nssf/internal/context"
 	"github.com/free5gc/nssf/internal/logger"
 	"github.com/free5gc/nssf/internal/plugin"
+	"github.com/free5gc/nssf/internal/sbi/processor"
 	"github.com/free5gc/nssf/internal/util"
 	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
@@ -24,6 +27,20 @@ func (s *Server) getNssaiAvailabilityRoutes() []Route {
 			},
 		},
 
+		{
+			"NSSAIAvailabilityList",
+			http.MethodGet,
+			"/nssai-availability",
+			s.NSSAIAvailabilityList,
+		},
+
+		{
+			"NSSAIAvailabilityGet",
+			http.MethodGet,
+			"/nssai-availability/:nfId",
+			s.NSSAIAvailabilityGet,
+		},
+
 		{
 			"NSSAIAvailabilityDelete",
 			http.MethodDelete,
@@ -52,6 +69,13 @@ func (s *Server) getNssaiAvailabilityRoutes() []Route {
 			s.NSSAIAvailabilityUnsubscribeDelete,
 		},
 
+		{
+			"NSSAIAvailabilitySubscriptionGet",
+			http.MethodGet,
+			"/nssai-availability/subscriptions/:subscriptionId",
+			s.NSSAIAvailabilitySubscriptionGet,
+		},
+
 		{
 			"NSSAIAvailabilityPost",
 			http.MethodPost,
@@ -75,6 +99,95 @@ func (s *Server) getNssaiAvailabilityRoutes() []Route {
 	}
 }
 
+// Segment of the subscriptions collection, which matches :nfId of NSSAI availability information
+// for methods other than POST, but is never an NF instance ID
+const subscriptionsSegment = "subscriptions"
+
+// Media types of the patch documents supported by PATCH of NSSAI availability information
+const (
+	jsonPatchMediaType  = "application/json-patch+json"
+	mergePatchMediaType = "application/merge-patch+json"
+)
+
+// Header identifying the NF instances of the request defined in TS 29.500
+const peerInfoHeader = "3gpp-Sbi-NF-Peer-Info"
+
+// Reject methods not supported by the subscriptions collection, and return whether the request is rejected
+func rejectSubscriptionsCollection(c *gin.Context, nfId string) bool {
+	if nfId != subscriptionsSegment {
+		return false
+	}
+	problemDetails := &models.ProblemDetails{
+		Title:  util.UNSUPPORTED_RESOURCE,
+		Status: http.StatusMethodNotAllowed,
+		Detail: fmt.Sprintf("Method %s is not allowed on NSSAI availability subscriptions", c.Request.Method),
+	}
+	c.Header("Allow", http.MethodPost)
+	c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+	util.GinProblemJson(c, problemDetails)
+	return true
+}
+
+// NSSAIAvailabilityList - Retrieves the NSSAI availability information held by NSSF indexed by NF instance ID,
+// filtered by TAI, S-NSSAI and AMF set
+func (s *Server) NSSAIAvailabilityList(c *gin.Context) {
+	logger.NssaiavailLog.Infof("Handle NSSAIAvailabilityList")
+
+	var query processor.NssaiAvailabilityListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		logger.NssaiavailLog.Errorf("BindQuery failed: %+v", err)
+		problemDetail := &models.ProblemDetails{
+			Title:         "Malformed Request",
+			Status:        http.StatusBadRequest,
+			Detail:        err.Error(),
+			InvalidParams: util.BindErrorInvalidParamsMessages(err),
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetail.Title)
+		util.GinProblemJson(c, problemDetail)
+		return
+	}
+
+	s.Processor().NssaiAvailabilityList(c, query)
+}
+
+// NSSAIAvailabilityGet - Retrieves the S-NSSAIs per TA provided by the NF service consumer (e.g AMF),
+// or the authorized NSSAI availability information with view=authorized
+func (s *Server) NSSAIAvailabilityGet(c *gin.Context) {
+	logger.NssaiavailLog.Infof("Handle NSSAIAvailabilityGet")
+
+	nfId := c.Params.ByName("nfId")
+
+	if nfId == "" {
+		problemDetails := &models.ProblemDetails{
+			Status: http.StatusBadRequest,
+			Cause:  "UNSPECIFIED",
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Cause)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+
+	if rejectSubscriptionsCollection(c, nfId) {
+		return
+	}
+
+	var query processor.NssaiAvailabilityGetQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		logger.NssaiavailLog.Errorf("BindQuery failed: %+v", err)
+		problemDetail := &models.ProblemDetails{
+			Title:         "Malformed Request",
+			Status:        http.StatusBadRequest,
+			Detail:        err.Error(),
+			InvalidParams: util.BindErrorInvalidParamsMessages(err),
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetail.Title)
+		util.GinProblemJson(c, problemDetail)
+		return
+	}
+
+	s.Processor().NssaiAvailabilityNfInstanceGet(c, nfId, query)
+}
+
 // NSSAIAvailabilityDelete - Deletes an already existing S-NSSAIs per TA
 // provided by the NF service consumer (e.g AMF)
 func (s *Server) NSSAIAvailabilityDelete(c *gin.Context) {
@@ -92,6 +205,10 @@ func (s *Server) NSSAIAvailabilityDelete(c *gin.Context) {
 		return
 	}
 
+	if rejectSubscriptionsCollection(c, nfId) {
+		return
+	}
+
 	s.Processor().NssaiAvailabilityNfInstanceDelete(c, nfId)
 }
 
@@ -112,6 +229,10 @@ func (s *Server) NSSAIAvailabilityPatch(c *gin.Context) {
 		return
 	}
 
+	if rejectSubscriptionsCollection(c, nfId) {
+		return
+	}
+
 	var patchDocument plugin.PatchDocument
 
 	requestBody, err := c.GetRawData()
@@ -125,6 +246,25 @@ func (s *Server) NSSAIAvailabilityPatch(c *gin.Context) {
 		return
 	}
 
+	// The patch is applied according to its media type, and JSON Patch is also accepted as plain JSON
+	switch c.ContentType() {
+	case mergePatchMediaType:
+		s.Processor().NssaiAvailabilityNfInstanceMergePatch(c, requestBody, nfId, c.GetHeader("If-Match"))
+		return
+	case jsonPatchMediaType, "application/json":
+	default:
+		problemDetails := &models.ProblemDetails{
+			Title:  util.UNSUPPORTED_MEDIA,
+			Status: http.StatusUnsupportedMediaType,
+			Detail: fmt.Sprintf("Patch of media type '%s' is not supported, use '%s' or '%s'",
+				c.ContentType(), jsonPatchMediaType, mergePatchMediaType),
+		}
+		c.Header("Accept-Patch", jsonPatchMediaType+", "+mergePatchMediaType)
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+
 	if err = openapi.Deserialize(&patchDocument, requestBody, "application/json"); err != nil {
 		problemDetails := &models.ProblemDetails{
 			Status: http.StatusBadRequest,
@@ -142,7 +282,7 @@ func (s *Server) NSSAIAvailabilityPatch(c *gin.Context) {
 	//       If NfId is invalid, return ProblemDetails with code 404 Not Found
 	//       If NF consumer is not authorized to update NSSAI availability, return ProblemDetails with code 403 Forbidden
 
-	s.Processor().NssaiAvailabilityNfInstancePatch(c, patchDocument, nfId)
+	s.Processor().NssaiAvailabilityNfInstancePatch(c, patchDocument, nfId, c.GetHeader("If-Match"))
 }
 
 type NssaiAvailabilityPutParams struct {
@@ -154,6 +294,10 @@ type NssaiAvailabilityPutParams struct {
 func (s *Server) NSSAIAvailabilityPut(c *gin.Context) {
 	logger.NssaiavailLog.Infof("Handle NSSAIAvailabilityPut")
 
+	if rejectSubscriptionsCollection(c, c.Params.ByName("nfId")) {
+		return
+	}
+
 	var params NssaiAvailabilityPutParams
 	if err := c.ShouldBindUri(&params); err != nil {
 		problemDetails := &models.ProblemDetails{
@@ -191,7 +335,24 @@ func (s *Server) NSSAIAvailabilityPut(c *gin.Context) {
 		return
 	}
 
-	s.Processor().NssaiAvailabilityNfInstanceUpdate(c, nssaiAvailabilityInfo, params.NfId)
+	s.Processor().NssaiAvailabilityNfInstanceUpdate(c, nssaiAvailabilityInfo, params.NfId, c.GetHeader("If-Match"))
+}
+
+func (s *Server) NSSAIAvailabilitySubscriptionGet(c *gin.Context) {
+	logger.NssaiavailLog.Infof("Handle NSSAIAvailabilitySubscriptionGet")
+
+	subscriptionId := c.Params.ByName("subscriptionId")
+	if subscriptionId == "" {
+		problemDetails := &models.ProblemDetails{
+			Status: http.StatusBadRequest,
+			Cause:  "UNSPECIFIED",
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Cause)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+
+	s.Processor().NssaiAvailabilitySubscriptionGet(c, subscriptionId)
 }
 
 func (s *Server) NSSAIAvailabilitySubscriptionPatch(c *gin.Context) {
@@ -229,7 +390,13 @@ func (s *Server) NSSAIAvailabilityPost(c *gin.Context) {
 		return
 	}
 
-	s.Processor().NssaiAvailabilitySubscriptionCreate(c, createData)
+	// The subscription is removed along with the AMF when the AMF is deregistered from NRF
+	// The AMF is identified by its access token if authenticated, otherwise by the headers of the request
+	consumerNfId := s.Context().ConsumerNfId(c.GetHeader("Authorization"))
+	if consumerNfId == "" {
+		consumerNfId = nssf_context.PeerNfId(c.GetHeader(peerInfoHeader), c.GetHeader("User-Agent"))
+	}
+	s.Processor().NssaiAvailabilitySubscriptionCreate(c, createData, consumerNfId)
 }
 
 func (s *Server) NSSAIAvailabilityOptions(c *gin.Context) {
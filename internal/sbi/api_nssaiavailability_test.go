@@ -0,0 +1,643 @@
+package sbi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+var (
+	testPlmnId = &models.PlmnId{Mcc: "208", Mnc: "93"}
+	testTai1   = &models.Tai{PlmnId: testPlmnId, Tac: "33456"}
+	testTai2   = &models.Tai{PlmnId: testPlmnId, Tac: "33457"}
+)
+
+func setNssaiAvailabilityConfig(t *testing.T) {
+	origin := factory.NssfConfig
+	t.Cleanup(func() {
+		factory.NssfConfig = origin
+	})
+
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			AmfList: []factory.AmfConfig{
+				{
+					NfId:     "469de254-2fe5-4ca0-8381-af3f500af77c",
+					AmfSetId: "2080931",
+					SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{
+							Tai:                 testTai1,
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}, {Sst: 1, Sd: "000001"}},
+						},
+						{
+							Tai:                 testTai2,
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "000002"}},
+						},
+					},
+				},
+				{
+					NfId:     "b9e6e2cb-5ce8-4cb6-9173-a266dd9a2f0c",
+					AmfSetId: "2080932",
+					SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{
+							Tai:                 testTai1,
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 2}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func newNssaiAvailabilityRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	s := newTestServer(t)
+	router := gin.New()
+	AddService(router.Group(factory.NssfNssaiavailResUriPrefix), s.getNssaiAvailabilityRoutes())
+	return router
+}
+
+func serveNssaiAvailability(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	httpRecorder := httptest.NewRecorder()
+	req := httptest.NewRequest(method, factory.NssfNssaiavailResUriPrefix+path, nil)
+	router.ServeHTTP(httpRecorder, req)
+	return httpRecorder
+}
+
+func TestNssaiAvailabilitySubscriptionsSegment(t *testing.T) {
+	setNssaiAvailabilityConfig(t)
+	router := newNssaiAvailabilityRouter(t)
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete, http.MethodPatch, http.MethodPut} {
+		t.Run(method, func(t *testing.T) {
+			httpRecorder := serveNssaiAvailability(router, method, "/nssai-availability/subscriptions")
+			if httpRecorder.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("Expected status code %d, got: %d", http.StatusMethodNotAllowed, httpRecorder.Code)
+			}
+			if allow := httpRecorder.Header().Get("Allow"); allow != http.MethodPost {
+				t.Errorf("Expected Allow header '%s', got: '%s'", http.MethodPost, allow)
+			}
+			var problemDetails models.ProblemDetails
+			if err := json.Unmarshal(httpRecorder.Body.Bytes(), &problemDetails); err != nil {
+				t.Fatalf("Error unmarshalling response body: %v", err)
+			}
+			if problemDetails.Status != http.StatusMethodNotAllowed {
+				t.Errorf("Expected problemDetails.Status to be %d, got: %d",
+					http.StatusMethodNotAllowed, problemDetails.Status)
+			}
+		})
+	}
+}
+
+func TestNssaiAvailabilityGet(t *testing.T) {
+	setNssaiAvailabilityConfig(t)
+	router := newNssaiAvailabilityRouter(t)
+
+	httpRecorder := serveNssaiAvailability(router, http.MethodGet,
+		"/nssai-availability/469de254-2fe5-4ca0-8381-af3f500af77c")
+	if httpRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+	}
+	if httpRecorder.Header().Get("ETag") == "" {
+		t.Errorf("Expected ETag header to be set")
+	}
+
+	// The stored information
+	var info models.NssaiAvailabilityInfo
+	if err := json.Unmarshal(httpRecorder.Body.Bytes(), &info); err != nil {
+		t.Fatalf("Error unmarshalling response body as NssaiAvailabilityInfo: %v", err)
+	}
+	if len(info.SupportedNssaiAvailabilityData) != 2 {
+		t.Errorf("Expected 2 supported NSSAI availability data, got: %d", len(info.SupportedNssaiAvailabilityData))
+	}
+	if info.AmfSetId != "2080931" {
+		t.Errorf("Expected AMF set ID '2080931', got: '%s'", info.AmfSetId)
+	}
+
+	// The authorized view returned to PUT
+	httpRecorder = serveNssaiAvailability(router, http.MethodGet,
+		"/nssai-availability/469de254-2fe5-4ca0-8381-af3f500af77c?view=authorized")
+	if httpRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+	}
+	var authorizedInfo models.AuthorizedNssaiAvailabilityInfo
+	if err := json.Unmarshal(httpRecorder.Body.Bytes(), &authorizedInfo); err != nil {
+		t.Fatalf("Error unmarshalling response body as AuthorizedNssaiAvailabilityInfo: %v", err)
+	}
+	if len(authorizedInfo.AuthorizedNssaiAvailabilityData) != 2 {
+		t.Fatalf("Expected 2 authorized NSSAI availability data, got: %d",
+			len(authorizedInfo.AuthorizedNssaiAvailabilityData))
+	}
+	for _, data := range authorizedInfo.AuthorizedNssaiAvailabilityData {
+		if data.Tai == nil || len(data.SupportedSnssaiList) == 0 {
+			t.Errorf("Expected TAI and supported S-NSSAIs in authorized data, got: %+v", data)
+		}
+	}
+
+	httpRecorder = serveNssaiAvailability(router, http.MethodGet,
+		"/nssai-availability/469de254-2fe5-4ca0-8381-af3f500af77c?view=unknown")
+	if httpRecorder.Code != http.StatusBadRequest {
+		t.Errorf("Expected status code %d, got: %d", http.StatusBadRequest, httpRecorder.Code)
+	}
+
+	httpRecorder = serveNssaiAvailability(router, http.MethodGet, "/nssai-availability/unknown-nf")
+	if httpRecorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got: %d", http.StatusNotFound, httpRecorder.Code)
+	}
+}
+
+func TestNssaiAvailabilityList(t *testing.T) {
+	setNssaiAvailabilityConfig(t)
+	router := newNssaiAvailabilityRouter(t)
+
+	mustMarshal := func(v any) string {
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("Error marshalling query: %v", err)
+		}
+		return string(b)
+	}
+
+	testCases := []struct {
+		name         string
+		query        url.Values
+		status       int
+		expectNfIds  []string
+		expectTaiLen []int
+	}{
+		{
+			name:         "Without filter",
+			query:        url.Values{},
+			status:       http.StatusOK,
+			expectNfIds:  []string{"469de254-2fe5-4ca0-8381-af3f500af77c", "b9e6e2cb-5ce8-4cb6-9173-a266dd9a2f0c"},
+			expectTaiLen: []int{2, 1},
+		},
+		{
+			name:         "Filter by TAI",
+			query:        url.Values{"tai": {mustMarshal(testTai2)}},
+			status:       http.StatusOK,
+			expectNfIds:  []string{"469de254-2fe5-4ca0-8381-af3f500af77c"},
+			expectTaiLen: []int{1},
+		},
+		{
+			name:         "Filter by S-NSSAI",
+			query:        url.Values{"snssai": {mustMarshal(models.Snssai{Sst: 2})}},
+			status:       http.StatusOK,
+			expectNfIds:  []string{"b9e6e2cb-5ce8-4cb6-9173-a266dd9a2f0c"},
+			expectTaiLen: []int{1},
+		},
+		{
+			name:         "Filter by AMF set",
+			query:        url.Values{"amf-set-id": {"2080931"}},
+			status:       http.StatusOK,
+			expectNfIds:  []string{"469de254-2fe5-4ca0-8381-af3f500af77c"},
+			expectTaiLen: []int{2},
+		},
+		{
+			name:        "No match",
+			query:       url.Values{"snssai": {mustMarshal(models.Snssai{Sst: 3})}},
+			status:      http.StatusOK,
+			expectNfIds: []string{},
+		},
+		{
+			name:   "Malformed TAI",
+			query:  url.Values{"tai": {"{"}},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Unknown view",
+			query:  url.Values{"view": {"unknown"}},
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			httpRecorder := serveNssaiAvailability(router, http.MethodGet,
+				"/nssai-availability?"+tc.query.Encode())
+			if httpRecorder.Code != tc.status {
+				t.Fatalf("Expected status code %d, got: %d", tc.status, httpRecorder.Code)
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			// NssaiAvailabilityInfo indexed by the NF ID
+			var list map[string]models.NssaiAvailabilityInfo
+			if err := json.Unmarshal(httpRecorder.Body.Bytes(), &list); err != nil {
+				t.Fatalf("Error unmarshalling response body: %v", err)
+			}
+			if len(list) != len(tc.expectNfIds) {
+				t.Fatalf("Expected %d NSSAI availability information, got: %d", len(tc.expectNfIds), len(list))
+			}
+			for i, nfId := range tc.expectNfIds {
+				info, ok := list[nfId]
+				if !ok {
+					t.Errorf("Expected NSSAI availability information of '%s'", nfId)
+					continue
+				}
+				if len(info.SupportedNssaiAvailabilityData) != tc.expectTaiLen[i] {
+					t.Errorf("Expected %d TAIs of '%s', got: %d",
+						tc.expectTaiLen[i], nfId, len(info.SupportedNssaiAvailabilityData))
+				}
+			}
+		})
+	}
+}
+
+func TestNssaiAvailabilityPatch(t *testing.T) {
+	const amfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+	const tai1 = `{"plmnId":{"mcc":"208","mnc":"93"},"tac":"33456"}`
+
+	testCases := []struct {
+		name        string
+		contentType string
+		ifMatch     string
+		body        string
+		status      int
+		// TACs of the changed TAIs in the response
+		expectChanged []string
+		// Number of S-NSSAIs per TAC stored after the patch
+		expectStored map[string]int
+	}{
+		{
+			name:          "JSON Patch",
+			contentType:   "application/json-patch+json",
+			body:          `[{"op":"remove","path":"/1"}]`,
+			status:        http.StatusOK,
+			expectChanged: []string{"33457"},
+			expectStored:  map[string]int{"33456": 2},
+		},
+		{
+			name:          "JSON Patch as JSON",
+			contentType:   "application/json",
+			body:          `[{"op":"add","path":"/0/supportedSnssaiList/-","value":{"sst":2}}]`,
+			status:        http.StatusOK,
+			expectChanged: []string{"33456"},
+			expectStored:  map[string]int{"33456": 3, "33457": 1},
+		},
+		{
+			name:        "Merge patch authorized like JSON Patch",
+			contentType: "application/merge-patch+json",
+			body: `[{"tai":` + tai1 + `,"supportedSnssaiList":` +
+				`[{"sst":1},{"sst":1,"sd":"000001"},{"sst":1,"sd":"000002"}]}]`,
+			status:        http.StatusOK,
+			expectChanged: []string{"33457"},
+			expectStored:  map[string]int{"33456": 2},
+		},
+		{
+			name:          "Merge patch changing S-NSSAIs",
+			contentType:   "application/merge-patch+json",
+			body:          `[{"tai":` + tai1 + `,"supportedSnssaiList":[{"sst":1}]}]`,
+			status:        http.StatusOK,
+			expectChanged: []string{"33456", "33457"},
+			expectStored:  map[string]int{"33456": 1},
+		},
+		{
+			name:         "Merge patch with S-NSSAI not supported in PLMN",
+			contentType:  "application/merge-patch+json",
+			body:         `[{"tai":` + tai1 + `,"supportedSnssaiList":[{"sst":1,"sd":"000003"}]}]`,
+			status:       http.StatusForbidden,
+			expectStored: map[string]int{"33456": 2, "33457": 1},
+		},
+		{
+			name:         "Merge patch not matching the schema",
+			contentType:  "application/merge-patch+json",
+			body:         `{"supportedNssaiAvailabilityData":[]}`,
+			status:       http.StatusBadRequest,
+			expectStored: map[string]int{"33456": 2, "33457": 1},
+		},
+		{
+			name:         "Malformed merge patch",
+			contentType:  "application/merge-patch+json",
+			body:         `[`,
+			status:       http.StatusBadRequest,
+			expectStored: map[string]int{"33456": 2, "33457": 1},
+		},
+		{
+			name:         "Merge patch without TAC",
+			contentType:  "application/merge-patch+json",
+			body:         `[{"tai":{"plmnId":{"mcc":"208","mnc":"93"}},"supportedSnssaiList":[{"sst":1}]}]`,
+			status:       http.StatusUnprocessableEntity,
+			expectStored: map[string]int{"33456": 2, "33457": 1},
+		},
+		{
+			name:         "Merge patch with stale entity tag",
+			contentType:  "application/merge-patch+json",
+			ifMatch:      `"stale"`,
+			body:         `[]`,
+			status:       http.StatusPreconditionFailed,
+			expectStored: map[string]int{"33456": 2, "33457": 1},
+		},
+		{
+			name:         "Unsupported media type",
+			contentType:  "text/plain",
+			body:         `[]`,
+			status:       http.StatusUnsupportedMediaType,
+			expectStored: map[string]int{"33456": 2, "33457": 1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setNssaiAvailabilityConfig(t)
+			configuration := factory.NssfConfig.Configuration
+			configuration.SupportedNssaiInPlmnList = []factory.SupportedNssaiInPlmn{
+				{PlmnId: testPlmnId, SupportedSnssaiList: []models.Snssai{{Sst: 1, Sd: "000001"}, {Sst: 1, Sd: "000002"}}},
+			}
+			configuration.TaList = []factory.TaConfig{
+				{
+					Tai: testTai1,
+					SupportedSnssaiList: []models.ExtSnssai{
+						{Sst: 1}, {Sst: 1, Sd: "000001"}, {Sst: 1, Sd: "000002"}, {Sst: 2},
+					},
+				},
+				{Tai: testTai2, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "000002"}}},
+			}
+			// S-NSSAI 1-000002 is not authorized to the AMF in the first TA
+			configuration.AmfPolicyList = []factory.AmfPolicy{
+				{
+					NfId: amfId,
+					AuthorizedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{Tai: testTai1, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}, {Sst: 1, Sd: "000001"}, {Sst: 2}}},
+						{Tai: testTai2, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "000002"}}},
+					},
+				},
+			}
+			router := newNssaiAvailabilityRouter(t)
+
+			httpRecorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch,
+				factory.NssfNssaiavailResUriPrefix+"/nssai-availability/"+amfId, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			if tc.ifMatch != "" {
+				req.Header.Set("If-Match", tc.ifMatch)
+			}
+			router.ServeHTTP(httpRecorder, req)
+			if httpRecorder.Code != tc.status {
+				t.Fatalf("Expected status code %d, got: %d, body: %s", tc.status, httpRecorder.Code,
+					httpRecorder.Body.String())
+			}
+
+			stored := make(map[string]int)
+			for _, data := range configuration.AmfList[0].SupportedNssaiAvailabilityData {
+				stored[data.Tai.Tac] = len(data.SupportedSnssaiList)
+			}
+			if !reflect.DeepEqual(stored, tc.expectStored) {
+				t.Errorf("Expected stored S-NSSAIs per TAC %v, got: %v", tc.expectStored, stored)
+			}
+
+			if tc.status == http.StatusUnsupportedMediaType {
+				if acceptPatch := httpRecorder.Header().Get("Accept-Patch"); acceptPatch == "" {
+					t.Errorf("Expected Accept-Patch header to be set")
+				}
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			if httpRecorder.Header().Get("ETag") == "" {
+				t.Errorf("Expected ETag header to be set")
+			}
+			var info models.AuthorizedNssaiAvailabilityInfo
+			if err := json.Unmarshal(httpRecorder.Body.Bytes(), &info); err != nil {
+				t.Fatalf("Error unmarshalling response body: %v", err)
+			}
+			changed := []string{}
+			for _, data := range info.AuthorizedNssaiAvailabilityData {
+				changed = append(changed, data.Tai.Tac)
+			}
+			sort.Strings(changed)
+			if !reflect.DeepEqual(changed, tc.expectChanged) {
+				t.Errorf("Expected changed TACs %v, got: %v", tc.expectChanged, changed)
+			}
+		})
+	}
+}
+
+func TestNssaiAvailabilitySubscriptionGet(t *testing.T) {
+	setNssaiAvailabilityConfig(t)
+	router := newNssaiAvailabilityRouter(t)
+
+	subscription := nssf_context.GetSelf().Subscriptions.Add(models.NssfEventSubscriptionCreateData{
+		NfNssaiAvailabilityUri: "https://amf.example.com/callback",
+		TaiList:                []models.Tai{*testTai1},
+		AmfSetId:               "2080931",
+		Event:                  models.NssfEventType_SNSSAI_STATUS_CHANGE_REPORT,
+	}, "")
+	t.Cleanup(func() {
+		nssf_context.GetSelf().Subscriptions.Remove(subscription.SubscriptionId)
+	})
+
+	httpRecorder := serveNssaiAvailability(router, http.MethodGet,
+		"/nssai-availability/subscriptions/"+subscription.SubscriptionId)
+	if httpRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+	}
+
+	var createdData models.NssfEventSubscriptionCreatedData
+	if err := json.Unmarshal(httpRecorder.Body.Bytes(), &createdData); err != nil {
+		t.Fatalf("Error unmarshalling response body: %v", err)
+	}
+	if createdData.SubscriptionId != subscription.SubscriptionId {
+		t.Errorf("Expected subscription ID '%s', got: '%s'", subscription.SubscriptionId, createdData.SubscriptionId)
+	}
+	if len(createdData.AuthorizedNssaiAvailabilityData) != 1 {
+		t.Fatalf("Expected 1 authorized NSSAI availability data, got: %d",
+			len(createdData.AuthorizedNssaiAvailabilityData))
+	}
+	// S-NSSAIs supported by AMFs in the AMF set only
+	if n := len(createdData.AuthorizedNssaiAvailabilityData[0].SupportedSnssaiList); n != 2 {
+		t.Errorf("Expected 2 supported S-NSSAIs, got: %d", n)
+	}
+
+	httpRecorder = serveNssaiAvailability(router, http.MethodGet, "/nssai-availability/subscriptions/unknown")
+	if httpRecorder.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got: %d", http.StatusNotFound, httpRecorder.Code)
+	}
+}
+
+func TestNssaiAvailabilitySubscriptionCreate(t *testing.T) {
+	setNssaiAvailabilityConfig(t)
+	gin.SetMode(gin.TestMode)
+	s := newTestServer(t)
+	s.nssfApp.(*testNssfApp).EXPECT().Context().Return(nssf_context.GetSelf()).AnyTimes()
+	router := gin.New()
+	AddService(router.Group(factory.NssfNssaiavailResUriPrefix), s.getNssaiAvailabilityRoutes())
+
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{
+			name: "Subscription created",
+			body: `{"nfNssaiAvailabilityUri":"https://amf.example.com/callback",` +
+				`"taiList":[{"plmnId":{"mcc":"208","mnc":"93"},"tac":"33456"}],` +
+				`"event":"SNSSAI_STATUS_CHANGE_REPORT"}`,
+			status: http.StatusCreated,
+		},
+		{
+			name:   "Callback URI not allowed",
+			body:   `{"nfNssaiAvailabilityUri":"http://127.0.0.1/callback","event":"SNSSAI_STATUS_CHANGE_REPORT"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Malformed body",
+			body:   `{`,
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			count := nssf_context.GetSelf().Subscriptions.Count()
+
+			httpRecorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost,
+				factory.NssfNssaiavailResUriPrefix+"/nssai-availability/subscriptions", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(httpRecorder, req)
+			if httpRecorder.Code != tc.status {
+				t.Fatalf("Expected status code %d, got: %d", tc.status, httpRecorder.Code)
+			}
+			if tc.status != http.StatusCreated {
+				if n := nssf_context.GetSelf().Subscriptions.Count(); n != count {
+					t.Errorf("Expected %d subscriptions, got: %d", count, n)
+				}
+				return
+			}
+
+			var createdData models.NssfEventSubscriptionCreatedData
+			if err := json.Unmarshal(httpRecorder.Body.Bytes(), &createdData); err != nil {
+				t.Fatalf("Error unmarshalling response body: %v", err)
+			}
+			t.Cleanup(func() {
+				nssf_context.GetSelf().Subscriptions.Remove(createdData.SubscriptionId)
+			})
+			if _, ok := nssf_context.GetSelf().Subscriptions.Get(createdData.SubscriptionId); !ok {
+				t.Errorf("Expected subscription '%s' to be stored", createdData.SubscriptionId)
+			}
+
+			// The Location header identifies the created subscription resource
+			location := httpRecorder.Header().Get("Location")
+			expected := factory.NssfNssaiavailResUriPrefix + "/nssai-availability/subscriptions/" +
+				createdData.SubscriptionId
+			if !strings.HasSuffix(location, expected) {
+				t.Errorf("Expected Location header to end with '%s', got: '%s'", expected, location)
+			}
+		})
+	}
+}
+
+// Notifier recording IDs of subscriptions notified
+type recordingNotifier struct {
+	mu              sync.Mutex
+	subscriptionIds []string
+}
+
+func (n *recordingNotifier) SendNssfEventNotification(
+	ctx context.Context, uri string, notification models.NssfEventNotification,
+) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.subscriptionIds = append(n.subscriptionIds, notification.SubscriptionId)
+	return nil
+}
+
+func TestNssaiAvailabilitySubscriptionPurgedWithAmf(t *testing.T) {
+	const amfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+
+	setNssaiAvailabilityConfig(t)
+	factory.NssfConfig.Configuration.TaList = []factory.TaConfig{
+		{Tai: testTai1, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}, {Sst: 1, Sd: "000001"}, {Sst: 2}}},
+	}
+	gin.SetMode(gin.TestMode)
+	s := newTestServer(t)
+	s.nssfApp.(*testNssfApp).EXPECT().Context().Return(nssf_context.GetSelf()).AnyTimes()
+	notifier := &recordingNotifier{}
+	s.Processor().SetNotifier(notifier)
+	router := gin.New()
+	AddService(router.Group(factory.NssfNssaiavailResUriPrefix), s.getNssaiAvailabilityRoutes())
+
+	// The AMF is identified by the headers of the request, since OAuth2 is not required
+	nssfCtx := nssf_context.GetSelf()
+	defer nssfCtx.OAuth2Required.Store(nssfCtx.OAuth2Required.Load())
+	nssfCtx.OAuth2Required.Store(false)
+
+	subscribe := func(name, value string) string {
+		httpRecorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost,
+			factory.NssfNssaiavailResUriPrefix+"/nssai-availability/subscriptions", strings.NewReader(
+				`{"nfNssaiAvailabilityUri":"https://amf.example.com/callback",`+
+					`"taiList":[{"plmnId":{"mcc":"208","mnc":"93"},"tac":"33456"}],`+
+					`"event":"SNSSAI_STATUS_CHANGE_REPORT"}`))
+		if name != "" {
+			req.Header.Set(name, value)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httpRecorder, req)
+		if httpRecorder.Code != http.StatusCreated {
+			t.Fatalf("Expected status code %d, got: %d", http.StatusCreated, httpRecorder.Code)
+		}
+		var createdData models.NssfEventSubscriptionCreatedData
+		if err := json.Unmarshal(httpRecorder.Body.Bytes(), &createdData); err != nil {
+			t.Fatalf("Error unmarshalling response body: %v", err)
+		}
+		t.Cleanup(func() {
+			nssfCtx.Subscriptions.Remove(createdData.SubscriptionId)
+		})
+		return createdData.SubscriptionId
+	}
+	byPeerInfo := subscribe(peerInfoHeader, "srcinst="+amfId)
+	byUserAgent := subscribe("User-Agent", "AMF-"+amfId)
+	remaining := subscribe("", "")
+
+	const notifyToken = "notify-token"
+	nssfCtx.NrfAmfStatusSubscription.Set("nrf-subscription", notifyToken, time.Time{})
+	defer nssfCtx.NrfAmfStatusSubscription.Clear("nrf-subscription")
+
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	s.Processor().NfStatusNotify(c, notifyToken, models.NrfNfManagementNotificationData{
+		Event:         models.NotificationEventType_DEREGISTERED,
+		NfInstanceUri: "http://127.0.0.10:8000/nnrf-nfm/v1/nf-instances/" + amfId,
+	})
+	if c.Writer.Status() != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got: %d", http.StatusNoContent, c.Writer.Status())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Processor().WaitNotifications(ctx); err != nil {
+		t.Fatalf("Error waiting for notifications: %v", err)
+	}
+
+	for _, subscriptionId := range []string{byPeerInfo, byUserAgent} {
+		if _, ok := nssfCtx.Subscriptions.Get(subscriptionId); ok {
+			t.Errorf("Expected subscription '%s' of the AMF to be purged", subscriptionId)
+		}
+	}
+	if _, ok := nssfCtx.Subscriptions.Get(remaining); !ok {
+		t.Fatalf("Expected subscription '%s' of another NF to be kept", remaining)
+	}
+	// The remaining subscriber is notified of the NSSAI availability no longer provided by the AMF
+	if len(notifier.subscriptionIds) != 1 || notifier.subscriptionIds[0] != remaining {
+		t.Errorf("Expected subscription '%s' to be notified, got: %v", remaining, notifier.subscriptionIds)
+	}
+}
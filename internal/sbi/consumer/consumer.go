@@ -2,6 +2,7 @@ package consumer
 
 import (
 	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/nssf/pkg/factory"
 	"github.com/free5gc/openapi/nrf/NFManagement"
 	sbi_metrics "github.com/free5gc/util/metrics/sbi"
 )
@@ -10,6 +11,8 @@ type Consumer struct {
 	app.NssfApp
 
 	*NrfService
+	*NsacfService
+	*NotificationService
 }
 
 func NewConsumer(nssf app.NssfApp) *Consumer {
@@ -20,8 +23,15 @@ func NewConsumer(nssf app.NssfApp) *Consumer {
 		nrfNfMgmtClient: NFManagement.NewAPIClient(configuration),
 	}
 
+	var nsacfService *NsacfService
+	if nssf.Config().IsNsacEnabled() && nssf.Config().GetNsacMode() == factory.NsacModeNsacf {
+		nsacfService = newNsacfService(nssf.Config().GetNsacfUri())
+	}
+
 	return &Consumer{
-		NssfApp:    nssf,
-		NrfService: nrfService,
+		NssfApp:             nssf,
+		NrfService:          nrfService,
+		NsacfService:        nsacfService,
+		NotificationService: newNotificationService(nssf.Config().GetNotificationConfig()),
 	}
 }
@@ -0,0 +1,154 @@
+/*
+ * NSSF Consumer
+ *
+ * NSSAI Availability Notification
+ */
+
+package consumer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	sbi_metrics "github.com/free5gc/util/metrics/sbi"
+)
+
+type notifyConfiguration struct {
+	httpClient *http.Client
+}
+
+var _ openapi.Configuration = &notifyConfiguration{}
+
+func (c *notifyConfiguration) BasePath() string                    { return "" }
+func (c *notifyConfiguration) Host() string                        { return "" }
+func (c *notifyConfiguration) UserAgent() string                   { return "NSSF" }
+func (c *notifyConfiguration) DefaultHeader() map[string]string    { return nil }
+func (c *notifyConfiguration) HTTPClient() *http.Client            { return c.httpClient }
+func (c *notifyConfiguration) Metrics() openapi.RequestMetricsHook { return sbi_metrics.SbiMetricHook }
+
+const notificationTimeout = 10 * time.Second
+
+// Service of AMF which the callback URIs for NSSAI availability notifications belong to, as the scope of the
+// access token, since notifications are requests to the AMF instead of to the NSSAI availability service of NSSF
+const serviceNameAmfCallback models.ServiceName = "namf-callback"
+
+// Sender of NSSAI availability notifications to the callback URIs of subscribers
+type NotificationService struct {
+	cfg *notifyConfiguration
+}
+
+func newNotificationService(policy factory.NotificationConfig) *NotificationService {
+	return &NotificationService{
+		cfg: &notifyConfiguration{
+			httpClient: &http.Client{
+				// Trace context is propagated to subscribers like to NRF by the clients of openapi
+				Transport: otelhttp.NewTransport(newSubscriberTransport(policy)),
+				Timeout:   notificationTimeout,
+			},
+		},
+	}
+}
+
+// Create the transport to subscribers, which dials only the addresses allowed by the notification policy
+func newSubscriberTransport(policy factory.NotificationConfig) *http.Transport {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: policy.InsecureSkipVerify,
+	}
+	if policy.CaCertPem != "" {
+		if pem, err := os.ReadFile(policy.CaCertPem); err != nil {
+			logger.ConsumerLog.Errorf("Read CA certificates of subscribers failed: %+v", err)
+		} else {
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				logger.ConsumerLog.Errorf("No CA certificate of subscribers found in %s", policy.CaCertPem)
+			}
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	// Subscribers are connected directly, since the address checked on dialing would be the one of a proxy
+	transport.Proxy = nil
+	transport.DialContext = dialSubscriber
+	return transport
+}
+
+// Dial the subscriber with the resolved address checked against the notification policy
+func dialSubscriber(ctx context.Context, network, address string) (net.Conn, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{
+		Timeout: notificationTimeout,
+		Control: func(_, resolved string, _ syscall.RawConn) error {
+			ip, _, splitErr := net.SplitHostPort(resolved)
+			if splitErr != nil {
+				return splitErr
+			}
+			return util.CheckCallbackAddress(host, net.ParseIP(ip))
+		},
+	}
+	return dialer.DialContext(ctx, network, address)
+}
+
+// Send the notification to the callback URI, with `traceparent` of the span in ctx
+func (ns *NotificationService) SendNssfEventNotification(
+	ctx context.Context, uri string, notification models.NssfEventNotification,
+) error {
+	// Access token is requested only if OAuth2 is required by NRF
+	tokenCtx, pd, err := nssf_context.GetSelf().GetTokenCtx(serviceNameAmfCallback,
+		models.NrfNfManagementNfType_AMF)
+	if err != nil {
+		return err
+	} else if pd != nil {
+		return fmt.Errorf("get token of subscriber failed: %s", pd.Detail)
+	}
+	if tokenCtx != nil {
+		if tok := tokenCtx.Value(openapi.ContextOAuth2); tok != nil {
+			ctx = context.WithValue(ctx, openapi.ContextOAuth2, tok)
+		}
+	}
+
+	headerParams := map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	req, err := openapi.PrepareRequest(ctx, ns.cfg, uri, http.MethodPost,
+		&notification, headerParams, url.Values{}, url.Values{}, "", "", nil)
+	if err != nil {
+		return err
+	}
+
+	rsp, err := openapi.CallAPI(ns.cfg, req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if rspCloseErr := rsp.Body.Close(); rspCloseErr != nil {
+			logger.ConsumerLog.Errorf("Notification response body cannot close: %+v", rspCloseErr)
+		}
+	}()
+
+	if rsp.StatusCode != http.StatusNoContent && rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("subscriber %s responded with status %d", uri, rsp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/internal/tracing"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/openapi/nrf/NFManagement"
+)
+
+func TestNotificationServiceDial(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	testCases := []struct {
+		name          string
+		policy        factory.NotificationConfig
+		expectAllowed bool
+	}{
+		{"Loopback subscriber is rejected", factory.NotificationConfig{}, false},
+		{"Loopback subscriber allowed by policy", factory.NotificationConfig{AllowPrivateTargets: true}, true},
+		{
+			"Loopback subscriber listed in allowed hosts",
+			factory.NotificationConfig{AllowedHosts: []string{"127.0.0.0/8"}},
+			true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory.NssfConfig = &factory.Config{
+				Configuration: &factory.Configuration{
+					Notification: &tc.policy,
+				},
+			}
+			if newSubscriberTransport(tc.policy).Proxy != nil {
+				t.Errorf("Expected subscribers to be connected without proxy")
+			}
+
+			ns := newNotificationService(tc.policy)
+			rsp, err := ns.cfg.httpClient.Post(srv.URL, "application/json", http.NoBody)
+			if rsp != nil {
+				rsp.Body.Close()
+			}
+			if tc.expectAllowed && err != nil {
+				t.Errorf("Expected subscriber to be connected, got: %+v", err)
+			}
+			if !tc.expectAllowed && err == nil {
+				t.Errorf("Expected connection to subscriber to be rejected")
+			}
+		})
+	}
+}
+
+func TestNotificationAccessToken(t *testing.T) {
+	scopes := make(chan string, 1)
+	nrf := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/oauth2/token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		scopes <- r.PostForm.Get("scope")
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(models.NrfAccessTokenAccessTokenRsp{
+			AccessToken: "amf-callback-token",
+			TokenType:   "Bearer",
+			ExpiresIn:   int32(time.Now().Add(time.Hour).Unix()),
+		}); err != nil {
+			t.Errorf("Encode access token failed: %+v", err)
+		}
+	}))
+	// NRF client speaks HTTP/2 without TLS
+	nrf.Config.Protocols = &http.Protocols{}
+	nrf.Config.Protocols.SetHTTP1(true)
+	nrf.Config.Protocols.SetUnencryptedHTTP2(true)
+	nrf.Start()
+	defer nrf.Close()
+
+	authorizations := make(chan string, 1)
+	amf := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations <- r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer amf.Close()
+
+	nssfCtx := nssf_context.GetSelf()
+	nrfUri := nssfCtx.NrfUri
+	nssfCtx.NrfUri = nrf.URL
+	nssfCtx.OAuth2Required.Store(true)
+	defer func() {
+		nssfCtx.NrfUri = nrfUri
+		nssfCtx.OAuth2Required.Store(false)
+	}()
+
+	policy := factory.NotificationConfig{AllowPrivateTargets: true}
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			Notification: &policy,
+		},
+	}
+	ns := newNotificationService(policy)
+
+	err := ns.SendNssfEventNotification(context.Background(), amf.URL+"/callback", models.NssfEventNotification{
+		SubscriptionId: "1",
+	})
+	if err != nil {
+		t.Fatalf("Send notification failed: %+v", err)
+	}
+	if scope := <-scopes; scope != string(serviceNameAmfCallback) {
+		t.Errorf("Expected access token of scope %q, got: %q", serviceNameAmfCallback, scope)
+	}
+	if authorization := <-authorizations; authorization != "Bearer amf-callback-token" {
+		t.Errorf("Expected notification with access token, got Authorization: %q", authorization)
+	}
+}
+
+// Set up propagation and a recording tracer provider as tracing.Init does when tracing is enabled
+func setUpTracing(t *testing.T) {
+	if _, err := tracing.Init(factory.Tracing{}, ""); err != nil {
+		t.Fatalf("Init tracing failed: %+v", err)
+	}
+	provider := sdktrace.NewTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		if err := provider.Shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown tracer provider failed: %+v", err)
+		}
+	})
+}
+
+func TestOutboundTraceContext(t *testing.T) {
+	setUpTracing(t)
+
+	traceparents := make(chan string, 1)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents <- r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("Notification to subscriber", func(t *testing.T) {
+		srv := httptest.NewServer(handler)
+		defer srv.Close()
+
+		policy := factory.NotificationConfig{AllowPrivateTargets: true}
+		factory.NssfConfig = &factory.Config{
+			Configuration: &factory.Configuration{
+				Notification: &policy,
+			},
+		}
+		ns := newNotificationService(policy)
+
+		ctx, span := tracing.Start(context.Background(), "test")
+		defer span.End()
+		err := ns.SendNssfEventNotification(ctx, srv.URL+"/callback", models.NssfEventNotification{
+			SubscriptionId: "1",
+		})
+		if err != nil {
+			t.Fatalf("Send notification failed: %+v", err)
+		}
+		checkTraceparent(t, <-traceparents, span.SpanContext().TraceID().String())
+	})
+
+	t.Run("Request to NRF", func(t *testing.T) {
+		srv := httptest.NewUnstartedServer(handler)
+		// NRF client speaks HTTP/2 without TLS
+		srv.Config.Protocols = &http.Protocols{}
+		srv.Config.Protocols.SetHTTP1(true)
+		srv.Config.Protocols.SetUnencryptedHTTP2(true)
+		srv.Start()
+		defer srv.Close()
+
+		configuration := NFManagement.NewConfiguration()
+		configuration.SetBasePath(srv.URL)
+		ns := &NrfService{nrfNfMgmtClient: NFManagement.NewAPIClient(configuration)}
+		if _, err := ns.SendDeregisterNFInstance("b9a9ce1c-6c3b-4f44-9a3c-2b2d1d1c3a01"); err != nil {
+			t.Fatalf("Deregister from NRF failed: %+v", err)
+		}
+		checkTraceparent(t, <-traceparents, "")
+	})
+}
+
+// Check the traceparent header is valid, and of the trace if traceId is not empty
+func checkTraceparent(t *testing.T, traceparent string, traceId string) {
+	t.Helper()
+	ctx := propagation.TraceContext{}.Extract(context.Background(),
+		propagation.MapCarrier{"traceparent": traceparent})
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		t.Fatalf("Expected valid traceparent, got: %q", traceparent)
+	}
+	if traceId != "" && spanContext.TraceID().String() != traceId {
+		t.Errorf("Expected traceparent of trace %s, got: %q", traceId, traceparent)
+	}
+}
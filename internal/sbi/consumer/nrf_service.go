@@ -14,6 +14,9 @@ import (
 
 	nssf_context "github.com/free5gc/nssf/internal/context"
 	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/tracing"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/factory"
 	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
 	"github.com/free5gc/openapi/nrf/NFManagement"
@@ -27,13 +30,14 @@ type NrfService struct {
 func (ns *NrfService) buildNFProfile(context *nssf_context.NSSFContext) (
 	profile models.NrfNfManagementNfProfile, err error,
 ) {
-	profile.NfInstanceId = context.NfId
+	profile.NfInstanceId = context.NfId()
 	profile.NfType = models.NrfNfManagementNfType_NSSF
 	profile.NfStatus = models.NrfNfManagementNfStatus_REGISTERED
 	profile.PlmnList = context.SupportedPlmnList
 	profile.Ipv4Addresses = []string{context.RegisterIPv4}
 	var services []models.NrfNfManagementNfService
 	for _, nfService := range context.NfService {
+		nfService.SupportedFeatures = util.GetSupportedFeatures(nfService.ServiceName)
 		services = append(services, nfService)
 	}
 	if len(services) > 0 {
@@ -45,20 +49,25 @@ func (ns *NrfService) buildNFProfile(context *nssf_context.NSSFContext) (
 func (ns *NrfService) SendRegisterNFInstance(ctx context.Context, nssfCtx *nssf_context.NSSFContext) (
 	resourceNrfUri string, retrieveNfInstanceId string, err error,
 ) {
-	nfInstanceId := nssfCtx.NfId
+	nfInstanceId := nssfCtx.NfId()
 	profile, err := ns.buildNFProfile(nssfCtx)
 	if err != nil {
 		return "", "", fmt.Errorf("failed to build nrf profile: %s", err.Error())
 	}
 	apiClient := ns.nrfNfMgmtClient
 
+	ctx, span := tracing.Start(ctx, "nrf.RegisterNFInstance")
+	defer span.End()
+
 	var res *NFManagement.RegisterNFInstanceResponse
 	var nf models.NrfNfManagementNfProfile
 	finish := false
 	for !finish {
 		select {
 		case <-ctx.Done():
-			return "", "", fmt.Errorf("context done")
+			err = fmt.Errorf("context done")
+			tracing.RecordError(span, err)
+			return "", "", err
 
 		default:
 			req := &NFManagement.RegisterNFInstanceRequest{
@@ -71,7 +80,10 @@ func (ns *NrfService) SendRegisterNFInstance(ctx context.Context, nssfCtx *nssf_
 				// TODO : add log
 				logger.ConsumerLog.Errorf("NSSF register to NRF Error[%s]", err.Error())
 				const retryInterval = 2 * time.Second
-				time.Sleep(retryInterval)
+				select {
+				case <-ctx.Done():
+				case <-time.After(retryInterval):
+				}
 				continue
 			}
 
@@ -88,7 +100,7 @@ func (ns *NrfService) SendRegisterNFInstance(ctx context.Context, nssfCtx *nssf_
 					logger.MainLog.Infoln("OAuth2 setting receive from NRF:", oauth2)
 				}
 			}
-			nssf_context.GetSelf().OAuth2Required = oauth2
+			nssf_context.GetSelf().OAuth2Required.Store(oauth2)
 			if oauth2 && nssf_context.GetSelf().NrfCertPem == "" {
 				logger.CfgLog.Error("OAuth2 enable but no nrfCertPem provided in config.")
 			}
@@ -110,12 +122,16 @@ func (ns *NrfService) SendDeregisterNFInstance(nfInstanceId string) (*models.Pro
 
 	client := ns.nrfNfMgmtClient
 
+	ctx, span := tracing.Start(ctx, "nrf.DeregisterNFInstance")
+	defer span.End()
+
 	req := &NFManagement.DeregisterNFInstanceRequest{
 		NfInstanceID: &nfInstanceId,
 	}
 
 	_, err = client.NFInstanceIDDocumentApi.DeregisterNFInstance(ctx, req)
 	if err != nil {
+		tracing.RecordError(span, err)
 		if apiErr, ok := err.(openapi.GenericOpenAPIError); ok {
 			// API error
 			if deregError, ok2 := apiErr.Model().(NFManagement.DeregisterNFInstanceError); ok2 {
@@ -130,3 +146,89 @@ func (ns *NrfService) SendDeregisterNFInstance(nfInstanceId string) (*models.Pro
 
 	return nil, nil
 }
+
+// Subscribe to NRF for deregistration of AMFs, so that data of AMFs leaving the network can be purged,
+// and keep the subscription in NSSF context, replacing the previous one
+func (ns *NrfService) SendCreateSubscriptionForAmfStatus(nssfCtx *nssf_context.NSSFContext) (
+	subscriptionId string, problemDetails *models.ProblemDetails, err error,
+) {
+	logger.ConsumerLog.Infof("Send Create Subscription for NF status of AMF")
+
+	ctx, pd, err := nssfCtx.GetTokenCtx(models.ServiceName_NNRF_NFM, models.NrfNfManagementNfType_NRF)
+	if err != nil {
+		return "", pd, err
+	}
+
+	// Notifications are authenticated by the token in the notification URI, which is given to NRF only
+	notifyToken := nssf_context.NewNotifyToken()
+	subscriptionData := models.NrfNfManagementSubscriptionData{
+		NfStatusNotificationUri: fmt.Sprintf("%s%s/nf-status-notify/%s",
+			nssf_context.GetIpv4Uri(), factory.NssfCallbackResUriPrefix, notifyToken),
+		SubscrCond: &models.SubscrCond{
+			NfType: string(models.NrfNfManagementNfType_AMF),
+		},
+		ReqNotifEvents: []models.NotificationEventType{
+			models.NotificationEventType_DEREGISTERED,
+		},
+		ReqNfType:       models.NrfNfManagementNfType_NSSF,
+		ReqNfInstanceId: nssfCtx.NfId(),
+	}
+	req := &NFManagement.CreateSubscriptionRequest{
+		NrfNfManagementSubscriptionData: &subscriptionData,
+	}
+
+	ctx, span := tracing.Start(ctx, "nrf.CreateSubscription")
+	defer span.End()
+
+	res, err := ns.nrfNfMgmtClient.SubscriptionsCollectionApi.CreateSubscription(ctx, req)
+	if err != nil {
+		tracing.RecordError(span, err)
+		if apiErr, ok := err.(openapi.GenericOpenAPIError); ok {
+			if subscribeError, ok2 := apiErr.Model().(NFManagement.CreateSubscriptionError); ok2 {
+				return "", &subscribeError.ProblemDetails, err
+			}
+		}
+		return "", nil, err
+	}
+
+	subscriptionId = res.NrfNfManagementSubscriptionData.SubscriptionId
+	if subscriptionId == "" {
+		subscriptionId = res.Location[strings.LastIndex(res.Location, "/")+1:]
+	}
+	// NRF may shorten the validity time requested, or set one if none is requested
+	var validityTime time.Time
+	if res.NrfNfManagementSubscriptionData.ValidityTime != nil {
+		validityTime = *res.NrfNfManagementSubscriptionData.ValidityTime
+	}
+	nssfCtx.NrfAmfStatusSubscription.Set(subscriptionId, notifyToken, validityTime)
+	return subscriptionId, nil, nil
+}
+
+func (ns *NrfService) SendRemoveSubscription(subscriptionId string) (*models.ProblemDetails, error) {
+	logger.ConsumerLog.Infof("Send Remove Subscription [%s]", subscriptionId)
+
+	ctx, pd, err := nssf_context.GetSelf().GetTokenCtx(models.ServiceName_NNRF_NFM, models.NrfNfManagementNfType_NRF)
+	if err != nil {
+		return pd, err
+	}
+
+	req := &NFManagement.RemoveSubscriptionRequest{
+		SubscriptionID: &subscriptionId,
+	}
+
+	ctx, span := tracing.Start(ctx, "nrf.RemoveSubscription")
+	defer span.End()
+
+	_, err = ns.nrfNfMgmtClient.SubscriptionIDDocumentApi.RemoveSubscription(ctx, req)
+	if err != nil {
+		tracing.RecordError(span, err)
+		if apiErr, ok := err.(openapi.GenericOpenAPIError); ok {
+			if removeError, ok2 := apiErr.Model().(NFManagement.RemoveSubscriptionError); ok2 {
+				return &removeError.ProblemDetails, err
+			}
+		}
+		return nil, err
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,159 @@
+/*
+ * NSSF Consumer
+ *
+ * Network Slice Admission Control
+ */
+
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/nsac"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+	sbi_metrics "github.com/free5gc/util/metrics/sbi"
+)
+
+const (
+	nsacfAdmissionTypeUe         = "UE"
+	nsacfAdmissionTypePduSession = "PDU_SESSION"
+
+	nsacfAcResultAccepted = "ACCEPTED"
+)
+
+// Admission control request of an S-NSSAI sent to the NSACF
+type nsacfAcRequestData struct {
+	NfId   string        `json:"nfId"`
+	Type   string        `json:"type"`
+	Snssai models.Snssai `json:"snssai"`
+	Tai    *models.Tai   `json:"tai,omitempty"`
+	// NF service consumer which requested the admission from NSSF, and the UE or PDU session admitted through it
+	ConsumerNfId string `json:"consumerNfId"`
+	HolderId     string `json:"holderId"`
+}
+
+type nsacfAcResponseData struct {
+	AcResult string `json:"acResult"`
+	// Set if the quota is reached in the TA instead of in the PLMN
+	TaLevel bool `json:"taLevel,omitempty"`
+}
+
+type nsacfConfiguration struct {
+	basePath   string
+	httpClient *http.Client
+}
+
+var _ openapi.Configuration = &nsacfConfiguration{}
+
+func (c *nsacfConfiguration) BasePath() string                    { return c.basePath }
+func (c *nsacfConfiguration) Host() string                        { return "" }
+func (c *nsacfConfiguration) UserAgent() string                   { return "NSSF" }
+func (c *nsacfConfiguration) DefaultHeader() map[string]string    { return nil }
+func (c *nsacfConfiguration) HTTPClient() *http.Client            { return c.httpClient }
+func (c *nsacfConfiguration) Metrics() openapi.RequestMetricsHook { return sbi_metrics.SbiMetricHook }
+
+type NsacfService struct {
+	cfg *nsacfConfiguration
+}
+
+var _ nsac.Controller = &NsacfService{}
+
+func newNsacfService(nsacfUri string) *NsacfService {
+	return &NsacfService{
+		cfg: &nsacfConfiguration{
+			basePath: nsacfUri + "/nnsacf-nsac/v1",
+		},
+	}
+}
+
+func (ns *NsacfService) AdmitUe(holder nsac.Holder, snssai models.Snssai, tai *models.Tai) (nsac.Result, error) {
+	result, err := ns.sendAdmissionControl(nsacfAdmissionTypeUe, holder, snssai, tai)
+	if err == nil && !result.Admitted {
+		result.Cause = nsac.CauseMaxNumOfUesReached
+	}
+	return result, err
+}
+
+func (ns *NsacfService) AdmitPduSession(holder nsac.Holder, snssai models.Snssai, tai *models.Tai) (
+	nsac.Result, error,
+) {
+	result, err := ns.sendAdmissionControl(nsacfAdmissionTypePduSession, holder, snssai, tai)
+	if err == nil && !result.Admitted {
+		result.Cause = nsac.CauseMaxNumOfPduSessionsReached
+	}
+	return result, err
+}
+
+func (ns *NsacfService) sendAdmissionControl(admissionType string, holder nsac.Holder, snssai models.Snssai,
+	tai *models.Tai,
+) (nsac.Result, error) {
+	ctx, pd, err := nssf_context.GetSelf().GetTokenCtx(models.ServiceName_NNSACF_NSAC,
+		models.NrfNfManagementNfType_NSACF)
+	if err != nil {
+		return nsac.Result{}, err
+	} else if pd != nil {
+		return nsac.Result{}, fmt.Errorf("get token of NSACF failed: %s", pd.Detail)
+	}
+	if ctx == nil {
+		ctx = context.TODO()
+	}
+
+	body := &nsacfAcRequestData{
+		NfId:   nssf_context.GetSelf().NfId(),
+		Type:   admissionType,
+		Snssai: snssai,
+		Tai:    tai,
+
+		ConsumerNfId: holder.NfId,
+		HolderId:     holder.Id,
+	}
+	headerParams := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	}
+
+	req, err := openapi.PrepareRequest(ctx, ns.cfg, ns.cfg.BasePath()+"/admission-control", http.MethodPost,
+		body, headerParams, url.Values{}, url.Values{}, "", "", nil)
+	if err != nil {
+		return nsac.Result{}, err
+	}
+
+	rsp, err := openapi.CallAPI(ns.cfg, req)
+	if err != nil {
+		return nsac.Result{}, err
+	}
+	defer func() {
+		if rspCloseErr := rsp.Body.Close(); rspCloseErr != nil {
+			logger.ConsumerLog.Errorf("NSACF response body cannot close: %+v", rspCloseErr)
+		}
+	}()
+
+	rspBody, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return nsac.Result{}, err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return nsac.Result{}, fmt.Errorf("NSACF responded with status %d", rsp.StatusCode)
+	}
+
+	var acResponse nsacfAcResponseData
+	if err = openapi.Deserialize(&acResponse, rspBody, "application/json"); err != nil {
+		return nsac.Result{}, err
+	}
+
+	result := nsac.Result{
+		Admitted: acResponse.AcResult == nsacfAcResultAccepted,
+		Scope:    nsac.ScopePlmn,
+	}
+	if acResponse.TaLevel {
+		result.Scope = nsac.ScopeTa
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package consumer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/free5gc/nssf/internal/nsac"
+	"github.com/free5gc/openapi/models"
+)
+
+func TestNsacfServiceAdmission(t *testing.T) {
+	requests := make(chan nsacfAcRequestData, 1)
+	var response nsacfAcResponseData
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/nnsacf-nsac/v1/admission-control" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var request nsacfAcRequestData
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("Encode admission control response failed: %+v", err)
+		}
+		requests <- request
+	}))
+	// NSACF client speaks HTTP/2 without TLS
+	srv.Config.Protocols = &http.Protocols{}
+	srv.Config.Protocols.SetHTTP1(true)
+	srv.Config.Protocols.SetUnencryptedHTTP2(true)
+	srv.Start()
+	defer srv.Close()
+
+	ns := newNsacfService(srv.URL)
+	holder := nsac.Holder{NfId: "469de254-2fe5-4ca0-8381-af3f500af77c", Id: "imsi-208930000000001"}
+	snssai := models.Snssai{Sst: 1, Sd: "010203"}
+	tai := &models.Tai{PlmnId: &models.PlmnId{Mcc: "208", Mnc: "93"}, Tac: "000001"}
+
+	testCases := []struct {
+		name         string
+		pduSession   bool
+		response     nsacfAcResponseData
+		expectType   string
+		expectResult nsac.Result
+	}{
+		{
+			name:         "UE accepted",
+			response:     nsacfAcResponseData{AcResult: nsacfAcResultAccepted},
+			expectType:   nsacfAdmissionTypeUe,
+			expectResult: nsac.Result{Admitted: true},
+		},
+		{
+			name:       "UE rejected in TA",
+			response:   nsacfAcResponseData{AcResult: "REJECTED", TaLevel: true},
+			expectType: nsacfAdmissionTypeUe,
+			expectResult: nsac.Result{
+				Scope: nsac.ScopeTa,
+				Cause: nsac.CauseMaxNumOfUesReached,
+			},
+		},
+		{
+			name:       "PDU session rejected in PLMN",
+			pduSession: true,
+			response:   nsacfAcResponseData{AcResult: "REJECTED"},
+			expectType: nsacfAdmissionTypePduSession,
+			expectResult: nsac.Result{
+				Scope: nsac.ScopePlmn,
+				Cause: nsac.CauseMaxNumOfPduSessionsReached,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response = tc.response
+
+			var result nsac.Result
+			var err error
+			if tc.pduSession {
+				result, err = ns.AdmitPduSession(holder, snssai, tai)
+			} else {
+				result, err = ns.AdmitUe(holder, snssai, tai)
+			}
+			if err != nil {
+				t.Fatalf("Admission control by NSACF failed: %+v", err)
+			}
+			if result != tc.expectResult {
+				t.Errorf("Expected result %+v, got: %+v", tc.expectResult, result)
+			}
+
+			request := <-requests
+			if request.Type != tc.expectType || request.Snssai != snssai {
+				t.Errorf("Expected admission of %s to S-NSSAI %+v, got: %+v", tc.expectType, snssai, request)
+			}
+			if request.ConsumerNfId != holder.NfId || request.HolderId != holder.Id {
+				t.Errorf("Expected admission of holder %+v, got: %+v", holder, request)
+			}
+		})
+	}
+}
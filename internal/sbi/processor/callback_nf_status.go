@@ -0,0 +1,97 @@
+/*
+ * NSSF Callback
+ *
+ * NF Status Notification from NRF
+ */
+
+package processor
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/nsac"
+	"github.com/free5gc/nssf/internal/tracing"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/util/metrics/sbi"
+)
+
+// Handle NF status notification of AMFs subscribed to NRF
+func (p *Processor) NfStatusNotify(
+	c *gin.Context, notifyToken string, notification models.NrfNfManagementNotificationData,
+) {
+	// Only notifications sent by NRF to the notification URI of the current subscription are accepted,
+	// so that AMFs could not be purged by anyone else
+	var subscriptionId string
+	if notification.SubscriptionContext != nil {
+		subscriptionId = notification.SubscriptionContext.SubscriptionId
+	}
+	if !nssf_context.GetSelf().NrfAmfStatusSubscription.Authenticate(notifyToken, subscriptionId) {
+		problemDetails := &models.ProblemDetails{
+			Title:  util.UNSUPPORTED_RESOURCE,
+			Status: http.StatusNotFound,
+			Detail: "Notification is not of any subscription of NSSF",
+		}
+		logger.CallbackLog.Warnf("Reject NF status notification of unknown subscription, nfInstanceUri: %s",
+			notification.NfInstanceUri)
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+
+	// NF instance URI ends with the NF instance ID, i.e. ".../nf-instances/{nfInstanceID}"
+	nfId := notification.NfInstanceUri[strings.LastIndex(notification.NfInstanceUri, "/")+1:]
+	if nfId == "" {
+		problemDetails := &models.ProblemDetails{
+			Title:  util.MANDATORY_IE_MISSING,
+			Status: http.StatusBadRequest,
+			Detail: "NF instance ID is missing in nfInstanceUri",
+			InvalidParams: []models.InvalidParam{
+				{
+					Param: "nfInstanceUri",
+				},
+			},
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+
+	switch notification.Event {
+	case models.NotificationEventType_DEREGISTERED:
+		logger.CallbackLog.Infof("AMF %s is deregistered from NRF", nfId)
+		p.purgeAmf(tracing.Context(c), nfId)
+	default:
+		logger.CallbackLog.Debugf("Ignore NF status event %s of NF %s", notification.Event, nfId)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// Remove NSSAI availability data, subscriptions and network slice admissions of the AMF which has left the network,
+// and notify remaining subscribers of the NSSAI availability no longer provided by the AMF
+func (p *Processor) purgeAmf(ctx context.Context, nfId string) {
+	before := removeAmfConfig(nfId)
+
+	for _, subscriptionId := range nssf_context.GetSelf().Subscriptions.RemoveByOwner(nfId) {
+		logger.CallbackLog.Infof("Remove subscription %s of AMF %s", subscriptionId, nfId)
+	}
+
+	// UEs and PDU sessions admitted on request of the AMF are no longer counted against quotas
+	if releaser, ok := p.admission.(nsac.Releaser); ok {
+		logger.CallbackLog.Infof("Release network slices held through AMF %s", nfId)
+		releaser.ReleaseNf(nfId)
+	}
+
+	if before != nil {
+		logger.CallbackLog.Infof("Remove NSSAI availability data of AMF %s", nfId)
+		p.notifyNssaiAvailabilityChanges(ctx, nfId, before.AmfSetId,
+			util.DiffSupportedNssaiAvailabilityData(before.SupportedNssaiAvailabilityData, nil))
+	}
+}
@@ -0,0 +1,187 @@
+package processor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+func TestNfStatusNotify(t *testing.T) {
+	const (
+		amfId          = "b9a9ce1c-6c3b-4f44-9a3c-2b2d1d1c3a01"
+		otherAmfId     = "b9a9ce1c-6c3b-4f44-9a3c-2b2d1d1c3a02"
+		subscriptionId = "nrf-subscription-1"
+		notifyToken    = "notify-token-1"
+	)
+	nfInstanceUri := "http://127.0.0.10:8000/nnrf-nfm/v1/nf-instances/" + amfId
+
+	testCases := []struct {
+		name string
+		// Whether NSSF is subscribed to NRF when the notification is received
+		subscribed   bool
+		notifyToken  string
+		notification models.NrfNfManagementNotificationData
+		expectStatus int
+		expectPurged bool
+	}{
+		{
+			name:        "Deregistered AMF is purged",
+			subscribed:  true,
+			notifyToken: notifyToken,
+			notification: models.NrfNfManagementNotificationData{
+				Event:         models.NotificationEventType_DEREGISTERED,
+				NfInstanceUri: nfInstanceUri,
+			},
+			expectStatus: http.StatusNoContent,
+			expectPurged: true,
+		},
+		{
+			name:        "Deregistered AMF is purged with subscription ID in notification",
+			subscribed:  true,
+			notifyToken: notifyToken,
+			notification: models.NrfNfManagementNotificationData{
+				Event:               models.NotificationEventType_DEREGISTERED,
+				NfInstanceUri:       nfInstanceUri,
+				SubscriptionContext: &models.SubscriptionContext{SubscriptionId: subscriptionId},
+			},
+			expectStatus: http.StatusNoContent,
+			expectPurged: true,
+		},
+		{
+			name:        "Other events are ignored",
+			subscribed:  true,
+			notifyToken: notifyToken,
+			notification: models.NrfNfManagementNotificationData{
+				Event:         models.NotificationEventType_PROFILE_CHANGED,
+				NfInstanceUri: nfInstanceUri,
+			},
+			expectStatus: http.StatusNoContent,
+		},
+		{
+			name:        "Missing NF instance ID",
+			subscribed:  true,
+			notifyToken: notifyToken,
+			notification: models.NrfNfManagementNotificationData{
+				Event:         models.NotificationEventType_DEREGISTERED,
+				NfInstanceUri: "http://127.0.0.10:8000/nnrf-nfm/v1/nf-instances/",
+			},
+			expectStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Wrong notify token",
+			subscribed:  true,
+			notifyToken: "forged-token",
+			notification: models.NrfNfManagementNotificationData{
+				Event:         models.NotificationEventType_DEREGISTERED,
+				NfInstanceUri: nfInstanceUri,
+			},
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name:        "Empty notify token",
+			subscribed:  true,
+			notifyToken: "",
+			notification: models.NrfNfManagementNotificationData{
+				Event:         models.NotificationEventType_DEREGISTERED,
+				NfInstanceUri: nfInstanceUri,
+			},
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name:        "Subscription ID of another subscription",
+			subscribed:  true,
+			notifyToken: notifyToken,
+			notification: models.NrfNfManagementNotificationData{
+				Event:               models.NotificationEventType_DEREGISTERED,
+				NfInstanceUri:       nfInstanceUri,
+				SubscriptionContext: &models.SubscriptionContext{SubscriptionId: "nrf-subscription-2"},
+			},
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name:        "Not subscribed to NRF",
+			subscribed:  false,
+			notifyToken: "",
+			notification: models.NrfNfManagementNotificationData{
+				Event:         models.NotificationEventType_DEREGISTERED,
+				NfInstanceUri: nfInstanceUri,
+			},
+			expectStatus: http.StatusNotFound,
+		},
+	}
+
+	nssfCtx := nssf_context.GetSelf()
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory.NssfConfig.Configuration.AmfList = []factory.AmfConfig{
+				{
+					NfId: amfId,
+					SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{
+							Tai: &models.Tai{
+								PlmnId: &models.PlmnId{Mcc: "466", Mnc: "92"},
+								Tac:    "33456",
+							},
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}},
+						},
+					},
+				},
+				{
+					NfId: otherAmfId,
+				},
+			}
+			createData := models.NssfEventSubscriptionCreateData{
+				NfNssaiAvailabilityUri: "http://127.0.0.18:8000/callback",
+				TaiList:                []models.Tai{{PlmnId: &models.PlmnId{Mcc: "466", Mnc: "92"}, Tac: "33456"}},
+				Event:                  models.NssfEventType_SNSSAI_STATUS_CHANGE_REPORT,
+			}
+			ownSubscription := nssfCtx.Subscriptions.Add(createData, amfId)
+			otherSubscription := nssfCtx.Subscriptions.Add(createData, otherAmfId)
+			defer nssfCtx.Subscriptions.Remove(ownSubscription.SubscriptionId)
+			defer nssfCtx.Subscriptions.Remove(otherSubscription.SubscriptionId)
+
+			if tc.subscribed {
+				nssfCtx.NrfAmfStatusSubscription.Set(subscriptionId, notifyToken, time.Time{})
+			}
+			defer nssfCtx.NrfAmfStatusSubscription.Clear(subscriptionId)
+
+			p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			p.NfStatusNotify(c, tc.notifyToken, tc.notification)
+
+			if c.Writer.Status() != tc.expectStatus {
+				t.Errorf("Expected status code %d, got: %d", tc.expectStatus, c.Writer.Status())
+			}
+
+			amfPresent := false
+			for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
+				if amfConfig.NfId == amfId {
+					amfPresent = true
+				}
+			}
+			if amfPresent == tc.expectPurged {
+				t.Errorf("Expected NSSAI availability data of AMF to be purged: %v", tc.expectPurged)
+			}
+			if _, ok := nssfCtx.Subscriptions.Get(ownSubscription.SubscriptionId); ok == tc.expectPurged {
+				t.Errorf("Expected subscription of AMF to be purged: %v", tc.expectPurged)
+			}
+			if _, ok := nssfCtx.Subscriptions.Get(otherSubscription.SubscriptionId); !ok {
+				t.Errorf("Expected subscription of another AMF to be kept")
+			}
+			if len(factory.NssfConfig.Configuration.AmfList) == 0 {
+				t.Errorf("Expected NSSAI availability data of another AMF to be kept")
+			}
+		})
+	}
+}
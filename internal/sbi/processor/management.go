@@ -0,0 +1,198 @@
+/*
+ * NSSF Management
+ *
+ * Runtime controls for operators
+ */
+
+package processor
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/util/metrics/sbi"
+)
+
+// Explain mode of network slice selection
+// When enabled, the explanation of every network slice selection is emitted at debug log level
+type ExplainMode struct {
+	Enable bool `json:"enable"`
+}
+
+func (p *Processor) ExplainModeGet(c *gin.Context) {
+	c.JSON(http.StatusOK, ExplainMode{Enable: p.ExplainMode()})
+}
+
+func (p *Processor) ExplainModeUpdate(c *gin.Context, explainMode ExplainMode) {
+	p.SetExplainMode(explainMode.Enable)
+	logger.MainLog.Infof("Explain mode of network slice selection is set to [%v]", explainMode.Enable)
+
+	c.JSON(http.StatusOK, ExplainMode{Enable: p.ExplainMode()})
+}
+
+// Log level globally or of a category
+type LogLevel struct {
+	// Omitted for the global level
+	Category string `json:"category,omitempty"`
+	Level    string `json:"level"`
+	// Whether the category has its own level instead of following the global level
+	Overridden bool `json:"overridden,omitempty"`
+	// Time when the temporary level is reverted, omitted if the level is kept until changed
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+// Change of log level, which is reverted after the duration if given, e.g. "15m"
+type LogLevelUpdate struct {
+	Level    string `json:"level"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// Output of logging
+type LoggingOutput struct {
+	Enable       bool `json:"enable"`
+	ReportCaller bool `json:"reportCaller"`
+}
+
+// Logging control, with the global level and the levels of all categories in effect
+type Logging struct {
+	LoggingOutput
+	Level      LogLevel   `json:"level"`
+	Categories []LogLevel `json:"categories"`
+}
+
+func (p *Processor) LoggingGet(c *gin.Context) {
+	c.JSON(http.StatusOK, p.logging())
+}
+
+func (p *Processor) LoggingUpdate(c *gin.Context, output LoggingOutput) {
+	p.SetLogEnable(output.Enable)
+	p.SetReportCaller(output.ReportCaller)
+
+	c.JSON(http.StatusOK, p.logging())
+}
+
+// Set the global log level if category is empty, or the level of the category
+func (p *Processor) LogLevelUpdate(c *gin.Context, category string, update LogLevelUpdate) {
+	if category != "" {
+		var err error
+		if category, err = logger.LookupCategory(category); err != nil {
+			problemDetails := &models.ProblemDetails{
+				Title:  util.UNSUPPORTED_RESOURCE,
+				Status: http.StatusNotFound,
+				Detail: err.Error(),
+			}
+			c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+			util.GinProblemJson(c, problemDetails)
+			return
+		}
+	}
+
+	lvl, d, err := parseLogLevelUpdate(update)
+	if err != nil {
+		problemDetails := &models.ProblemDetails{
+			Title:  util.INVALID_REQUEST,
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+
+	switch {
+	case d > 0:
+		logger.SetLevel(category, lvl, d)
+		logger.MainLog.Infof("Log level of [%s] is set to [%s] for %s", logCategoryName(category), lvl, d)
+	case category == "":
+		// Kept global level is also set in configuration
+		p.SetLogLevel(update.Level)
+	default:
+		logger.SetLevel(category, lvl, 0)
+		logger.MainLog.Infof("Log level of [%s] is set to [%s]", category, lvl)
+	}
+
+	c.JSON(http.StatusOK, newLogLevel(logger.GetLevel(category)))
+}
+
+// Clear the level of the category, so that the global level is in effect for it
+func (p *Processor) LogLevelReset(c *gin.Context, category string) {
+	category, err := logger.LookupCategory(category)
+	if err != nil {
+		problemDetails := &models.ProblemDetails{
+			Title:  util.UNSUPPORTED_RESOURCE,
+			Status: http.StatusNotFound,
+			Detail: err.Error(),
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+
+	logger.ResetLevel(category)
+	logger.MainLog.Infof("Log level of [%s] is reset to the global level", category)
+
+	c.JSON(http.StatusOK, newLogLevel(logger.GetLevel(category)))
+}
+
+func (p *Processor) logging() Logging {
+	logging := Logging{
+		LoggingOutput: LoggingOutput{
+			Enable:       p.Config().GetLogEnable(),
+			ReportCaller: p.Config().GetLogReportCaller(),
+		},
+		Categories: []LogLevel{},
+	}
+	for _, info := range logger.GetLevels() {
+		if info.Category == "" {
+			logging.Level = newLogLevel(info)
+		} else {
+			logging.Categories = append(logging.Categories, newLogLevel(info))
+		}
+	}
+	return logging
+}
+
+func parseLogLevelUpdate(update LogLevelUpdate) (logrus.Level, time.Duration, error) {
+	lvl, err := logrus.ParseLevel(update.Level)
+	if err != nil {
+		return 0, 0, err
+	}
+	if update.Duration == "" {
+		return lvl, 0, nil
+	}
+	d, err := time.ParseDuration(update.Duration)
+	if err != nil {
+		return 0, 0, err
+	}
+	if d <= 0 {
+		return 0, 0, fmt.Errorf("duration %s should be positive", update.Duration)
+	}
+	return lvl, d, nil
+}
+
+func newLogLevel(info logger.LevelInfo) LogLevel {
+	logLevel := LogLevel{
+		Category:   info.Category,
+		Level:      info.Level.String(),
+		Overridden: info.Overridden,
+	}
+	if !info.RevertAt.IsZero() {
+		revertAt := info.RevertAt
+		logLevel.RevertAt = &revertAt
+	}
+	return logLevel
+}
+
+func logCategoryName(category string) string {
+	if category == "" {
+		return "global"
+	}
+	return category
+}
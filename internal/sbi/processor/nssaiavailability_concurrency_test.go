@@ -0,0 +1,214 @@
+package processor_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+
+	"github.com/free5gc/nssf/internal/plugin"
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/internal/testutil"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+// Stress tests of NSSAI availability handlers, which are meant to be run with the race detector
+
+var stressPlmnId = models.PlmnId{Mcc: "208", Mnc: "93"}
+
+var stressSnssai = models.ExtSnssai{Sst: 1, Sd: "010203"}
+
+func stressNssaiAvailabilityInfo(taiList ...models.Tai) models.NssaiAvailabilityInfo {
+	var info models.NssaiAvailabilityInfo
+	for i := range taiList {
+		info.SupportedNssaiAvailabilityData = append(info.SupportedNssaiAvailabilityData,
+			models.SupportedNssaiAvailabilityData{
+				Tai:                 &taiList[i],
+				SupportedSnssaiList: []models.ExtSnssai{stressSnssai},
+			})
+	}
+	return info
+}
+
+// Check that every AMF is stored once and return NSSAI availability information indexed by AMF ID
+func checkAmfListConsistency(t *testing.T) map[string]factory.AmfConfig {
+	t.Helper()
+
+	factory.NssfConfig.RLock()
+	defer factory.NssfConfig.RUnlock()
+
+	amfConfigs := make(map[string]factory.AmfConfig)
+	for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
+		if _, exist := amfConfigs[amfConfig.NfId]; exist {
+			t.Errorf("AMF %s is stored more than once", amfConfig.NfId)
+		}
+		amfConfigs[amfConfig.NfId] = amfConfig
+	}
+	return amfConfigs
+}
+
+func TestNfInstanceConcurrentCreate(t *testing.T) {
+	mockNssfApp := app.NewMockNssfApp(gomock.NewController(t))
+	processor := processor.NewProcessor(mockNssfApp)
+	taiList := testutil.SetupTaListConfig(stressPlmnId, []models.ExtSnssai{stressSnssai}, 4)
+
+	// Parallel PUTs of the same AMF never create duplicated entries, and no update is lost
+	const writers = 64
+	var (
+		wg      sync.WaitGroup
+		created atomic.Int32
+	)
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			processor.NssaiAvailabilityNfInstanceUpdate(c,
+				stressNssaiAvailabilityInfo(taiList[i%len(taiList)]), "nf1", "")
+			switch httpRecorder.Code {
+			case http.StatusCreated:
+				created.Add(1)
+			case http.StatusOK:
+			default:
+				t.Errorf("Expected status code %d or %d, got: %d", http.StatusCreated, http.StatusOK, httpRecorder.Code)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Only the first PUT creates the information
+	if n := created.Load(); n != 1 {
+		t.Errorf("Expected NSSAI availability information to be created once, got: %d", n)
+	}
+
+	amfConfigs := checkAmfListConsistency(t)
+	if len(amfConfigs) != 1 {
+		t.Fatalf("Expected 1 AMF, got: %d", len(amfConfigs))
+	}
+	if version := amfConfigs["nf1"].Version; version != writers {
+		t.Errorf("Expected version to be %d, got: %d", writers, version)
+	}
+}
+
+func TestNfInstanceConcurrentPatch(t *testing.T) {
+	mockNssfApp := app.NewMockNssfApp(gomock.NewController(t))
+	processor := processor.NewProcessor(mockNssfApp)
+	taiList := testutil.SetupTaListConfig(stressPlmnId, []models.ExtSnssai{stressSnssai}, 64)
+	factory.NssfConfig.Configuration.AmfList = []factory.AmfConfig{
+		{
+			NfId:    "nf1",
+			Version: 1,
+		},
+	}
+
+	// Each writer appends a distinct TAI, and all of them are kept
+	var wg sync.WaitGroup
+	for i := range taiList {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			patchDocument := plugin.PatchDocument{
+				{
+					Op:    models.PatchOperation_ADD,
+					Path:  "/-",
+					Value: stressNssaiAvailabilityInfo(taiList[i]).SupportedNssaiAvailabilityData[0],
+				},
+			}
+			processor.NssaiAvailabilityNfInstancePatch(c, patchDocument, "nf1", "")
+			if httpRecorder.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	amfConfig := checkAmfListConsistency(t)["nf1"]
+	if n := len(amfConfig.SupportedNssaiAvailabilityData); n != len(taiList) {
+		t.Errorf("Expected %d TAIs, got: %d", len(taiList), n)
+	}
+	if version := amfConfig.Version; version != uint64(len(taiList))+1 {
+		t.Errorf("Expected version to be %d, got: %d", len(taiList)+1, version)
+	}
+}
+
+func TestNfInstanceConcurrentMixedOperations(t *testing.T) {
+	mockNssfApp := app.NewMockNssfApp(gomock.NewController(t))
+	p := processor.NewProcessor(mockNssfApp)
+	taiList := testutil.SetupTaListConfig(stressPlmnId, []models.ExtSnssai{stressSnssai}, 8)
+
+	// Writers and readers of a few AMFs interleave, including NSSelection lookups from the snapshot
+	const (
+		workers    = 32
+		iterations = 50
+		amfs       = 4
+	)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				nfId := fmt.Sprintf("nf%d", (w+i)%amfs)
+				tai := taiList[(w*iterations+i)%len(taiList)]
+				httpRecorder := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(httpRecorder)
+
+				var expected []int
+				switch (w + i) % 6 {
+				case 0:
+					p.NssaiAvailabilityNfInstanceUpdate(c, stressNssaiAvailabilityInfo(tai), nfId, "")
+					expected = []int{http.StatusOK, http.StatusCreated}
+				case 1:
+					patchDocument := plugin.PatchDocument{
+						{
+							Op:    models.PatchOperation_ADD,
+							Path:  "/-",
+							Value: stressNssaiAvailabilityInfo(tai).SupportedNssaiAvailabilityData[0],
+						},
+					}
+					p.NssaiAvailabilityNfInstancePatch(c, patchDocument, nfId, "")
+					expected = []int{http.StatusOK, http.StatusNotFound}
+				case 2:
+					p.NssaiAvailabilityNfInstanceDelete(c, nfId)
+					expected = []int{http.StatusNoContent, http.StatusNotFound}
+				case 3:
+					p.NssaiAvailabilityNfInstanceGet(c, nfId, processor.NssaiAvailabilityGetQuery{})
+					expected = []int{http.StatusOK, http.StatusNotFound}
+				case 4:
+					p.NssaiAvailabilityList(c, processor.NssaiAvailabilityListQuery{})
+					expected = []int{http.StatusOK}
+				default:
+					util.CheckSupportedSnssaiInAmfTa(models.Snssai{Sst: stressSnssai.Sst, Sd: stressSnssai.Sd},
+						nfId, tai)
+					continue
+				}
+
+				if !util.Contain(c.Writer.Status(), expected) {
+					t.Errorf("Expected status code in %v, got: %d", expected, c.Writer.Status())
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// The snapshot for NSSelection reflects the final NSSAI availability information
+	for nfId, amfConfig := range checkAmfListConsistency(t) {
+		for _, s := range amfConfig.SupportedNssaiAvailabilityData {
+			if !util.CheckSupportedSnssaiInAmfTa(models.Snssai{Sst: stressSnssai.Sst, Sd: stressSnssai.Sd},
+				nfId, *s.Tai) {
+				t.Errorf("Expected S-NSSAI to be supported by AMF %s in TAI %+v", nfId, *s.Tai)
+			}
+		}
+	}
+}
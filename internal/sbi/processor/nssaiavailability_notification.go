@@ -0,0 +1,114 @@
+/*
+ * NSSF NSSAI Availability
+ *
+ * NSSF NSSAI Availability Service
+ */
+
+package processor
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/tracing"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/openapi/models"
+)
+
+// Get authorized NSSAI availability data of the given TAIs in the scope of the subscription
+// A subscription targeting an AMF set or region is scoped by NSSAI availability reported by AMFs in the target,
+// otherwise by NSSAI availability reported by all AMFs serving the TAs
+func authorizeSubscriptionData(
+	data *models.NssfEventSubscriptionCreateData, taiList []models.Tai,
+) []models.AuthorizedNssaiAvailabilityData {
+	if data.AmfSetId != "" {
+		return util.AuthorizeOfAmfSetFromConfig(data.AmfSetId, taiList)
+	}
+	return util.AuthorizeOfTaListFromAmfs(taiList)
+}
+
+// Notify subscribers of the changes of NSSAI availability data reported by the AMF
+// Subscriptions targeting the AMF set or region of the AMF, and subscriptions targeting the changed TAs
+// without AMF set are notified
+// Notifications are traced as children of the span in ctx, and sent even after the request of ctx is done
+func (p *Processor) notifyNssaiAvailabilityChanges(
+	ctx context.Context, nfId string, amfSetId string, changes []util.NssaiAvailabilityChange,
+) {
+	if p.notifier == nil || len(changes) == 0 {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	for _, subscription := range nssf_context.GetSelf().Subscriptions.List() {
+		data := subscription.SubscriptionData
+		if data.Expiry != nil && !data.Expiry.IsZero() && data.Expiry.Before(time.Now()) {
+			continue
+		}
+		if data.AmfSetId != "" &&
+			!util.MatchAmfSetId(data.AmfSetId, amfSetId) && !util.CheckAmfInAmfSet(nfId, data.AmfSetId) {
+			continue
+		}
+
+		var taiList []models.Tai
+		for _, change := range changes {
+			if len(data.TaiList) == 0 || util.Contain(change.Tai, data.TaiList) {
+				taiList = append(taiList, change.Tai)
+			}
+		}
+		if len(taiList) == 0 {
+			continue
+		}
+
+		authorizedNssaiAvailabilityData := authorizeSubscriptionData(data, taiList)
+		// TAI which is no longer served by the target is notified with empty S-NSSAI list
+		for i := range taiList {
+			hitTai := false
+			for _, a := range authorizedNssaiAvailabilityData {
+				if reflect.DeepEqual(*a.Tai, taiList[i]) {
+					hitTai = true
+					break
+				}
+			}
+			if !hitTai {
+				authorizedNssaiAvailabilityData = append(authorizedNssaiAvailabilityData,
+					models.AuthorizedNssaiAvailabilityData{
+						Tai:                 &taiList[i],
+						SupportedSnssaiList: []models.ExtSnssai{},
+					})
+			}
+		}
+
+		negotiatedFeatures, err := util.NegotiateSupportedFeatures(models.ServiceName_NNSSF_NSSAIAVAILABILITY,
+			data.SupportedFeatures)
+		if err != nil {
+			logger.NssaiavailLog.Warnf("Invalid supported features of subscription %s: %+v",
+				subscription.SubscriptionId, err)
+		}
+		notification := models.NssfEventNotification{
+			SubscriptionId: subscription.SubscriptionId,
+			AuthorizedNssaiAvailabilityData: applyNssaiavailSupportedFeatures(negotiatedFeatures,
+				authorizedNssaiAvailabilityData),
+		}
+
+		p.notifications.Add(1)
+		go func(uri string) {
+			defer p.notifications.Done()
+			notifyCtx, span := tracing.Start(ctx, "nssaiavailability.notify")
+			defer span.End()
+			// Callback URI is checked again in case the notification policy is changed after subscription
+			err := util.ValidateCallbackUri(uri)
+			if err == nil {
+				err = p.notifier.SendNssfEventNotification(notifyCtx, uri, notification)
+			}
+			if err != nil {
+				tracing.RecordError(span, err)
+				logger.NssaiavailLog.Warnf("Send notification of subscription %s to %s failed: %+v",
+					notification.SubscriptionId, uri, err)
+			}
+			nssf_context.GetSelf().Subscriptions.RecordDelivery(notification.SubscriptionId, err)
+		}(data.NfNssaiAvailabilityUri)
+	}
+}
@@ -0,0 +1,224 @@
+package processor_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+// Notifier recording notifications sent
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []models.NssfEventNotification
+}
+
+func (n *recordingNotifier) SendNssfEventNotification(
+	ctx context.Context, uri string, notification models.NssfEventNotification,
+) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+// Delete NSSAI availability information of the AMF, and wait for the notifications to be sent
+func deleteAndWaitNotifications(t *testing.T, p *processor.Processor, nfId string) {
+	t.Helper()
+
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	c.Request = httptest.NewRequest(http.MethodDelete, "/", nil)
+	p.NssaiAvailabilityNfInstanceDelete(c, nfId)
+	if c.Writer.Status() != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got: %d", http.StatusNoContent, c.Writer.Status())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.WaitNotifications(ctx); err != nil {
+		t.Fatalf("Error waiting for notifications: %v", err)
+	}
+}
+
+func TestNotifyNssaiAvailabilityAmfSet(t *testing.T) {
+	const (
+		amfSet1   = "set1.region48.amfset.5gc.mnc93.mcc208"
+		amfSet2   = "set2.region48.amfset.5gc.mnc93.mcc208"
+		amfRegion = "region48.amfset.5gc.mnc93.mcc208"
+	)
+	plmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	tai := models.Tai{PlmnId: &plmnId, Tac: "33456"}
+	otherTai := models.Tai{PlmnId: &plmnId, Tac: "33457"}
+
+	defer func(configuration *factory.Configuration) {
+		factory.NssfConfig.Configuration = configuration
+	}(factory.NssfConfig.Configuration)
+
+	subscriptions := map[string]models.NssfEventSubscriptionCreateData{
+		"AMF set 1":           {AmfSetId: amfSet1},
+		"AMF set 2":           {AmfSetId: amfSet2},
+		"AMF region":          {AmfSetId: amfRegion},
+		"AMF set 1 other TAI": {AmfSetId: amfSet1, TaiList: []models.Tai{otherTai}},
+		"TAI list":            {TaiList: []models.Tai{tai}},
+		"Other TAI list":      {TaiList: []models.Tai{otherTai}},
+		"AMF set 1 expired": {
+			AmfSetId: amfSet1,
+			Expiry:   func() *time.Time { expiry := time.Now().Add(-time.Hour); return &expiry }(),
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		nfId                string
+		expectSubscriptions []string
+	}{
+		{
+			name:                "AMF with AMF set ID",
+			nfId:                "amf1",
+			expectSubscriptions: []string{"AMF region", "AMF set 1", "TAI list"},
+		},
+		{
+			name:                "AMF in AMF set configuration",
+			nfId:                "amf2",
+			expectSubscriptions: []string{"AMF region", "AMF set 1", "TAI list"},
+		},
+		{
+			name:                "AMF in other AMF set",
+			nfId:                "amf3",
+			expectSubscriptions: []string{"AMF region", "AMF set 2", "TAI list"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			supportedNssaiAvailabilityData := []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}}},
+			}
+			factory.NssfConfig.Configuration = &factory.Configuration{
+				TaList: []factory.TaConfig{
+					{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}}},
+				},
+				AmfSetList: []factory.AmfSetConfig{
+					{AmfSetId: amfSet1, AmfList: []string{"amf2"}},
+				},
+				AmfList: []factory.AmfConfig{
+					{NfId: "amf1", AmfSetId: amfSet1, SupportedNssaiAvailabilityData: supportedNssaiAvailabilityData},
+					{NfId: "amf2", SupportedNssaiAvailabilityData: supportedNssaiAvailabilityData},
+					{NfId: "amf3", AmfSetId: amfSet2, SupportedNssaiAvailabilityData: supportedNssaiAvailabilityData},
+				},
+			}
+
+			names := make(map[string]string, len(subscriptions))
+			for name, createData := range subscriptions {
+				createData.NfNssaiAvailabilityUri = "https://amf.example.com/callback"
+				createData.Event = models.NssfEventType_SNSSAI_STATUS_CHANGE_REPORT
+				subscription := nssf_context.GetSelf().Subscriptions.Add(createData, "")
+				names[subscription.SubscriptionId] = name
+			}
+			t.Cleanup(func() {
+				for subscriptionId := range names {
+					nssf_context.GetSelf().Subscriptions.Remove(subscriptionId)
+				}
+			})
+
+			notifier := &recordingNotifier{}
+			p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+			p.SetNotifier(notifier)
+			deleteAndWaitNotifications(t, p, tc.nfId)
+
+			// Only subscriptions to the AMF set or region of the AMF, and to the changed TAI are notified
+			var notified []string
+			for _, notification := range notifier.notifications {
+				notified = append(notified, names[notification.SubscriptionId])
+			}
+			sort.Strings(notified)
+			if len(notified) != len(tc.expectSubscriptions) {
+				t.Fatalf("Expected subscriptions %v to be notified, got: %v", tc.expectSubscriptions, notified)
+			}
+			for i := range notified {
+				if notified[i] != tc.expectSubscriptions[i] {
+					t.Errorf("Expected subscriptions %v to be notified, got: %v", tc.expectSubscriptions, notified)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestNotifyNssaiAvailabilityTaiList(t *testing.T) {
+	plmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	tai := models.Tai{PlmnId: &plmnId, Tac: "33456"}
+
+	defer func(configuration *factory.Configuration) {
+		factory.NssfConfig.Configuration = configuration
+	}(factory.NssfConfig.Configuration)
+	factory.NssfConfig.Configuration = &factory.Configuration{
+		TaList: []factory.TaConfig{
+			{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}, {Sst: 2}}},
+		},
+		AmfList: []factory.AmfConfig{
+			{
+				NfId: "amf1",
+				SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+					{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}}},
+				},
+			},
+			{
+				NfId: "amf2",
+				SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+					{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 2}}},
+				},
+			},
+		},
+	}
+
+	subscription := nssf_context.GetSelf().Subscriptions.Add(models.NssfEventSubscriptionCreateData{
+		NfNssaiAvailabilityUri: "https://amf.example.com/callback",
+		TaiList:                []models.Tai{tai},
+		Event:                  models.NssfEventType_SNSSAI_STATUS_CHANGE_REPORT,
+	}, "")
+	t.Cleanup(func() {
+		nssf_context.GetSelf().Subscriptions.Remove(subscription.SubscriptionId)
+	})
+
+	notifier := &recordingNotifier{}
+	p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+	p.SetNotifier(notifier)
+
+	// S-NSSAIs of the TA follow NSSAI availability reported by the AMFs still serving it
+	expected := [][]models.ExtSnssai{{{Sst: 1}}, {}}
+	for i, nfId := range []string{"amf2", "amf1"} {
+		deleteAndWaitNotifications(t, p, nfId)
+
+		if len(notifier.notifications) != i+1 {
+			t.Fatalf("Expected %d notifications, got: %+v", i+1, notifier.notifications)
+		}
+		notification := notifier.notifications[i]
+		if notification.SubscriptionId != subscription.SubscriptionId {
+			t.Errorf("Expected subscription '%s' to be notified, got: '%s'",
+				subscription.SubscriptionId, notification.SubscriptionId)
+		}
+		if len(notification.AuthorizedNssaiAvailabilityData) != 1 {
+			t.Fatalf("Expected 1 authorized NSSAI availability data, got: %+v",
+				notification.AuthorizedNssaiAvailabilityData)
+		}
+		data := notification.AuthorizedNssaiAvailabilityData[0]
+		if !reflect.DeepEqual(*data.Tai, tai) || !reflect.DeepEqual(data.SupportedSnssaiList, expected[i]) {
+			t.Errorf("Expected S-NSSAIs %+v of TAI %+v, got: %+v", expected[i], tai, data)
+		}
+	}
+}
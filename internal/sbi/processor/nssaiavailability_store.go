@@ -7,23 +7,155 @@
 package processor
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 
-	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/gin-gonic/gin"
 
+	nssf_context "github.com/free5gc/nssf/internal/context"
 	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/metrics/business"
 	"github.com/free5gc/nssf/internal/plugin"
+	"github.com/free5gc/nssf/internal/tracing"
 	"github.com/free5gc/nssf/internal/util"
 	"github.com/free5gc/nssf/pkg/factory"
 	"github.com/free5gc/openapi/models"
 	"github.com/free5gc/util/metrics/sbi"
 )
 
+// Entity tag of NSSAI availability information of the AMF
+func amfEntityTag(amfConfig *factory.AmfConfig) string {
+	return fmt.Sprintf("\"%d\"", amfConfig.Version)
+}
+
+// Evaluate If-Match header against NSSAI availability information of the AMF, which is nil if absent
+// The caller must hold the lock of NSSF configuration
+func checkIfMatch(ifMatch string, amfConfig *factory.AmfConfig) *models.ProblemDetails {
+	if ifMatch == "" {
+		return nil
+	}
+
+	if amfConfig != nil {
+		for _, tag := range strings.Split(ifMatch, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || tag == amfEntityTag(amfConfig) {
+				return nil
+			}
+		}
+	}
+
+	return &models.ProblemDetails{
+		Title:  util.PRECONDITION_FAILED,
+		Status: http.StatusPreconditionFailed,
+		Detail: fmt.Sprintf("NSSAI availability information does not match If-Match '%s'", ifMatch),
+	}
+}
+
+// Find NSSAI availability information of the AMF
+// The caller must hold the lock of NSSF configuration
+func findAmfConfig(nfId string) *factory.AmfConfig {
+	for i := range factory.NssfConfig.Configuration.AmfList {
+		if factory.NssfConfig.Configuration.AmfList[i].NfId == nfId {
+			return &factory.NssfConfig.Configuration.AmfList[i]
+		}
+	}
+	return nil
+}
+
+// Transaction on NSSAI availability information of an AMF
+// It is given a copy of the current information, or nil if absent, and returns the information to be stored,
+// or nil to remove it. Nothing is changed if problem details are returned
+// Slices in the information are shared with the store and must be replaced rather than modified in place
+type amfConfigTransaction func(current *factory.AmfConfig) (*factory.AmfConfig, *models.ProblemDetails)
+
+// Run the transaction on NSSAI availability information of the AMF under the write lock of NSSF configuration
+// This is the only path to change NSSAI availability information, so that lookup, check and store are atomic
+// Copies of the information before and after the transaction are returned, which are nil if absent
+func updateAmfConfig(nfId string, tx amfConfigTransaction) (
+	before *factory.AmfConfig, after *factory.AmfConfig, problemDetails *models.ProblemDetails,
+) {
+	factory.NssfConfig.Lock()
+	defer factory.NssfConfig.Unlock()
+
+	amfList := factory.NssfConfig.Configuration.AmfList
+	index := -1
+	for i := range amfList {
+		if amfList[i].NfId == nfId {
+			index = i
+			before = new(factory.AmfConfig)
+			*before = amfList[i]
+			break
+		}
+	}
+
+	var current *factory.AmfConfig
+	if before != nil {
+		current = new(factory.AmfConfig)
+		*current = *before
+	}
+	updated, problemDetails := tx(current)
+	if problemDetails != nil {
+		return before, nil, problemDetails
+	}
+	if updated == nil && before == nil {
+		return nil, nil, nil
+	}
+
+	// AMF list is replaced rather than modified in place, since it may still be referred by readers
+	// which have copied the slice under the lock
+	var updatedAmfList []factory.AmfConfig
+	switch {
+	case updated == nil:
+		updatedAmfList = make([]factory.AmfConfig, 0, len(amfList)-1)
+		updatedAmfList = append(updatedAmfList, amfList[:index]...)
+		updatedAmfList = append(updatedAmfList, amfList[index+1:]...)
+	case before == nil:
+		after = new(factory.AmfConfig)
+		*after = *updated
+		after.NfId = nfId
+		after.Version = 1
+		updatedAmfList = make([]factory.AmfConfig, 0, len(amfList)+1)
+		updatedAmfList = append(updatedAmfList, amfList...)
+		updatedAmfList = append(updatedAmfList, *after)
+	default:
+		after = new(factory.AmfConfig)
+		*after = *updated
+		after.NfId = nfId
+		after.Version = before.Version + 1
+		updatedAmfList = append([]factory.AmfConfig{}, amfList...)
+		updatedAmfList[index] = *after
+	}
+
+	factory.NssfConfig.Configuration.AmfList = updatedAmfList
+	factory.NssfConfig.RefreshSnapshotLocked()
+	business.SetNssaiAvailabilityAmfGauge(len(updatedAmfList))
+	return before, after, nil
+}
+
+// Remove NSSAI availability information of the AMF, and return the removed information, which is nil if absent
+func removeAmfConfig(nfId string) *factory.AmfConfig {
+	before, _, _ := updateAmfConfig(nfId, func(*factory.AmfConfig) (*factory.AmfConfig, *models.ProblemDetails) {
+		return nil, nil
+	})
+	return before
+}
+
+// Get authorized NSSAI availability data of the TAI from NSSAI availability information of the AMF
+func authorizeOfAmfTa(amfConfig *factory.AmfConfig, tai models.Tai) (models.AuthorizedNssaiAvailabilityData, bool) {
+	for _, s := range amfConfig.SupportedNssaiAvailabilityData {
+		if reflect.DeepEqual(*s.Tai, tai) {
+			return models.AuthorizedNssaiAvailabilityData{
+				Tai:                  &tai,
+				SupportedSnssaiList:  s.SupportedSnssaiList,
+				RestrictedSnssaiList: util.GetRestrictedSnssaiListFromConfig(tai),
+			}, true
+		}
+	}
+	return models.AuthorizedNssaiAvailabilityData{}, false
+}
+
 func validateSupportedNssaiAvailabilityDataList(
 	c *gin.Context, supportedNssaiAvailabilityData []models.SupportedNssaiAvailabilityData,
 ) bool {
@@ -38,84 +170,108 @@ func validateSupportedNssaiAvailabilityDataList(
 			util.GinProblemJson(c, problemDetails)
 			return false
 		}
-
-		if !util.CheckSupportedNssaiInPlmn(s.SupportedSnssaiList, *s.Tai.PlmnId) {
-			problemDetails := &models.ProblemDetails{
-				Title:  util.UNSUPPORTED_RESOURCE,
-				Status: http.StatusForbidden,
-				Detail: "S-NSSAI in Requested NSSAI is not supported in PLMN",
-				Cause:  "SNSSAI_NOT_SUPPORTED",
-			}
-			c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Cause)
-			util.GinProblemJson(c, problemDetails)
-			return false
-		}
 	}
 
 	return true
 }
 
-func (p *Processor) NssaiAvailabilityNfInstanceDelete(c *gin.Context, nfId string) {
-	var problemDetails *models.ProblemDetails
-	for i, amfConfig := range factory.NssfConfig.Configuration.AmfList {
-		if amfConfig.NfId == nfId {
-			factory.NssfConfig.Configuration.AmfList = append(
-				factory.NssfConfig.Configuration.AmfList[:i],
-				factory.NssfConfig.Configuration.AmfList[i+1:]...)
-
-			c.Status(http.StatusNoContent)
-			return
+// Views of NSSAI availability information of an AMF held by NSSF
+const (
+	// NssaiAvailabilityInfo stored by PUT or PATCH, which is the default
+	NssaiAvailabilityViewStored = "stored"
+	// AuthorizedNssaiAvailabilityInfo as returned to PUT
+	NssaiAvailabilityViewAuthorized = "authorized"
+)
+
+// Query parameters to get NSSAI availability information of an AMF
+type NssaiAvailabilityGetQuery struct {
+	View string `form:"view" binding:"omitempty,oneof=stored authorized"`
+}
+
+// Query parameters to list NSSAI availability information
+type NssaiAvailabilityListQuery struct {
+	Tai      *models.Tai    `form:"tai" binding:"omitempty"`
+	Snssai   *models.Snssai `form:"snssai" binding:"omitempty"`
+	AmfSetId string         `form:"amf-set-id"`
+	View     string         `form:"view" binding:"omitempty,oneof=stored authorized"`
+}
+
+// Build NSSAI availability information of the AMF as stored by PUT or PATCH
+func buildNssaiAvailabilityInfo(amfConfig factory.AmfConfig) models.NssaiAvailabilityInfo {
+	info := models.NssaiAvailabilityInfo{
+		SupportedNssaiAvailabilityData: amfConfig.SupportedNssaiAvailabilityData,
+		SupportedFeatures:              amfConfig.SupportedFeatures,
+		AmfSetId:                       amfConfig.AmfSetId,
+	}
+	if info.SupportedNssaiAvailabilityData == nil {
+		info.SupportedNssaiAvailabilityData = []models.SupportedNssaiAvailabilityData{}
+	}
+	return info
+}
+
+// Build the authorized NSSAI availability information of every TA of the AMF, which is the one returned to PUT
+// The caller must not hold the lock of NSSF configuration
+func buildAuthorizedNssaiAvailabilityInfo(amfConfig factory.AmfConfig) models.AuthorizedNssaiAvailabilityInfo {
+	info := models.AuthorizedNssaiAvailabilityInfo{
+		AuthorizedNssaiAvailabilityData: []models.AuthorizedNssaiAvailabilityData{},
+	}
+	for _, s := range amfConfig.SupportedNssaiAvailabilityData {
+		if authorizedNssaiAvailabilityData, ok := authorizeOfAmfTa(&amfConfig, *s.Tai); ok {
+			info.AuthorizedNssaiAvailabilityData = append(info.AuthorizedNssaiAvailabilityData,
+				authorizedNssaiAvailabilityData)
 		}
 	}
 
-	problemDetails = &models.ProblemDetails{
-		Title:  util.UNSUPPORTED_RESOURCE,
-		Status: http.StatusNotFound,
-		Detail: fmt.Sprintf("AMF ID '%s' does not exist", nfId),
+	// Features negotiated when the NSSAI availability information was created
+	negotiatedFeatures, err := util.NegotiateSupportedFeatures(models.ServiceName_NNSSF_NSSAIAVAILABILITY,
+		amfConfig.SupportedFeatures)
+	if err != nil {
+		logger.NssaiavailLog.Warnf("Invalid supported features of AMF %s: %+v", amfConfig.NfId, err)
 	}
-	c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
-	util.GinProblemJson(c, problemDetails)
-}
+	info.AuthorizedNssaiAvailabilityData = applyNssaiavailSupportedFeatures(negotiatedFeatures,
+		info.AuthorizedNssaiAvailabilityData)
+	info.SupportedFeatures = util.FormatSupportedFeatures(negotiatedFeatures)
 
-func (p *Processor) NssaiAvailabilityNfInstancePatch(
-	c *gin.Context,
-	nssaiAvailabilityUpdateInfo plugin.PatchDocument, nfId string,
-) {
-	var (
-		response = &models.AuthorizedNssaiAvailabilityInfo{}
-		amfIdx   int
-		original []byte
-		hitAmf   bool
-	)
-	factory.NssfConfig.RLock()
-	for amfIdx, amfConfig := range factory.NssfConfig.Configuration.AmfList {
-		if amfConfig.NfId == nfId {
-			// Since json-patch package does not have idea of optional field of datatype,
-			// provide with null or empty value instead of omitting the field
-			temp := factory.NssfConfig.Configuration.AmfList[amfIdx].SupportedNssaiAvailabilityData
-			const dummyString string = "DUMMY"
-			for i := range temp {
-				for j := range temp[i].SupportedSnssaiList {
-					if temp[i].SupportedSnssaiList[j].Sd == "" {
-						temp[i].SupportedSnssaiList[j].Sd = dummyString
-					}
-				}
-			}
-			var err error
-			original, err = json.Marshal(temp)
-			if err != nil {
-				logger.NssaiavailLog.Errorf("Marshal error in NSSAIAvailabilityPatchProcedure: %+v", err)
-			}
-			original = bytes.ReplaceAll(original, []byte(dummyString), []byte(""))
+	return info
+}
 
-			// original, _ = json.Marshal(factory.NssfConfig.Configuration.AmfList[amfIdx].SupportedNssaiAvailabilityData)
+// Build the view of NSSAI availability information of the AMF
+func buildNssaiAvailabilityView(amfConfig factory.AmfConfig, view string) any {
+	if view == NssaiAvailabilityViewAuthorized {
+		return buildAuthorizedNssaiAvailabilityInfo(amfConfig)
+	}
+	return buildNssaiAvailabilityInfo(amfConfig)
+}
 
-			hitAmf = true
-			break
+// Keep NSSAI availability data matching the TAI and S-NSSAI only, nil means no filtering
+func filterSupportedNssaiAvailabilityData(
+	list []models.SupportedNssaiAvailabilityData, tai *models.Tai, snssai *models.Snssai,
+) []models.SupportedNssaiAvailabilityData {
+	var filtered []models.SupportedNssaiAvailabilityData
+	for _, s := range list {
+		if tai != nil && !reflect.DeepEqual(*s.Tai, *tai) {
+			continue
 		}
+		if snssai != nil && !util.CheckSnssaiInNssai(*snssai, s.SupportedSnssaiList) {
+			continue
+		}
+		filtered = append(filtered, s)
+	}
+	return filtered
+}
+
+// NSSAIAvailability GET method
+func (p *Processor) NssaiAvailabilityNfInstanceGet(c *gin.Context, nfId string, query NssaiAvailabilityGetQuery) {
+	var amfConfig factory.AmfConfig
+
+	factory.NssfConfig.RLock()
+	found := findAmfConfig(nfId)
+	if found != nil {
+		amfConfig = *found
 	}
 	factory.NssfConfig.RUnlock()
-	if !hitAmf {
+
+	if found == nil {
 		problemDetails := &models.ProblemDetails{
 			Title:  util.UNSUPPORTED_RESOURCE,
 			Status: http.StatusNotFound,
@@ -126,136 +282,291 @@ func (p *Processor) NssaiAvailabilityNfInstancePatch(
 		return
 	}
 
-	// TODO: Check if returned HTTP status codes or problem details are proper when errors occur
+	c.Header("ETag", amfEntityTag(&amfConfig))
+	c.JSON(http.StatusOK, buildNssaiAvailabilityView(amfConfig, query.View))
+}
+
+// List NSSAI availability information of all AMFs matching the query, indexed by NF instance ID of the AMF
+func (p *Processor) NssaiAvailabilityList(c *gin.Context, query NssaiAvailabilityListQuery) {
+	var amfConfigList []factory.AmfConfig
 
-	// Provide JSON string with null or empty value in `Value` of `PatchItem`
-	for i, patchItem := range nssaiAvailabilityUpdateInfo {
-		if reflect.ValueOf(patchItem.Value).Kind() == reflect.Map {
-			_, exist := patchItem.Value.(map[string]interface{})["sst"]
-			_, notExist := patchItem.Value.(map[string]interface{})["sd"]
-			if exist && !notExist {
-				nssaiAvailabilityUpdateInfo[i].Value.(map[string]interface{})["sd"] = ""
-			}
+	factory.NssfConfig.RLock()
+	for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
+		if query.AmfSetId != "" && !util.CheckAmfInAmfSetLocked(amfConfig.NfId, query.AmfSetId) {
+			continue
 		}
+
+		filtered := filterSupportedNssaiAvailabilityData(amfConfig.SupportedNssaiAvailabilityData,
+			query.Tai, query.Snssai)
+		if len(filtered) == 0 && (query.Tai != nil || query.Snssai != nil) {
+			continue
+		}
+		amfConfig.SupportedNssaiAvailabilityData = filtered
+		amfConfigList = append(amfConfigList, amfConfig)
 	}
-	patchJSON, err := json.Marshal(nssaiAvailabilityUpdateInfo)
-	if err != nil {
-		logger.NssaiavailLog.Errorf("Marshal error in NSSAIAvailabilityPatchProcedure: %+v", err)
+	factory.NssfConfig.RUnlock()
+
+	response := make(map[string]any, len(amfConfigList))
+	for _, amfConfig := range amfConfigList {
+		response[amfConfig.NfId] = buildNssaiAvailabilityView(amfConfig, query.View)
 	}
 
-	patch, err := jsonpatch.DecodePatch(patchJSON)
-	if err != nil {
+	c.JSON(http.StatusOK, response)
+}
+
+func (p *Processor) NssaiAvailabilityNfInstanceDelete(c *gin.Context, nfId string) {
+	before := removeAmfConfig(nfId)
+	if before == nil {
 		problemDetails := &models.ProblemDetails{
-			Title:  util.MALFORMED_REQUEST,
-			Status: http.StatusBadRequest,
-			Detail: err.Error(),
+			Title:  util.UNSUPPORTED_RESOURCE,
+			Status: http.StatusNotFound,
+			Detail: fmt.Sprintf("AMF ID '%s' does not exist", nfId),
 		}
 		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
 		util.GinProblemJson(c, problemDetails)
 		return
 	}
 
-	modified, err := patch.Apply(original)
-	if err != nil {
-		problemDetails := &models.ProblemDetails{
-			Title:  util.INVALID_REQUEST,
-			Status: http.StatusConflict,
-			Detail: err.Error(),
+	p.notifyNssaiAvailabilityChanges(tracing.Context(c), nfId, before.AmfSetId,
+		util.DiffSupportedNssaiAvailabilityData(before.SupportedNssaiAvailabilityData, nil))
+
+	c.Status(http.StatusNoContent)
+}
+
+func (p *Processor) NssaiAvailabilityNfInstancePatch(
+	c *gin.Context,
+	nssaiAvailabilityUpdateInfo plugin.PatchDocument, nfId string, ifMatch string,
+) {
+	p.patchNssaiAvailability(c, nfId, ifMatch, nssaiAvailabilityUpdateInfo.ApplyToSupportedNssaiAvailabilityData)
+}
+
+func (p *Processor) NssaiAvailabilityNfInstanceMergePatch(
+	c *gin.Context, mergePatch []byte, nfId string, ifMatch string,
+) {
+	p.patchNssaiAvailability(c, nfId, ifMatch,
+		func(data []models.SupportedNssaiAvailabilityData) (
+			[]models.SupportedNssaiAvailabilityData, *plugin.PatchError,
+		) {
+			return plugin.ApplyMergePatchToSupportedNssaiAvailabilityData(data, mergePatch)
+		})
+}
+
+// Apply the patch to NSSAI availability data of the AMF
+// Lookup, patch and store are done under the same write lock so that concurrent updates are never lost
+func (p *Processor) patchNssaiAvailability(
+	c *gin.Context, nfId string, ifMatch string,
+	apply func([]models.SupportedNssaiAvailabilityData) ([]models.SupportedNssaiAvailabilityData, *plugin.PatchError),
+) {
+	response := &models.AuthorizedNssaiAvailabilityInfo{}
+
+	before, after, problemDetails := updateAmfConfig(nfId, func(current *factory.AmfConfig) (
+		*factory.AmfConfig, *models.ProblemDetails,
+	) {
+		if current == nil {
+			return nil, &models.ProblemDetails{
+				Title:  util.UNSUPPORTED_RESOURCE,
+				Status: http.StatusNotFound,
+				Detail: fmt.Sprintf("AMF ID '%s' does not exist", nfId),
+			}
+		}
+		if problemDetails := checkIfMatch(ifMatch, current); problemDetails != nil {
+			return nil, problemDetails
 		}
-		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
-		util.GinProblemJson(c, problemDetails)
-		return
-	}
 
-	var updatedSupportedNssaiAvailabilityData []models.SupportedNssaiAvailabilityData
-	err = json.Unmarshal(modified, &updatedSupportedNssaiAvailabilityData)
-	if err != nil {
-		problemDetails := &models.ProblemDetails{
-			Title:  util.INVALID_REQUEST,
-			Status: http.StatusBadRequest,
-			Detail: err.Error(),
+		updatedSupportedNssaiAvailabilityData, patchErr := apply(current.SupportedNssaiAvailabilityData)
+		if patchErr != nil {
+			return nil, buildPatchProblemDetails(patchErr)
+		}
+
+		for _, s := range updatedSupportedNssaiAvailabilityData {
+			if !util.CheckSupportedNssaiInPlmnLocked(s.SupportedSnssaiList, *s.Tai.PlmnId) {
+				return nil, &models.ProblemDetails{
+					Title:  util.UNSUPPORTED_RESOURCE,
+					Status: http.StatusForbidden,
+					Detail: "S-NSSAI in Requested NSSAI is not supported in PLMN",
+					Cause:  "SNSSAI_NOT_SUPPORTED",
+				}
+			}
+		}
+
+		// Patched data is authorized in the same way as the data replaced by PUT,
+		// so that only the authorized S-NSSAIs are stored
+		authorizedData, unauthorizedData := util.AuthorizeSupportedNssaiAvailabilityDataLocked(nfId,
+			updatedSupportedNssaiAvailabilityData)
+		for _, s := range unauthorizedData {
+			logger.NssaiavailLog.Warnf("S-NSSAIs %+v of AMF %s under TAI %+v are not authorized",
+				s.SupportedSnssaiList, nfId, *s.Tai)
+		}
+
+		current.SupportedNssaiAvailabilityData = authorizedData
+		return current, nil
+	})
+	if problemDetails != nil {
+		if problemDetails.Cause != "" {
+			c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Cause)
+		} else {
+			c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
 		}
-		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
 		util.GinProblemJson(c, problemDetails)
 		return
 	}
 
-	if !validateSupportedNssaiAvailabilityDataList(c, updatedSupportedNssaiAvailabilityData) {
-		return
-	}
+	// Return authorized NSSAI availability information of updated TAI only
+	// TAI which is removed is returned with empty S-NSSAI list
+	changes := util.DiffSupportedNssaiAvailabilityData(before.SupportedNssaiAvailabilityData,
+		after.SupportedNssaiAvailabilityData)
+	for _, change := range changes {
+		logger.NssaiavailLog.Infof("NSSAI availability of AMF %s updated: %s", nfId, change)
+
+		if change.IsRemoved() {
+			tai := change.Tai
+			response.AuthorizedNssaiAvailabilityData = append(response.AuthorizedNssaiAvailabilityData,
+				models.AuthorizedNssaiAvailabilityData{
+					Tai:                 &tai,
+					SupportedSnssaiList: []models.ExtSnssai{},
+				})
+			continue
+		}
 
-	factory.NssfConfig.Lock()
-	factory.NssfConfig.Configuration.AmfList[amfIdx].SupportedNssaiAvailabilityData = updatedSupportedNssaiAvailabilityData
-	factory.NssfConfig.Unlock()
+		if authorizedNssaiAvailabilityData, ok := authorizeOfAmfTa(after, change.Tai); ok {
+			response.AuthorizedNssaiAvailabilityData = append(
+				response.AuthorizedNssaiAvailabilityData,
+				authorizedNssaiAvailabilityData)
+		}
+	}
 
-	// Return all authorized NSSAI availability information
-	response.AuthorizedNssaiAvailabilityData, err = util.AuthorizeOfAmfFromConfig(nfId)
+	// Features negotiated when the NSSAI availability information was created
+	negotiatedFeatures, err := util.NegotiateSupportedFeatures(models.ServiceName_NNSSF_NSSAIAVAILABILITY,
+		after.SupportedFeatures)
 	if err != nil {
-		logger.NssaiavailLog.Errorf("util AuthorizeOfAmfFromConfig error in NSSAIAvailabilityPatchProcedure: %+v", err)
+		logger.NssaiavailLog.Warnf("Invalid supported features of AMF %s: %+v", nfId, err)
 	}
+	response.AuthorizedNssaiAvailabilityData = applyNssaiavailSupportedFeatures(negotiatedFeatures,
+		response.AuthorizedNssaiAvailabilityData)
+	response.SupportedFeatures = util.FormatSupportedFeatures(negotiatedFeatures)
 
-	// TODO: Return authorized NSSAI availability information of updated TAI only
+	p.notifyNssaiAvailabilityChanges(tracing.Context(c), nfId, after.AmfSetId, changes)
 
+	c.Header("ETag", amfEntityTag(after))
 	c.JSON(http.StatusOK, response)
 }
 
+func buildPatchProblemDetails(patchErr *plugin.PatchError) *models.ProblemDetails {
+	problemDetails := &models.ProblemDetails{
+		Status: int32(patchErr.Status),
+		Detail: patchErr.Detail,
+	}
+	switch patchErr.Status {
+	case http.StatusBadRequest:
+		problemDetails.Title = util.MALFORMED_REQUEST
+	case http.StatusConflict, http.StatusUnprocessableEntity:
+		problemDetails.Title = util.INVALID_REQUEST
+	default:
+		problemDetails.Title = util.INTERNAL_ERROR
+	}
+	if patchErr.Param != "" {
+		problemDetails.InvalidParams = []models.InvalidParam{
+			{
+				Param:  patchErr.Param,
+				Reason: patchErr.Detail,
+			},
+		}
+	}
+	return problemDetails
+}
+
 // NSSAIAvailability PUT method
 func (p *Processor) NssaiAvailabilityNfInstanceUpdate(
 	c *gin.Context,
-	nssaiAvailabilityInfo models.NssaiAvailabilityInfo, nfId string,
+	nssaiAvailabilityInfo models.NssaiAvailabilityInfo, nfId string, ifMatch string,
 ) {
-	var (
-		response = &models.AuthorizedNssaiAvailabilityInfo{}
-		hitAmf   bool
-	)
+	response := &models.AuthorizedNssaiAvailabilityInfo{}
 
 	if !validateSupportedNssaiAvailabilityDataList(c, nssaiAvailabilityInfo.SupportedNssaiAvailabilityData) {
 		return
 	}
 
-	// TODO: Currently authorize all the provided S-NSSAIs
-	//       Take some issue into consideration e.g. operator policies
+	negotiatedFeatures, err := util.NegotiateSupportedFeatures(models.ServiceName_NNSSF_NSSAIAVAILABILITY,
+		nssaiAvailabilityInfo.SupportedFeatures)
+	if err != nil {
+		problemDetails := &models.ProblemDetails{
+			Title:  util.INVALID_REQUEST,
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+			InvalidParams: []models.InvalidParam{
+				{
+					Param:  "supportedFeatures",
+					Reason: err.Error(),
+				},
+			},
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+	supportedFeatures := util.FormatSupportedFeatures(negotiatedFeatures)
+
+	// Authorize the provided S-NSSAIs with S-NSSAIs supported in the TA and in the PLMN, and operator policies
+	// Only the authorized S-NSSAIs are stored and returned
+	authorizedData, unauthorizedData := util.AuthorizeSupportedNssaiAvailabilityData(nfId,
+		nssaiAvailabilityInfo.SupportedNssaiAvailabilityData)
+	for _, s := range unauthorizedData {
+		logger.NssaiavailLog.Warnf("S-NSSAIs %+v of AMF %s under TAI %+v are not authorized",
+			s.SupportedSnssaiList, nfId, *s.Tai)
+	}
 
 	// Find AMF configuration of given NfId
-	// If found, then update the SupportedNssaiAvailabilityData
-	factory.NssfConfig.Lock()
-	for i, amfConfig := range factory.NssfConfig.Configuration.AmfList {
-		if amfConfig.NfId == nfId {
-			factory.NssfConfig.Configuration.AmfList[i].SupportedNssaiAvailabilityData = nssaiAvailabilityInfo.
-				SupportedNssaiAvailabilityData
-
-			hitAmf = true
-			break
+	// If found, then update the SupportedNssaiAvailabilityData, otherwise create a new one
+	before, after, problemDetails := updateAmfConfig(nfId, func(current *factory.AmfConfig) (
+		*factory.AmfConfig, *models.ProblemDetails,
+	) {
+		if problemDetails := checkIfMatch(ifMatch, current); problemDetails != nil {
+			return nil, problemDetails
+		}
+		if current == nil {
+			current = &factory.AmfConfig{}
 		}
+		current.SupportedNssaiAvailabilityData = authorizedData
+		current.SupportedFeatures = supportedFeatures
+		current.AmfSetId = nssaiAvailabilityInfo.AmfSetId
+		return current, nil
+	})
+	if problemDetails != nil {
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
 	}
-	factory.NssfConfig.Unlock()
 
-	// If no AMF record is found, create a new one
-	if !hitAmf {
-		var amfConfig factory.AmfConfig
-		amfConfig.NfId = nfId
-		amfConfig.SupportedNssaiAvailabilityData = nssaiAvailabilityInfo.SupportedNssaiAvailabilityData
-		factory.NssfConfig.Lock()
-		factory.NssfConfig.Configuration.AmfList = append(
-			factory.NssfConfig.Configuration.AmfList,
-			amfConfig)
-		factory.NssfConfig.Unlock()
+	var beforeData []models.SupportedNssaiAvailabilityData
+	if before != nil {
+		beforeData = before.SupportedNssaiAvailabilityData
 	}
+	changes := util.DiffSupportedNssaiAvailabilityData(beforeData, after.SupportedNssaiAvailabilityData)
 
 	// Return all authorized NSSAI availability information
 	// a.AuthorizedNssaiAvailabilityData, _ = authorizeOfAmfFromConfig(nfId)
 
 	// Return authorized NSSAI availability information of updated TAI only
-	for _, s := range nssaiAvailabilityInfo.SupportedNssaiAvailabilityData {
-		authorizedNssaiAvailabilityData, err := util.AuthorizeOfAmfTaFromConfig(nfId, *s.Tai)
-		if err == nil {
+	for _, s := range authorizedData {
+		if authorizedNssaiAvailabilityData, ok := authorizeOfAmfTa(after, *s.Tai); ok {
 			response.AuthorizedNssaiAvailabilityData = append(
 				response.AuthorizedNssaiAvailabilityData,
 				authorizedNssaiAvailabilityData)
-		} else {
-			logger.NssaiavailLog.Warn(err)
 		}
 	}
 
+	response.AuthorizedNssaiAvailabilityData = applyNssaiavailSupportedFeatures(negotiatedFeatures,
+		response.AuthorizedNssaiAvailabilityData)
+	response.SupportedFeatures = supportedFeatures
+
+	p.notifyNssaiAvailabilityChanges(tracing.Context(c), nfId, after.AmfSetId, changes)
+
+	c.Header("ETag", amfEntityTag(after))
+	if before == nil {
+		c.Header("Location", fmt.Sprintf("%s%s/nssai-availability/%s",
+			nssf_context.GetIpv4Uri(), factory.NssfNssaiavailResUriPrefix, nfId))
+		c.JSON(http.StatusCreated, response)
+		return
+	}
 	c.JSON(http.StatusOK, response)
 }
@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/mock/gomock"
 
+	"github.com/free5gc/nssf/internal/plugin"
 	"github.com/free5gc/nssf/internal/sbi/processor"
 	"github.com/free5gc/nssf/internal/util"
 	"github.com/free5gc/nssf/pkg/app"
@@ -88,3 +92,405 @@ func TestNfInstanceDelete(t *testing.T) {
 		t.Errorf("Expected problemDetails.Detail to be '%s', got: '%s'", expectedDetail, problemDetails.Detail)
 	}
 }
+
+func TestNfInstanceUpdateIfMatch(t *testing.T) {
+	mockNssfApp := app.NewMockNssfApp(gomock.NewController(t))
+	processor := processor.NewProcessor(mockNssfApp)
+	factory.NssfConfig.Configuration.AmfList = nil
+
+	nfId := "469de254-2fe5-4ca0-8381-af3f500af77c"
+	put := func(ifMatch string) *httptest.ResponseRecorder {
+		httpRecorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(httpRecorder)
+		processor.NssaiAvailabilityNfInstanceUpdate(c, models.NssaiAvailabilityInfo{}, nfId, ifMatch)
+		return httpRecorder
+	}
+
+	// Test case 1: Conditional creation of a non-existing NF instance
+	if httpRecorder := put("*"); httpRecorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status code %d, got: %d", http.StatusPreconditionFailed, httpRecorder.Code)
+	}
+
+	// Test case 2: Unconditional creation
+	httpRecorder := put("")
+	if httpRecorder.Code != http.StatusCreated {
+		t.Errorf("Expected status code %d, got: %d", http.StatusCreated, httpRecorder.Code)
+	}
+	if location := httpRecorder.Header().Get("Location"); !strings.HasSuffix(location,
+		factory.NssfNssaiavailResUriPrefix+"/nssai-availability/"+nfId) {
+		t.Errorf("Expected Location of the created NSSAI availability information, got: '%s'", location)
+	}
+	etag := httpRecorder.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("Expected ETag in response")
+	}
+
+	// Test case 3: Update with the current entity tag
+	httpRecorder = put(etag)
+	if httpRecorder.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+	}
+	if location := httpRecorder.Header().Get("Location"); location != "" {
+		t.Errorf("Expected no Location on update, got: '%s'", location)
+	}
+	if httpRecorder.Header().Get("ETag") == etag {
+		t.Errorf("Expected ETag to be changed from %s", etag)
+	}
+
+	// Test case 4: Update with a stale entity tag
+	httpRecorder = put(etag)
+	if httpRecorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status code %d, got: %d", http.StatusPreconditionFailed, httpRecorder.Code)
+	}
+
+	var problemDetails models.ProblemDetails
+	if err := json.Unmarshal(httpRecorder.Body.Bytes(), &problemDetails); err != nil {
+		t.Errorf("Error unmarshalling response body: %v", err)
+	}
+	if problemDetails.Title != util.PRECONDITION_FAILED {
+		t.Errorf("Expected problemDetails.Title to be '%s', got: '%s'", util.PRECONDITION_FAILED, problemDetails.Title)
+	}
+}
+
+func TestNfInstancePatchIfMatch(t *testing.T) {
+	mockNssfApp := app.NewMockNssfApp(gomock.NewController(t))
+	processor := processor.NewProcessor(mockNssfApp)
+	factory.NssfConfig.Configuration.AmfList = []factory.AmfConfig{
+		{
+			NfId:    "nf1",
+			Version: 1,
+		},
+	}
+
+	patch := func(ifMatch string) *httptest.ResponseRecorder {
+		httpRecorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(httpRecorder)
+		processor.NssaiAvailabilityNfInstancePatch(c, plugin.PatchDocument{}, "nf1", ifMatch)
+		return httpRecorder
+	}
+
+	// Test case 1: Patch with a stale entity tag
+	if httpRecorder := patch(`"0"`); httpRecorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status code %d, got: %d", http.StatusPreconditionFailed, httpRecorder.Code)
+	}
+
+	// Test case 2: Patch with one of the entity tags matched
+	httpRecorder := patch(`"0", "1"`)
+	if httpRecorder.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+	}
+	if etag := httpRecorder.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("Expected ETag to be '\"2\"', got: '%s'", etag)
+	}
+
+	// Test case 3: Weak entity tag never matches
+	if httpRecorder = patch(`W/"2"`); httpRecorder.Code != http.StatusPreconditionFailed {
+		t.Errorf("Expected status code %d, got: %d", http.StatusPreconditionFailed, httpRecorder.Code)
+	}
+}
+
+func TestNfInstancePatchAuthorization(t *testing.T) {
+	plmnId := models.PlmnId{Mcc: "466", Mnc: "92"}
+	tai := models.Tai{PlmnId: &plmnId, Tac: "33456"}
+	otherTai := models.Tai{PlmnId: &plmnId, Tac: "33457"}
+
+	defer func(configuration *factory.Configuration) {
+		factory.NssfConfig.Configuration = configuration
+	}(factory.NssfConfig.Configuration)
+	factory.NssfConfig.Configuration = &factory.Configuration{
+		SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+			{PlmnId: &plmnId, SupportedSnssaiList: []models.Snssai{{Sst: 1, Sd: "000001"}, {Sst: 1, Sd: "000002"}}},
+		},
+		TaList: []factory.TaConfig{
+			{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "000001"}, {Sst: 1, Sd: "000002"}}},
+			{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "000001"}}},
+		},
+		AmfPolicyList: []factory.AmfPolicy{
+			{
+				NfId: "nf1",
+				AuthorizedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+					{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "000001"}}},
+					{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "000001"}}},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		value        models.ExtSnssai
+		expectStatus int
+		expectStored []models.ExtSnssai
+	}{
+		{
+			name:         "S-NSSAI authorized by TA and policy is stored",
+			value:        models.ExtSnssai{Sst: 1, Sd: "000001"},
+			expectStatus: http.StatusOK,
+			expectStored: []models.ExtSnssai{{Sst: 1, Sd: "000001"}},
+		},
+		{
+			name:         "S-NSSAI not authorized by policy is not stored",
+			value:        models.ExtSnssai{Sst: 1, Sd: "000002"},
+			expectStatus: http.StatusOK,
+			expectStored: nil,
+		},
+		{
+			name:         "S-NSSAI not supported in PLMN is forbidden",
+			value:        models.ExtSnssai{Sst: 1, Sd: "000003"},
+			expectStatus: http.StatusForbidden,
+			expectStored: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory.NssfConfig.Configuration.AmfList = []factory.AmfConfig{
+				{
+					NfId: "nf1",
+					SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{}},
+					},
+				},
+			}
+
+			processor := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			patchDocument := plugin.PatchDocument{
+				{Op: models.PatchOperation_ADD, Path: "/0/supportedSnssaiList/-", Value: tc.value},
+			}
+			processor.NssaiAvailabilityNfInstancePatch(c, patchDocument, "nf1", "")
+			if httpRecorder.Code != tc.expectStatus {
+				t.Fatalf("Expected status code %d, got: %d", tc.expectStatus, httpRecorder.Code)
+			}
+
+			var stored []models.ExtSnssai
+			for _, s := range factory.NssfConfig.Configuration.AmfList[0].SupportedNssaiAvailabilityData {
+				stored = append(stored, s.SupportedSnssaiList...)
+			}
+			if !reflect.DeepEqual(stored, tc.expectStored) {
+				t.Errorf("Expected stored S-NSSAIs %+v, got: %+v", tc.expectStored, stored)
+			}
+		})
+	}
+}
+
+func TestNfInstanceConcurrentUpdateIfMatch(t *testing.T) {
+	mockNssfApp := app.NewMockNssfApp(gomock.NewController(t))
+	processor := processor.NewProcessor(mockNssfApp)
+	factory.NssfConfig.Configuration.AmfList = []factory.AmfConfig{
+		{
+			NfId:    "nf1",
+			Version: 1,
+		},
+	}
+
+	// Only one of the writers with the same entity tag wins
+	const writers = 16
+	codes := make(chan int, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			if i%2 == 0 {
+				processor.NssaiAvailabilityNfInstanceUpdate(c, models.NssaiAvailabilityInfo{}, "nf1", `"1"`)
+			} else {
+				processor.NssaiAvailabilityNfInstancePatch(c, plugin.PatchDocument{}, "nf1", `"1"`)
+			}
+			codes <- httpRecorder.Code
+		}(i)
+	}
+	wg.Wait()
+	close(codes)
+
+	succeeded := 0
+	for code := range codes {
+		switch code {
+		case http.StatusOK:
+			succeeded++
+		case http.StatusPreconditionFailed:
+		default:
+			t.Errorf("Unexpected status code %d", code)
+		}
+	}
+	if succeeded != 1 {
+		t.Errorf("Expected exactly one update to succeed, got: %d", succeeded)
+	}
+	if version := factory.NssfConfig.Configuration.AmfList[0].Version; version != 2 {
+		t.Errorf("Expected version to be 2, got: %d", version)
+	}
+}
+
+func TestNfInstanceGetSupportedFeatures(t *testing.T) {
+	plmnId := models.PlmnId{Mcc: "466", Mnc: "92"}
+	tai := models.Tai{PlmnId: &plmnId, Tac: "33456"}
+
+	defer func(configuration *factory.Configuration) {
+		factory.NssfConfig.Configuration = configuration
+	}(factory.NssfConfig.Configuration)
+
+	supportedSnssaiList := []models.ExtSnssai{
+		{Sst: 1, Sd: "000001"},
+		{Sst: 1, WildcardSd: true},
+		{Sst: 2, SdRanges: []models.SdRange{{Start: "000001", End: "0000ff"}}},
+	}
+
+	testCases := []struct {
+		name              string
+		supportedFeatures string
+		expectFeatures    string
+		expectSnssaiList  []models.ExtSnssai
+	}{
+		{
+			name:              "SD ranges not negotiated",
+			supportedFeatures: "",
+			expectFeatures:    "",
+			expectSnssaiList:  []models.ExtSnssai{{Sst: 1, Sd: "000001"}},
+		},
+		{
+			name:              "SD ranges negotiated",
+			supportedFeatures: "4",
+			expectFeatures:    "4",
+			expectSnssaiList:  supportedSnssaiList,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory.NssfConfig.Configuration = &factory.Configuration{
+				AmfList: []factory.AmfConfig{
+					{
+						NfId: "nf1",
+						SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+							{Tai: &tai, SupportedSnssaiList: supportedSnssaiList},
+						},
+						SupportedFeatures: tc.supportedFeatures,
+					},
+				},
+			}
+
+			p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			p.NssaiAvailabilityNfInstanceGet(c, "nf1", processor.NssaiAvailabilityGetQuery{
+				View: processor.NssaiAvailabilityViewAuthorized,
+			})
+			if httpRecorder.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+			}
+
+			var info models.AuthorizedNssaiAvailabilityInfo
+			if err := json.Unmarshal(httpRecorder.Body.Bytes(), &info); err != nil {
+				t.Fatalf("Error unmarshalling response body: %v", err)
+			}
+			if info.SupportedFeatures != tc.expectFeatures {
+				t.Errorf("Expected supported features %q, got: %q", tc.expectFeatures, info.SupportedFeatures)
+			}
+			if len(info.AuthorizedNssaiAvailabilityData) != 1 {
+				t.Fatalf("Expected 1 authorized NSSAI availability data, got: %d",
+					len(info.AuthorizedNssaiAvailabilityData))
+			}
+			// S-NSSAIs which could not be represented without SD ranges are removed
+			if supported := info.AuthorizedNssaiAvailabilityData[0].SupportedSnssaiList; !reflect.DeepEqual(
+				supported, tc.expectSnssaiList) {
+				t.Errorf("Expected supported S-NSSAIs %+v, got: %+v", tc.expectSnssaiList, supported)
+			}
+		})
+	}
+}
+
+func TestNfInstancePatchChangedTai(t *testing.T) {
+	plmnId := models.PlmnId{Mcc: "466", Mnc: "92"}
+	tai := models.Tai{PlmnId: &plmnId, Tac: "33456"}
+	otherTai := models.Tai{PlmnId: &plmnId, Tac: "33457"}
+	snssaiList := []models.ExtSnssai{{Sst: 1, Sd: "000001"}, {Sst: 1, Sd: "000002"}}
+
+	defer func(configuration *factory.Configuration) {
+		factory.NssfConfig.Configuration = configuration
+	}(factory.NssfConfig.Configuration)
+	factory.NssfConfig.Configuration = &factory.Configuration{
+		SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+			{PlmnId: &plmnId, SupportedSnssaiList: []models.Snssai{{Sst: 1, Sd: "000001"}, {Sst: 1, Sd: "000002"}}},
+		},
+		TaList: []factory.TaConfig{
+			{Tai: &tai, SupportedSnssaiList: snssaiList},
+			{Tai: &otherTai, SupportedSnssaiList: snssaiList},
+		},
+		AmfPolicyList: []factory.AmfPolicy{
+			{
+				NfId: "nf1",
+				AuthorizedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+					{Tai: &tai, SupportedSnssaiList: snssaiList},
+					{Tai: &otherTai, SupportedSnssaiList: snssaiList},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		patchDocument plugin.PatchDocument
+		expectData    []models.AuthorizedNssaiAvailabilityData
+	}{
+		{
+			name: "S-NSSAI added to TAI",
+			patchDocument: plugin.PatchDocument{
+				{Op: models.PatchOperation_ADD, Path: "/0/supportedSnssaiList/-", Value: snssaiList[1]},
+			},
+			expectData: []models.AuthorizedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: snssaiList},
+			},
+		},
+		{
+			name: "TAI removed",
+			patchDocument: plugin.PatchDocument{
+				{Op: models.PatchOperation_REMOVE, Path: "/1"},
+			},
+			expectData: []models.AuthorizedNssaiAvailabilityData{
+				{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{}},
+			},
+		},
+		{
+			name: "Unchanged",
+			patchDocument: plugin.PatchDocument{
+				{Op: models.PatchOperation_REPLACE, Path: "/0/supportedSnssaiList/0", Value: snssaiList[0]},
+			},
+			expectData: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory.NssfConfig.Configuration.AmfList = []factory.AmfConfig{
+				{
+					NfId: "nf1",
+					SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "000001"}}},
+						{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "000001"}}},
+					},
+				},
+			}
+
+			processor := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+			httpRecorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(httpRecorder)
+			processor.NssaiAvailabilityNfInstancePatch(c, tc.patchDocument, "nf1", "")
+			if httpRecorder.Code != http.StatusOK {
+				t.Fatalf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+			}
+
+			var info models.AuthorizedNssaiAvailabilityInfo
+			if err := json.Unmarshal(httpRecorder.Body.Bytes(), &info); err != nil {
+				t.Fatalf("Error unmarshalling response body: %v", err)
+			}
+			// Only TAIs of which NSSAI availability data is changed are returned
+			if !reflect.DeepEqual(info.AuthorizedNssaiAvailabilityData, tc.expectData) {
+				t.Errorf("Expected authorized NSSAI availability data %+v, got: %+v",
+					tc.expectData, info.AuthorizedNssaiAvailabilityData)
+			}
+		})
+	}
+}
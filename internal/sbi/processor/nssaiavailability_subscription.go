@@ -8,102 +8,85 @@ package processor
 
 import (
 	"fmt"
-	"math"
 	"net/http"
-	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 
+	nssf_context "github.com/free5gc/nssf/internal/context"
 	"github.com/free5gc/nssf/internal/logger"
 	"github.com/free5gc/nssf/internal/util"
 	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
 	"github.com/free5gc/util/metrics/sbi"
 )
 
-// Create a new subscription with an unused ID and add it to configuration
-// In this implementation, string converted from 32-bit integer is used as subscription ID
-func createSubscription(createData models.NssfEventSubscriptionCreateData) (factory.Subscription, error) {
-	var subscription factory.Subscription
-	var idx uint32 = 1
-	factory.NssfConfig.Lock()
-	defer factory.NssfConfig.Unlock()
-	for _, sub := range factory.NssfConfig.Subscriptions {
-		tempID, err := strconv.Atoi(sub.SubscriptionId)
-		if err != nil {
-			return subscription, err
-		}
-		if uint32(tempID) == idx {
-			if idx == math.MaxUint32 {
-				return subscription, fmt.Errorf("no available subscription ID")
-			}
-			idx++
-		} else {
-			break
-		}
-	}
-
-	subscription.SubscriptionId = strconv.Itoa(int(idx))
-	subscription.SubscriptionData = new(models.NssfEventSubscriptionCreateData)
-	*subscription.SubscriptionData = createData
-
-	factory.NssfConfig.Subscriptions = append(factory.NssfConfig.Subscriptions, subscription)
-
-	return subscription, nil
-}
-
 // NSSAIAvailability subscription POST method
 func (p *Processor) NssaiAvailabilitySubscriptionCreate(
 	c *gin.Context,
 	createData models.NssfEventSubscriptionCreateData,
+	consumerNfId string,
 ) {
 	var (
-		response       = &models.NssfEventSubscriptionCreatedData{}
+		response       *models.NssfEventSubscriptionCreatedData
 		problemDetails *models.ProblemDetails
 	)
 
-	subscription, err := createSubscription(createData)
+	negotiatedFeatures, err := util.NegotiateSupportedFeatures(models.ServiceName_NNSSF_NSSAIAVAILABILITY,
+		createData.SupportedFeatures)
 	if err != nil {
-		logger.NssaiavailLog.Warn(err)
-
 		problemDetails = &models.ProblemDetails{
-			Title:  util.UNSUPPORTED_RESOURCE,
-			Status: http.StatusNotFound,
+			Title:  util.INVALID_REQUEST,
+			Status: http.StatusBadRequest,
 			Detail: err.Error(),
+			InvalidParams: []models.InvalidParam{
+				{
+					Param:  "supportedFeatures",
+					Reason: err.Error(),
+				},
+			},
 		}
 		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
 		util.GinProblemJson(c, problemDetails)
 		return
 	}
-
-	response.SubscriptionId = subscription.SubscriptionId
-	if subscription.SubscriptionData.Expiry != nil && !subscription.SubscriptionData.Expiry.IsZero() {
-		response.Expiry = new(time.Time)
-		*response.Expiry = *subscription.SubscriptionData.Expiry
+	if err = util.ValidateCallbackUri(createData.NfNssaiAvailabilityUri); err != nil {
+		problemDetails = &models.ProblemDetails{
+			Title:  util.INVALID_REQUEST,
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+			InvalidParams: []models.InvalidParam{
+				{
+					Param:  "nfNssaiAvailabilityUri",
+					Reason: err.Error(),
+				},
+			},
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
 	}
-	response.AuthorizedNssaiAvailabilityData = util.AuthorizeOfTaListFromConfig(subscription.SubscriptionData.TaiList)
 
-	c.JSON(http.StatusOK, response)
-}
+	// Keep the negotiated features in the subscription for later notifications
+	createData.SupportedFeatures = util.FormatSupportedFeatures(negotiatedFeatures)
 
-func (p *Processor) NssaiAvailabilitySubscriptionUnsubscribe(c *gin.Context, subscriptionId string) {
-	var problemDetails *models.ProblemDetails
+	subscription := nssf_context.GetSelf().Subscriptions.Add(createData, consumerNfId)
+	response = buildSubscriptionCreatedData(subscription, negotiatedFeatures)
 
-	factory.NssfConfig.Lock()
-	defer factory.NssfConfig.Unlock()
-	for i, subscription := range factory.NssfConfig.Subscriptions {
-		if subscription.SubscriptionId == subscriptionId {
-			factory.NssfConfig.Subscriptions = append(factory.NssfConfig.Subscriptions[:i],
-				factory.NssfConfig.Subscriptions[i+1:]...)
+	c.Header("Location", fmt.Sprintf("%s%s/nssai-availability/subscriptions/%s",
+		nssf_context.GetIpv4Uri(), factory.NssfNssaiavailResUriPrefix, subscription.SubscriptionId))
+	c.JSON(http.StatusCreated, response)
+}
 
-			c.Status(http.StatusNoContent)
-			return
-		}
+func (p *Processor) NssaiAvailabilitySubscriptionUnsubscribe(c *gin.Context, subscriptionId string) {
+	if nssf_context.GetSelf().Subscriptions.Remove(subscriptionId) {
+		c.Status(http.StatusNoContent)
+		return
 	}
 
 	// No specific subscription ID exists
-	problemDetails = &models.ProblemDetails{
+	problemDetails := &models.ProblemDetails{
 		Title:  util.UNSUPPORTED_RESOURCE,
 		Status: http.StatusNotFound,
 		Detail: fmt.Sprintf("Subscription ID '%s' is not available", subscriptionId),
@@ -111,3 +94,58 @@ func (p *Processor) NssaiAvailabilitySubscriptionUnsubscribe(c *gin.Context, sub
 	c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
 	util.GinProblemJson(c, problemDetails)
 }
+
+// Build the created data of the subscription as returned when it is created,
+// with NSSAI availability authorized currently
+func buildSubscriptionCreatedData(
+	subscription factory.Subscription, negotiatedFeatures openapi.SupportedFeature,
+) *models.NssfEventSubscriptionCreatedData {
+	data := &models.NssfEventSubscriptionCreatedData{
+		SubscriptionId:    subscription.SubscriptionId,
+		SupportedFeatures: subscription.SubscriptionData.SupportedFeatures,
+	}
+	if subscription.SubscriptionData.Expiry != nil && !subscription.SubscriptionData.Expiry.IsZero() {
+		data.Expiry = new(time.Time)
+		*data.Expiry = *subscription.SubscriptionData.Expiry
+	}
+	data.AuthorizedNssaiAvailabilityData = applyNssaiavailSupportedFeatures(negotiatedFeatures,
+		authorizeSubscriptionData(subscription.SubscriptionData, subscription.SubscriptionData.TaiList))
+	return data
+}
+
+// NSSAI availability subscription held by NSSF
+// It is the created data returned when the subscription is created, so that it is decoded as
+// NssfEventSubscriptionCreatedData by consumers, along with the state of notification delivery
+type NssaiAvailabilitySubscriptionData struct {
+	models.NssfEventSubscriptionCreatedData
+	// Set if the latest notification is not delivered to the subscriber
+	DeliveryFailure *nssf_context.DeliveryFailure `json:"deliveryFailure,omitempty"`
+}
+
+func (p *Processor) NssaiAvailabilitySubscriptionGet(c *gin.Context, subscriptionId string) {
+	subscription, ok := nssf_context.GetSelf().Subscriptions.Get(subscriptionId)
+	if !ok {
+		problemDetails := &models.ProblemDetails{
+			Title:  util.UNSUPPORTED_RESOURCE,
+			Status: http.StatusNotFound,
+			Detail: fmt.Sprintf("Subscription ID '%s' is not available", subscriptionId),
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+
+	negotiatedFeatures, err := util.NegotiateSupportedFeatures(models.ServiceName_NNSSF_NSSAIAVAILABILITY,
+		subscription.SubscriptionData.SupportedFeatures)
+	if err != nil {
+		logger.NssaiavailLog.Warnf("Invalid supported features of subscription %s: %+v", subscriptionId, err)
+	}
+	response := &NssaiAvailabilitySubscriptionData{
+		NssfEventSubscriptionCreatedData: *buildSubscriptionCreatedData(subscription, negotiatedFeatures),
+	}
+	if failure, undeliverable := nssf_context.GetSelf().Subscriptions.GetDeliveryFailure(subscriptionId); undeliverable {
+		response.DeliveryFailure = &failure
+	}
+
+	c.JSON(http.StatusOK, response)
+}
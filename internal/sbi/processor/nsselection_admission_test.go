@@ -0,0 +1,172 @@
+package processor_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/internal/nsac"
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+const nsacAmfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+
+var nsacSnssai = models.Snssai{Sst: 1, Sd: "010203"}
+
+func setNsacConfig(quota factory.SnssaiQuota) {
+	setNsselectionConfig()
+	quota.Snssai = &nsacSnssai
+	factory.NssfConfig.Configuration.Nsac = &factory.NsacConfig{
+		Enable:    true,
+		Mode:      factory.NsacModeLocal,
+		QuotaList: []factory.SnssaiQuota{quota},
+	}
+}
+
+// Run NSSelection Get with the correlation information identifying the UE, if not empty
+func getNetworkSliceInformationOfUe(t *testing.T, p *processor.Processor,
+	param processor.NetworkSliceInformationGetQuery, correlationInfo string,
+) *models.AuthorizedNetworkSliceInfo {
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/network-slice-information", nil)
+	if correlationInfo != "" {
+		c.Request.Header.Set("3gpp-Sbi-Correlation-Info", correlationInfo)
+	}
+	p.NSSelectionSliceInformationGet(c, param)
+
+	if httpRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+	}
+	var authorizedNetworkSliceInfo models.AuthorizedNetworkSliceInfo
+	if err := json.Unmarshal(httpRecorder.Body.Bytes(), &authorizedNetworkSliceInfo); err != nil {
+		t.Fatalf("Error unmarshalling response body: %v", err)
+	}
+	return &authorizedNetworkSliceInfo
+}
+
+func isSnssaiAllowed(authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo, snssai models.Snssai) bool {
+	for _, allowedNssai := range authorizedNetworkSliceInfo.AllowedNssaiList {
+		for _, allowedSnssai := range allowedNssai.AllowedSnssaiList {
+			if *allowedSnssai.AllowedSnssai == snssai {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestNSSelectionAdmissionHolders(t *testing.T) {
+	const (
+		subscriptionId = "nrf-subscription-1"
+		notifyToken    = "notify-token-1"
+	)
+
+	setNsacConfig(factory.SnssaiQuota{MaxNumOfUes: 1})
+	p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+	p.SetAdmissionController(nsac.NewLocalController())
+
+	param := processor.NetworkSliceInformationGetQuery{
+		NfType: models.NrfNfManagementNfType_AMF,
+		NfId:   nsacAmfId,
+		SliceInfoRequestForRegistration: &models.SliceInfoForRegistration{
+			SubscribedNssai: []models.SubscribedSnssai{
+				{SubscribedSnssai: &nsacSnssai},
+			},
+			RequestedNssai: []models.Snssai{nsacSnssai},
+		},
+		Tai: &nsselectionTai,
+	}
+
+	steps := []struct {
+		name            string
+		correlationInfo string
+		expectAllowed   bool
+	}{
+		{"UE within the quota", "imsi-208930000000001", true},
+		{"Same UE registering again", "imsi-208930000000001,gpsi-msisdn-886912345678", true},
+		{"Another UE exceeding the quota", "imsi-208930000000002", false},
+		{"UE not identified exceeding the quota", "", false},
+	}
+	for _, step := range steps {
+		authorizedNetworkSliceInfo := getNetworkSliceInformationOfUe(t, p, param, step.correlationInfo)
+		if allowed := isSnssaiAllowed(authorizedNetworkSliceInfo, nsacSnssai); allowed != step.expectAllowed {
+			t.Errorf("%s: expected S-NSSAI to be allowed: %v, got: %+v", step.name, step.expectAllowed,
+				authorizedNetworkSliceInfo)
+		}
+	}
+
+	// UEs admitted through the AMF are released once the AMF is deregistered
+	nssfCtx := nssf_context.GetSelf()
+	nssfCtx.NrfAmfStatusSubscription.Set(subscriptionId, notifyToken, time.Time{})
+	defer nssfCtx.NrfAmfStatusSubscription.Clear(subscriptionId)
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	p.NfStatusNotify(c, notifyToken, models.NrfNfManagementNotificationData{
+		Event:         models.NotificationEventType_DEREGISTERED,
+		NfInstanceUri: "http://127.0.0.10:8000/nnrf-nfm/v1/nf-instances/" + nsacAmfId,
+	})
+	if c.Writer.Status() != http.StatusNoContent {
+		t.Fatalf("Expected status code %d, got: %d", http.StatusNoContent, c.Writer.Status())
+	}
+
+	authorizedNetworkSliceInfo := getNetworkSliceInformationOfUe(t, p, param, "imsi-208930000000002")
+	if !isSnssaiAllowed(authorizedNetworkSliceInfo, nsacSnssai) {
+		t.Errorf("Expected S-NSSAI to be allowed after the AMF is deregistered, got: %+v", authorizedNetworkSliceInfo)
+	}
+}
+
+func TestNSSelectionAdmissionAfterSelection(t *testing.T) {
+	setNsacConfig(factory.SnssaiQuota{MaxNumOfPduSessions: 1})
+	p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+	p.SetAdmissionController(nsac.NewLocalController())
+
+	param := processor.NetworkSliceInformationGetQuery{
+		NfType: models.NrfNfManagementNfType_AMF,
+		NfId:   nsacAmfId,
+		SliceInfoRequestForPduSession: &models.SliceInfoForPduSession{
+			SNssai:            &nsacSnssai,
+			RoamingIndication: models.RoamingIndication_NON_ROAMING,
+		},
+		Tai: &nsselectionTai,
+	}
+
+	// Selections failing without NSI of the S-NSSAI hold no quota
+	for i := 0; i < 2; i++ {
+		authorizedNetworkSliceInfo := getNetworkSliceInformationOfUe(t, p, param, "")
+		if authorizedNetworkSliceInfo.NsiInformation != nil || len(authorizedNetworkSliceInfo.RejectedNssaiInPlmn) != 0 {
+			t.Fatalf("Expected selection to fail without NSI, got: %+v", authorizedNetworkSliceInfo)
+		}
+	}
+
+	factory.NssfConfig.Lock()
+	factory.NssfConfig.Configuration.NsiList = []factory.NsiConfig{
+		{
+			Snssai:             &nsacSnssai,
+			NsiInformationList: []models.NsiInformation{{NrfId: "http://127.0.0.10:8000/nnrf-nfm/v1/nf-instances"}},
+		},
+	}
+	factory.NssfConfig.RefreshSnapshotLocked()
+	factory.NssfConfig.Unlock()
+
+	authorizedNetworkSliceInfo := getNetworkSliceInformationOfUe(t, p, param, "")
+	if authorizedNetworkSliceInfo.NsiInformation == nil {
+		t.Fatalf("Expected PDU session within the quota to be admitted, got: %+v", authorizedNetworkSliceInfo)
+	}
+
+	authorizedNetworkSliceInfo = getNetworkSliceInformationOfUe(t, p, param, "")
+	rejectedNssaiInPlmn := authorizedNetworkSliceInfo.RejectedNssaiInPlmn
+	if authorizedNetworkSliceInfo.NsiInformation != nil || len(rejectedNssaiInPlmn) != 1 ||
+		rejectedNssaiInPlmn[0] != nsacSnssai {
+		t.Errorf("Expected PDU session exceeding the quota to be rejected, got: %+v", authorizedNetworkSliceInfo)
+	}
+}
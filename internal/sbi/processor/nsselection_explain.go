@@ -0,0 +1,240 @@
+/*
+ * NSSF NS Selection
+ *
+ * Explanation of network slice selection
+ */
+
+package processor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+// Header of NSSelection request to have the explanation returned along with Authorized Network Slice Info
+const ExplainHeader = "X-Nssf-Explain"
+
+// Content-IDs of parts in multipart/related response of explained network slice selection
+const (
+	explainContentIdResult      = "authorizedNetworkSliceInfo"
+	explainContentIdExplanation = "explanation"
+)
+
+// Stages of network slice selection recorded in explanation
+const (
+	ExplainStageHomePlmn       = "homePlmnCheck"
+	ExplainStageTa             = "taCheck"
+	ExplainStagePlmn           = "plmnCheck"
+	ExplainStageMapping        = "mappingLookup"
+	ExplainStageSubscription   = "subscriptionCheck"
+	ExplainStageRestrictedList = "restrictedListCheck"
+	ExplainStageAdmission      = "admissionControl"
+	ExplainStageAmf            = "amfMatch"
+	ExplainStageAmfSet         = "amfSetMatch"
+	ExplainStageNsi            = "nsiSelection"
+)
+
+// Procedures of network slice selection
+const (
+	ExplainProcedureRegistration = "registration"
+	ExplainProcedurePduSession   = "pduSession"
+)
+
+// Decision made at a stage of network slice selection
+type ExplainStep struct {
+	Stage  string         `json:"stage"`
+	Snssai *models.Snssai `json:"snssai,omitempty"`
+	// Whether the check is passed or a match is found
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail"`
+}
+
+// Explanation of network slice selection, with decisions in the order they are made
+type Explanation struct {
+	Procedure string        `json:"procedure"`
+	Steps     []ExplainStep `json:"steps"`
+}
+
+type explanationCtxKey struct{}
+
+func withExplanation(ctx context.Context, explanation *Explanation) context.Context {
+	return context.WithValue(ctx, explanationCtxKey{}, explanation)
+}
+
+// Get the explanation being recorded, which is nil if the selection is not explained
+func explanationFrom(ctx context.Context) *Explanation {
+	explanation, _ := ctx.Value(explanationCtxKey{}).(*Explanation)
+	return explanation
+}
+
+// Set the procedure of network slice selection, nothing is done if the selection is not explained
+func (e *Explanation) setProcedure(procedure string) {
+	if e != nil {
+		e.Procedure = procedure
+	}
+}
+
+// Record a decision, nothing is done if the selection is not explained
+func (e *Explanation) record(stage string, passed bool, format string, args ...any) {
+	if e == nil {
+		return
+	}
+	e.Steps = append(e.Steps, ExplainStep{
+		Stage:  stage,
+		Passed: passed,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// Record a decision on the S-NSSAI, nothing is done if the selection is not explained
+func (e *Explanation) recordSnssai(stage string, snssai models.Snssai, passed bool, format string, args ...any) {
+	if e == nil {
+		return
+	}
+	e.Steps = append(e.Steps, ExplainStep{
+		Stage:  stage,
+		Snssai: &snssai,
+		Passed: passed,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// Check whether the explanation is requested by the NSSelection request
+func isExplainRequested(c *gin.Context) bool {
+	if c.Request == nil {
+		return false
+	}
+	explain, err := strconv.ParseBool(c.GetHeader(ExplainHeader))
+	return err == nil && explain
+}
+
+func logExplanation(explanation *Explanation) {
+	if !logger.NsselLog.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+	data, err := json.Marshal(explanation)
+	if err != nil {
+		logger.NsselLog.Errorf("Marshal explanation failed: %+v", err)
+		return
+	}
+	logger.NsselLog.Debugf("Explanation of network slice selection: %s", data)
+}
+
+// Respond with Authorized Network Slice Info and its explanation as parts of multipart/related body
+// Authorized Network Slice Info is the root part, so that it could still be located by the consumer
+func explainedJson(c *gin.Context, status int, response *models.AuthorizedNetworkSliceInfo,
+	explanation *Explanation,
+) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	parts := []struct {
+		contentId string
+		value     any
+	}{
+		{explainContentIdResult, response},
+		{explainContentIdExplanation, explanation},
+	}
+	for _, part := range parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", "application/json")
+		header.Set("Content-Id", part.contentId)
+		partWriter, err := writer.CreatePart(header)
+		if err == nil {
+			err = json.NewEncoder(partWriter).Encode(part.value)
+		}
+		if err != nil {
+			logger.NsselLog.Errorf("Encode explanation failed: %+v", err)
+			c.JSON(status, response)
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		logger.NsselLog.Errorf("Encode explanation failed: %+v", err)
+		c.JSON(status, response)
+		return
+	}
+
+	contentType := fmt.Sprintf("multipart/related; boundary=%s; type=\"application/json\"; start=\"%s\"",
+		writer.Boundary(), explainContentIdResult)
+	c.Data(status, contentType, body.Bytes())
+}
+
+// Record whether the S-NSSAI is restricted in UE's current TA for roamers of UE's Home PLMN
+// Restricted S-NSSAIs are informed to AMFs with NSSAI availability, and are not enforced in network slice selection
+func explainRestrictedSnssai(explanation *Explanation, param NetworkSliceInformationGetQuery, snssai models.Snssai) {
+	if explanation == nil || param.HomePlmnId == nil || param.Tai == nil {
+		return
+	}
+
+	for _, restrictedSnssai := range util.GetRestrictedSnssaiListFromConfig(*param.Tai) {
+		restricted := restrictedSnssai.HomePlmnId != nil && *restrictedSnssai.HomePlmnId == *param.HomePlmnId
+		for _, homePlmnId := range restrictedSnssai.HomePlmnIdList {
+			restricted = restricted || homePlmnId == *param.HomePlmnId
+		}
+		if restricted && util.CheckSnssaiInNssai(snssai, restrictedSnssai.SNssaiList) {
+			explanation.recordSnssai(ExplainStageRestrictedList, snssai, false,
+				"S-NSSAI is restricted in TA %s for Home PLMN %s, which is informed to AMFs and not enforced here",
+				explainTai(*param.Tai), explainPlmnId(param.HomePlmnId))
+			return
+		}
+	}
+	explanation.recordSnssai(ExplainStageRestrictedList, snssai, true,
+		"S-NSSAI is not restricted in TA %s for Home PLMN %s", explainTai(*param.Tai), explainPlmnId(param.HomePlmnId))
+}
+
+// Record the AMF set or candidate AMFs found to serve the UE
+func explainAmfSelection(explanation *Explanation, tai models.Tai,
+	authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo,
+) {
+	if explanation == nil {
+		return
+	}
+
+	var allowedNssai []models.Snssai
+	for _, allowedNssaiElement := range authorizedNetworkSliceInfo.AllowedNssaiList {
+		for _, allowedSnssai := range allowedNssaiElement.AllowedSnssaiList {
+			allowedNssai = append(allowedNssai, *allowedSnssai.AllowedSnssai)
+		}
+	}
+	if len(allowedNssai) == 0 {
+		explanation.record(ExplainStageAmfSet, false, "No S-NSSAI is allowed, no AMF is selected")
+		return
+	}
+
+	if amfSetConfig, found := factory.NssfConfig.Snapshot().FindAmfSetServingNssai(tai, allowedNssai); found {
+		explanation.record(ExplainStageAmfSet, true, "AMF set %s supports all Allowed S-NSSAIs in TA %s",
+			amfSetConfig.AmfSetId, explainTai(tai))
+		return
+	}
+	explanation.record(ExplainStageAmfSet, false, "No AMF set supports all Allowed S-NSSAIs in TA %s", explainTai(tai))
+	explanation.record(ExplainStageAmf, len(authorizedNetworkSliceInfo.CandidateAmfList) != 0,
+		"Candidate AMFs supporting all Allowed S-NSSAIs in TA %s: %v", explainTai(tai),
+		authorizedNetworkSliceInfo.CandidateAmfList)
+}
+
+func explainPlmnId(plmnId *models.PlmnId) string {
+	if plmnId == nil {
+		return "<nil>"
+	}
+	return plmnId.Mcc + plmnId.Mnc
+}
+
+func explainTai(tai models.Tai) string {
+	if tai.Nid != "" {
+		return fmt.Sprintf("%s/%s/%s", explainPlmnId(tai.PlmnId), tai.Tac, tai.Nid)
+	}
+	return fmt.Sprintf("%s/%s", explainPlmnId(tai.PlmnId), tai.Tac)
+}
@@ -0,0 +1,122 @@
+package processor_test
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+func TestNSSelectionExplain(t *testing.T) {
+	mockNssfApp := app.NewMockNssfApp(gomock.NewController(t))
+	p := processor.NewProcessor(mockNssfApp)
+
+	plmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	supportedTai := models.Tai{PlmnId: &plmnId, Tac: "000001"}
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+				{
+					PlmnId: &plmnId,
+					SupportedSnssaiList: []models.Snssai{
+						{Sst: 1, Sd: "010203"},
+						{Sst: 1, Sd: "112233"},
+					},
+				},
+			},
+			TaList: []factory.TaConfig{
+				{
+					Tai:                 &supportedTai,
+					SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "010203"}},
+				},
+			},
+		},
+	}
+
+	param := processor.NetworkSliceInformationGetQuery{
+		NfType: models.NrfNfManagementNfType_AMF,
+		NfId:   "469de254-2fe5-4ca0-8381-af3f500af77c",
+		SliceInfoRequestForRegistration: &models.SliceInfoForRegistration{
+			SubscribedNssai: []models.SubscribedSnssai{
+				{SubscribedSnssai: &models.Snssai{Sst: 1, Sd: "010203"}},
+				{SubscribedSnssai: &models.Snssai{Sst: 1, Sd: "112233"}},
+			},
+			RequestedNssai: []models.Snssai{
+				{Sst: 1, Sd: "010203"},
+				{Sst: 1, Sd: "112233"},
+			},
+		},
+		Tai: &supportedTai,
+	}
+
+	// Without the header, the response is Authorized Network Slice Info as is
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/network-slice-information", nil)
+	p.NSSelectionSliceInformationGet(c, param)
+	if contentType := httpRecorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/json") {
+		t.Fatalf("Expected JSON response, got: %s", contentType)
+	}
+
+	// With the header, the explanation is returned as a part along with Authorized Network Slice Info
+	httpRecorder = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(httpRecorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/network-slice-information", nil)
+	c.Request.Header.Set(processor.ExplainHeader, "true")
+	p.NSSelectionSliceInformationGet(c, param)
+	if httpRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(httpRecorder.Header().Get("Content-Type"))
+	if err != nil || mediaType != "multipart/related" {
+		t.Fatalf("Expected multipart/related response, got: %s", httpRecorder.Header().Get("Content-Type"))
+	}
+	reader := multipart.NewReader(httpRecorder.Body, params["boundary"])
+
+	var authorizedNetworkSliceInfo models.AuthorizedNetworkSliceInfo
+	var explanation processor.Explanation
+	for _, v := range []any{&authorizedNetworkSliceInfo, &explanation} {
+		part, err := reader.NextPart()
+		if err != nil {
+			t.Fatalf("Read part failed: %+v", err)
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("Read part failed: %+v", err)
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Fatalf("Decode part %s failed: %+v", part.Header.Get("Content-Id"), err)
+		}
+	}
+
+	if len(authorizedNetworkSliceInfo.RejectedNssaiInTa) != 1 {
+		t.Fatalf("Expected 1 S-NSSAI rejected in TA, got: %+v", authorizedNetworkSliceInfo.RejectedNssaiInTa)
+	}
+	if explanation.Procedure != processor.ExplainProcedureRegistration {
+		t.Errorf("Expected procedure %s, got: %s", processor.ExplainProcedureRegistration, explanation.Procedure)
+	}
+
+	// The rejection in TA is explained
+	explained := false
+	for _, step := range explanation.Steps {
+		if step.Stage == processor.ExplainStageTa && !step.Passed && step.Snssai != nil && step.Snssai.Sd == "112233" {
+			explained = true
+		}
+	}
+	if !explained {
+		t.Errorf("Expected rejection in TA to be explained, got: %+v", explanation.Steps)
+	}
+}
@@ -0,0 +1,101 @@
+package processor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/mock/gomock"
+
+	"github.com/free5gc/nssf/internal/metrics/business"
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+	"github.com/free5gc/util/metrics/utils"
+)
+
+func TestNSSelectionMetricsLabels(t *testing.T) {
+	business.GetBusinessMetrics("free5gc")
+	utils.EnableBusinessMetrics()
+
+	p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+
+	plmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	supportedTai := models.Tai{PlmnId: &plmnId, Tac: "000001"}
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+				{
+					PlmnId: &plmnId,
+					SupportedSnssaiList: []models.Snssai{
+						{Sst: 1, Sd: "010203"},
+						{Sst: 1, Sd: "112233"},
+					},
+				},
+			},
+			TaList: []factory.TaConfig{
+				{
+					Tai:                 &supportedTai,
+					SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "010203"}},
+				},
+			},
+		},
+	}
+
+	// S-NSSAIs requested by the consumer with arbitrary SDs are rejected in PLMN
+	var requestedNssai []models.Snssai
+	var subscribedNssai []models.SubscribedSnssai
+	for _, snssai := range []models.Snssai{
+		{Sst: 1, Sd: "010203"},
+		{Sst: 1, Sd: "112233"},
+		{Sst: 1, Sd: "f00001"},
+		{Sst: 1, Sd: "f00002"},
+	} {
+		requestedNssai = append(requestedNssai, snssai)
+		subscribedNssai = append(subscribedNssai, models.SubscribedSnssai{SubscribedSnssai: &snssai})
+	}
+	param := processor.NetworkSliceInformationGetQuery{
+		NfType: models.NrfNfManagementNfType_AMF,
+		NfId:   "469de254-2fe5-4ca0-8381-af3f500af77c",
+		SliceInfoRequestForRegistration: &models.SliceInfoForRegistration{
+			SubscribedNssai: subscribedNssai,
+			RequestedNssai:  requestedNssai,
+		},
+		Tai: &supportedTai,
+	}
+
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/network-slice-information", nil)
+	p.NSSelectionSliceInformationGet(c, param)
+	if httpRecorder.Code != http.StatusOK {
+		t.Fatalf("Expected status code %d, got: %d", http.StatusOK, httpRecorder.Code)
+	}
+
+	testCases := []struct {
+		outcome string
+		snssai  string
+		expect  float64
+	}{
+		{business.OutcomeAllowed, "1-010203", 1},
+		{business.OutcomeRejectedInTa, "1-112233", 1},
+		{business.OutcomeRejectedInPlmn, business.OTHER_LABEL_VALUE, 2},
+	}
+	for _, tc := range testCases {
+		value := testutil.ToFloat64(business.NsselectionSnssaiCounter.With(prometheus.Labels{
+			business.OUTCOME_LABEL: tc.outcome,
+			business.SNSSAI_LABEL:  tc.snssai,
+			business.PLMN_ID_LABEL: "20893",
+		}))
+		if value != tc.expect {
+			t.Errorf("Expected %v S-NSSAIs %s %s, got: %v", tc.expect, tc.snssai, tc.outcome, value)
+		}
+	}
+	if count := testutil.CollectAndCount(business.NsselectionSnssaiCounter); count != len(testCases) {
+		t.Errorf("Expected only configured S-NSSAIs to be labeled in %d series, got: %d", len(testCases), count)
+	}
+}
@@ -10,14 +10,22 @@
 package processor
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/metrics/business"
+	"github.com/free5gc/nssf/internal/nsac"
+	"github.com/free5gc/nssf/internal/tracing"
 	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/factory"
 	"github.com/free5gc/openapi"
 	"github.com/free5gc/openapi/models"
 	"github.com/free5gc/util/metrics/sbi"
@@ -41,6 +49,40 @@ type NetworkSliceInformationGetQuery struct {
 	HomePlmnId        *models.PlmnId `form:"home-plmn-id" binding:"required_without=Tai,omitempty"`
 	Tai               *models.Tai    `form:"tai" binding:"required_without=HomePlmnId,omitempty"`
 	SupportedFeatures string         `form:"supported-features"`
+
+	// Identities of the UE and of the request, which identify holders of network slices admitted by
+	// Network Slice Admission Control, and are not bound from the query
+	ueId      string
+	requestId string
+}
+
+// Header of correlation information defined in TS 29.500, e.g. "imsi-208930000000001" identifying the UE
+const correlationInfoHeader = "3gpp-Sbi-Correlation-Info"
+
+// Get the UE identity in correlation information, which is the first of the comma-separated entries
+// "<type>-<value>", or empty if the UE is not identified
+func ueIdFromCorrelationInfo(correlationInfo string) string {
+	for _, entry := range strings.Split(correlationInfo, ",") {
+		entry = strings.TrimSpace(entry)
+		if ctype, cvalue, ok := strings.Cut(entry, "-"); ok && ctype != "" && cvalue != "" {
+			return entry
+		}
+	}
+	return ""
+}
+
+// Holder of network slices admitted to the UE, which is the request itself if the UE is not identified
+func (param NetworkSliceInformationGetQuery) ueHolder() nsac.Holder {
+	if param.ueId != "" {
+		return nsac.Holder{NfId: param.NfId, Id: param.ueId}
+	}
+	return nsac.Holder{NfId: param.NfId, Id: param.requestId}
+}
+
+// Holder of the network slice admitted to the PDU session, which is the request itself
+// since PDU sessions are not identified in network slice selection
+func (param NetworkSliceInformationGetQuery) pduSessionHolder() nsac.Holder {
+	return nsac.Holder{NfId: param.NfId, Id: param.requestId}
 }
 
 // Check if the NF service consumer is authorized
@@ -81,6 +123,25 @@ func (p *Processor) NSSelectionSliceInformationGet(
 		return
 	}
 
+	negotiatedFeatures, err := util.NegotiateSupportedFeatures(models.ServiceName_NNSSF_NSSELECTION,
+		param.SupportedFeatures)
+	if err != nil {
+		problemDetails = &models.ProblemDetails{
+			Title:  util.INVALID_REQUEST,
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+			InvalidParams: []models.InvalidParam{
+				{
+					Param:  "supported-features",
+					Reason: err.Error(),
+				},
+			},
+		}
+		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Title)
+		util.GinProblemJson(c, problemDetails)
+		return
+	}
+
 	if param.Tai == nil && param.HomePlmnId == nil {
 		problemDetails = &models.ProblemDetails{
 			Title:  util.MANDATORY_IE_MISSING,
@@ -101,12 +162,26 @@ func (p *Processor) NSSelectionSliceInformationGet(
 		return
 	}
 
+	param.ueId = ueIdFromCorrelationInfo(c.GetHeader(correlationInfoHeader))
+	param.requestId = uuid.New().String()
+
+	// Decisions are recorded if the explanation is requested by the consumer or explain mode is enabled
+	ctx := tracing.Context(c)
+	explainRequested := isExplainRequested(c)
+	var explanation *Explanation
+	if explainRequested || p.ExplainMode() {
+		explanation = &Explanation{}
+		ctx = withExplanation(ctx, explanation)
+	}
+
 	if param.SliceInfoRequestForRegistration != nil {
 		// Network slice information is requested during the Registration procedure
-		status, response, problemDetails = nsselectionForRegistration(param)
+		explanation.setProcedure(ExplainProcedureRegistration)
+		status, response, problemDetails = p.nsselectionForRegistration(ctx, param)
 	} else if param.SliceInfoRequestForPduSession != nil {
 		// Network slice information is requested during the PDU session establishment procedure
-		status, response, problemDetails = nsselectionForPduSession(param)
+		explanation.setProcedure(ExplainProcedurePduSession)
+		status, response, problemDetails = p.nsselectionForPduSession(ctx, param)
 	} else {
 		problemDetails = &models.ProblemDetails{
 			Title:  util.MANDATORY_IE_MISSING,
@@ -126,6 +201,10 @@ func (p *Processor) NSSelectionSliceInformationGet(
 
 	// TODO: Handle `SliceInfoRequestForUeConfigurationUpdate`
 
+	if explanation != nil {
+		logExplanation(explanation)
+	}
+
 	if problemDetails != nil {
 		c.Set(sbi.IN_PB_DETAILS_CTX_STR, problemDetails.Cause)
 		util.GinProblemJson(c, problemDetails)
@@ -141,13 +220,182 @@ func (p *Processor) NSSelectionSliceInformationGet(
 		return
 	}
 
+	recordNsselectionMetrics(param, response)
+	applyNsselSupportedFeatures(negotiatedFeatures, response)
+
+	if explainRequested {
+		explainedJson(c, status, response, explanation)
+		return
+	}
 	c.JSON(status, response)
 }
 
+// Record outcomes of network slice selection in business metrics
+// S-NSSAIs are counted per serving PLMN, or per Home PLMN if UE's current TA is unknown
+// S-NSSAIs and PLMNs given by consumers are labeled only if they are configured, and counted as other otherwise
+func recordNsselectionMetrics(param NetworkSliceInformationGetQuery, response *models.AuthorizedNetworkSliceInfo) {
+	snapshot := factory.NssfConfig.Snapshot()
+	plmnId := param.HomePlmnId
+	if param.Tai != nil {
+		plmnId = param.Tai.PlmnId
+	}
+	if plmnId != nil && !snapshot.IsPlmnConfigured(*plmnId) {
+		plmnId = nil
+	}
+	configuredSnssai := func(snssai models.Snssai) *models.Snssai {
+		if util.CheckStandardSnssai(snssai) || snapshot.IsSnssaiConfigured(snssai) {
+			return &snssai
+		}
+		return nil
+	}
+
+	// An S-NSSAI allowed in several Access Types is counted once
+	var allowedNssai []models.Snssai
+	for _, allowedNssaiElement := range response.AllowedNssaiList {
+		for _, allowedSnssai := range allowedNssaiElement.AllowedSnssaiList {
+			if allowedSnssai.AllowedSnssai == nil || util.Contain(*allowedSnssai.AllowedSnssai, allowedNssai) {
+				continue
+			}
+			allowedNssai = append(allowedNssai, *allowedSnssai.AllowedSnssai)
+			business.IncrNsselectionSnssaiCounter(business.OutcomeAllowed,
+				configuredSnssai(*allowedSnssai.AllowedSnssai), plmnId)
+			for _, nsiInformation := range allowedSnssai.NsiInformationList {
+				business.IncrNsselectionNsiCounter(nsiInformation.NsiId)
+			}
+		}
+	}
+	for _, snssai := range response.RejectedNssaiInTa {
+		business.IncrNsselectionSnssaiCounter(business.OutcomeRejectedInTa, configuredSnssai(snssai), plmnId)
+	}
+	for _, snssai := range response.RejectedNssaiInPlmn {
+		business.IncrNsselectionSnssaiCounter(business.OutcomeRejectedInPlmn, configuredSnssai(snssai), plmnId)
+	}
+	if response.NsiInformation != nil {
+		business.IncrNsselectionNsiCounter(response.NsiInformation.NsiId)
+	}
+
+	if param.SliceInfoRequestForRegistration != nil && len(response.AllowedNssaiList) != 0 {
+		business.ObserveNsselectionCandidateAmfListSize(len(response.CandidateAmfList))
+	}
+}
+
+// Check whether a UE could be admitted to the S-NSSAI with Network Slice Admission Control
+// If the quota of the S-NSSAI is reached, it is added to Rejected NSSAI instead
+func (p *Processor) admitUe(holder nsac.Holder,
+	snssai models.Snssai, tai *models.Tai, authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo,
+) bool {
+	if p.admission == nil {
+		return true
+	}
+
+	result, err := p.admission.AdmitUe(holder, snssai, tai)
+	return handleAdmissionResult(snssai, result, err, authorizedNetworkSliceInfo)
+}
+
+// Check whether a PDU session could be admitted to the S-NSSAI with Network Slice Admission Control
+// If the quota of the S-NSSAI is reached, it is added to Rejected NSSAI instead
+func (p *Processor) admitPduSession(holder nsac.Holder,
+	snssai models.Snssai, tai *models.Tai, authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo,
+) bool {
+	if p.admission == nil {
+		return true
+	}
+
+	result, err := p.admission.AdmitPduSession(holder, snssai, tai)
+	return handleAdmissionResult(snssai, result, err, authorizedNetworkSliceInfo)
+}
+
+func handleAdmissionResult(snssai models.Snssai, result nsac.Result, err error,
+	authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo,
+) bool {
+	if err != nil {
+		// Do not block the UE if the admission control is not available
+		logger.NsselLog.Warnf("Network slice admission control of S-NSSAI %+v failed: %+v", snssai, err)
+		return true
+	}
+
+	if result.Admitted {
+		return true
+	}
+
+	logger.NsselLog.Infof("S-NSSAI %+v is rejected by network slice admission control: %s", snssai, result.Cause)
+	switch result.Scope {
+	case nsac.ScopeTa:
+		if !util.Contain(snssai, authorizedNetworkSliceInfo.RejectedNssaiInTa) {
+			authorizedNetworkSliceInfo.RejectedNssaiInTa = append(authorizedNetworkSliceInfo.RejectedNssaiInTa, snssai)
+		}
+	default:
+		if !util.Contain(snssai, authorizedNetworkSliceInfo.RejectedNssaiInPlmn) {
+			authorizedNetworkSliceInfo.RejectedNssaiInPlmn = append(authorizedNetworkSliceInfo.RejectedNssaiInPlmn,
+				snssai)
+		}
+	}
+	return false
+}
+
+// Check whether UE's Home PLMN and current TA are supported
+// If not, the requested S-NSSAIs are rejected as a whole
+func checkServingArea(ctx context.Context, param NetworkSliceInformationGetQuery, requestedNssai []models.Snssai,
+	authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo,
+) bool {
+	_, span := tracing.Start(ctx, "nsselection.checkServingArea")
+	defer span.End()
+	explanation := explanationFrom(ctx)
+
+	if param.HomePlmnId != nil {
+		// Check whether UE's Home PLMN is supported when UE is a roamer
+		if !util.CheckSupportedHplmn(*param.HomePlmnId) {
+			authorizedNetworkSliceInfo.RejectedNssaiInPlmn = append(
+				authorizedNetworkSliceInfo.RejectedNssaiInPlmn,
+				requestedNssai...)
+			span.SetAttributes(attribute.String("nssf.rejected", "home_plmn"))
+			explanation.record(ExplainStageHomePlmn, false,
+				"No S-NSSAI mapping of Home PLMN %s is configured, all requested S-NSSAIs are rejected in PLMN",
+				explainPlmnId(param.HomePlmnId))
+			return false
+		}
+		explanation.record(ExplainStageHomePlmn, true, "S-NSSAI mappings of Home PLMN %s are configured",
+			explainPlmnId(param.HomePlmnId))
+	}
+
+	if param.Tai != nil {
+		// Check whether UE's current TA is supported when UE provides TAI
+		if !util.CheckSupportedTa(*param.Tai) {
+			authorizedNetworkSliceInfo.RejectedNssaiInTa = append(
+				authorizedNetworkSliceInfo.RejectedNssaiInTa,
+				requestedNssai...)
+			span.SetAttributes(attribute.String("nssf.rejected", "ta"))
+			explanation.record(ExplainStageTa, false,
+				"TA %s is not in taList, all requested S-NSSAIs are rejected in TA", explainTai(*param.Tai))
+			return false
+		}
+		explanation.record(ExplainStageTa, true, "TA %s is in taList", explainTai(*param.Tai))
+	}
+
+	return true
+}
+
+// Get Access Type(s) of the Allowed S-NSSAI
+// If UE's Access Type could not be identified, i.e. no TAI is provided or the S-NSSAI is supported in multiple
+// Access Types at UE's current TA, the Access Type(s) are decided by operator policy
+func getAccessTypeList(param NetworkSliceInformationGetQuery, snssai models.Snssai) []models.AccessType {
+	if param.Tai == nil {
+		return util.GetUnknownAccessTypeList(nil)
+	}
+
+	accessTypeList := util.GetAccessTypeListFromConfig(snssai, *param.Tai)
+	if len(accessTypeList) == 1 {
+		return accessTypeList
+	}
+	return util.GetUnknownAccessTypeList(accessTypeList)
+}
+
 // Set Allowed NSSAI with Subscribed S-NSSAI(s) which are marked as default S-NSSAI(s)
-func useDefaultSubscribedSnssai(
+func (p *Processor) useDefaultSubscribedSnssai(ctx context.Context,
 	param NetworkSliceInformationGetQuery, authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo,
 ) {
+	explanation := explanationFrom(ctx)
+
 	var mappingOfSnssai []models.MappingOfSnssai
 	if param.HomePlmnId != nil {
 		// Find mapping of Subscribed S-NSSAI of UE's HPLMN to S-NSSAI in Serving PLMN from NSSF configuration
@@ -155,6 +403,8 @@ func useDefaultSubscribedSnssai(
 
 		if mappingOfSnssai == nil {
 			logger.NsselLog.Warnf("No S-NSSAI mapping of UE's HPLMN %+v in NSSF configuration", *param.HomePlmnId)
+			explanation.record(ExplainStageMapping, false, "No S-NSSAI mapping is configured for Home PLMN %s",
+				explainPlmnId(param.HomePlmnId))
 			return
 		}
 	}
@@ -172,17 +422,33 @@ func useDefaultSubscribedSnssai(
 					logger.NsselLog.Warnf("No mapping of Subscribed S-NSSAI %+v in PLMN %+v in NSSF configuration",
 						*subscribedSnssai.SubscribedSnssai,
 						*param.HomePlmnId)
+					explanation.recordSnssai(ExplainStageMapping, *subscribedSnssai.SubscribedSnssai, false,
+						"No mapping of default Subscribed S-NSSAI is configured for Home PLMN %s",
+						explainPlmnId(param.HomePlmnId))
 					continue
 				} else {
 					mappingOfSubscribedSnssai = *targetMapping.ServingSnssai
+					explanation.recordSnssai(ExplainStageMapping, *subscribedSnssai.SubscribedSnssai, true,
+						"Default Subscribed S-NSSAI is mapped to serving S-NSSAI %+v", mappingOfSubscribedSnssai)
 				}
 			} else {
 				mappingOfSubscribedSnssai = *subscribedSnssai.SubscribedSnssai
 			}
 
 			if param.Tai != nil && !util.CheckSupportedSnssaiInTa(mappingOfSubscribedSnssai, *param.Tai) {
+				explanation.recordSnssai(ExplainStageTa, mappingOfSubscribedSnssai, false,
+					"Default Subscribed S-NSSAI is not supported in TA %s", explainTai(*param.Tai))
+				continue
+			}
+			explainRestrictedSnssai(explanation, param, mappingOfSubscribedSnssai)
+
+			if !p.admitUe(param.ueHolder(), mappingOfSubscribedSnssai, param.Tai, authorizedNetworkSliceInfo) {
+				explanation.recordSnssai(ExplainStageAdmission, mappingOfSubscribedSnssai, false,
+					"Default Subscribed S-NSSAI is rejected by network slice admission control")
 				continue
 			}
+			explanation.recordSnssai(ExplainStageSubscription, mappingOfSubscribedSnssai, true,
+				"Default Subscribed S-NSSAI is allowed")
 
 			var allowedSnssaiElement models.AllowedSnssai
 			allowedSnssaiElement.AllowedSnssai = new(models.Snssai)
@@ -199,15 +465,8 @@ func useDefaultSubscribedSnssai(
 				*allowedSnssaiElement.MappedHomeSnssai = *subscribedSnssai.SubscribedSnssai
 			}
 
-			// Default Access Type is set to 3GPP Access if no TAI is provided
-			// TODO: Depend on operator implementation, it may also return S-NSSAIs in all valid Access Type if
-			//       UE's Access Type could not be identified
-			accessType := models.AccessType__3_GPP_ACCESS
-			if param.Tai != nil {
-				accessType = util.GetAccessTypeFromConfig(*param.Tai)
-			}
-
-			util.AddAllowedSnssai(allowedSnssaiElement, accessType, authorizedNetworkSliceInfo)
+			accessTypeList := getAccessTypeList(param, *allowedSnssaiElement.AllowedSnssai)
+			util.AddAllowedSnssai(allowedSnssaiElement, accessTypeList, authorizedNetworkSliceInfo)
 		}
 	}
 }
@@ -288,35 +547,58 @@ func setConfiguredNssai(
 	}
 }
 
-// Network slice selection for registration
-// The function is executed when the IE, `slice-info-request-for-registration`, is provided in query parameters
-func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
-	int, *models.AuthorizedNetworkSliceInfo, *models.ProblemDetails,
+// Set Configured NSSAI with S-NSSAI(s) supported in the Serving PLMN
+// Used when no subscription information is available, e.g. during PDU session establishment
+func deriveConfiguredNssaiInPlmn(
+	homePlmnId *models.PlmnId, servingPlmnId models.PlmnId, authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo,
 ) {
-	authorizedNetworkSliceInfo := &models.AuthorizedNetworkSliceInfo{}
-	var status int
-	if param.HomePlmnId != nil {
-		// Check whether UE's Home PLMN is supported when UE is a roamer
-		if !util.CheckSupportedHplmn(*param.HomePlmnId) {
-			authorizedNetworkSliceInfo.RejectedNssaiInPlmn = append(
-				authorizedNetworkSliceInfo.RejectedNssaiInPlmn,
-				param.SliceInfoRequestForRegistration.RequestedNssai...)
+	var mappingOfSnssai []models.MappingOfSnssai
+	if homePlmnId != nil {
+		// Find mapping of S-NSSAI in Serving PLMN to S-NSSAI of UE's HPLMN from NSSF configuration
+		mappingOfSnssai = util.GetMappingOfPlmnFromConfig(*homePlmnId)
 
-			status = http.StatusOK
-			return status, authorizedNetworkSliceInfo, nil
+		if mappingOfSnssai == nil {
+			logger.NsselLog.Warnf("No S-NSSAI mapping of UE's HPLMN %+v in NSSF configuration", *homePlmnId)
+			return
 		}
 	}
 
-	if param.Tai != nil {
-		// Check whether UE's current TA is supported when UE provides TAI
-		if !util.CheckSupportedTa(*param.Tai) {
-			authorizedNetworkSliceInfo.RejectedNssaiInTa = append(
-				authorizedNetworkSliceInfo.RejectedNssaiInTa,
-				param.SliceInfoRequestForRegistration.RequestedNssai...)
+	for _, supportedSnssai := range util.GetSupportedSnssaiListInPlmnFromConfig(servingPlmnId) {
+		var configuredSnssai models.ConfiguredSnssai
+		configuredSnssai.ConfiguredSnssai = new(models.Snssai)
+		*configuredSnssai.ConfiguredSnssai = supportedSnssai
 
-			status = http.StatusOK
-			return status, authorizedNetworkSliceInfo, nil
+		if homePlmnId != nil && !util.CheckStandardSnssai(supportedSnssai) {
+			// Non-standard S-NSSAIs are only usable by the roamer if there is a mapping to its HPLMN
+			targetMapping, found := util.FindMappingWithServingSnssai(supportedSnssai, mappingOfSnssai)
+			if !found {
+				continue
+			}
+			configuredSnssai.MappedHomeSnssai = new(models.Snssai)
+			*configuredSnssai.MappedHomeSnssai = *targetMapping.HomeSnssai
 		}
+
+		authorizedNetworkSliceInfo.ConfiguredNssai = append(
+			authorizedNetworkSliceInfo.ConfiguredNssai,
+			configuredSnssai)
+	}
+}
+
+// Network slice selection for registration
+// The function is executed when the IE, `slice-info-request-for-registration`, is provided in query parameters
+func (p *Processor) nsselectionForRegistration(ctx context.Context, param NetworkSliceInformationGetQuery) (
+	int, *models.AuthorizedNetworkSliceInfo, *models.ProblemDetails,
+) {
+	ctx, span := tracing.Start(ctx, "nsselection.registration")
+	defer span.End()
+	explanation := explanationFrom(ctx)
+
+	authorizedNetworkSliceInfo := &models.AuthorizedNetworkSliceInfo{}
+	var status int
+	if !checkServingArea(ctx, param, param.SliceInfoRequestForRegistration.RequestedNssai,
+		authorizedNetworkSliceInfo) {
+		status = http.StatusOK
+		return status, authorizedNetworkSliceInfo, nil
 	}
 
 	if param.SliceInfoRequestForRegistration.SubscribedNssai != nil {
@@ -366,6 +648,8 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 		// mapped S-NSSAI values for the S-NSSAI values in `subscribedNssai`. But also `sNssaiForMapping` shall be
 		// provided if `requestMapping` is set to true. In the implementation, the NSSF would return mapped S-NSSAIs
 		// for S-NSSAIs in both `sNssaiForMapping` and `subscribedSnssai` if present
+		_, mappingSpan := tracing.Start(ctx, "nsselection.mapping")
+		defer mappingSpan.End()
 
 		if param.HomePlmnId == nil {
 			detail := "[Query Parameter] `home-plmn-id` should be provided" +
@@ -392,6 +676,8 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 			// Find mappings for S-NSSAIs in `subscribedSnssai`
 			for _, subscribedSnssai := range param.SliceInfoRequestForRegistration.SubscribedNssai {
 				if util.CheckStandardSnssai(*subscribedSnssai.SubscribedSnssai) {
+					explanation.recordSnssai(ExplainStageMapping, *subscribedSnssai.SubscribedSnssai, true,
+						"Subscribed S-NSSAI is standard, no mapping is needed")
 					continue
 				}
 
@@ -401,8 +687,12 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 					logger.NsselLog.Warnf("No mapping of Subscribed S-NSSAI %+v in PLMN %+v in NSSF configuration",
 						*subscribedSnssai.SubscribedSnssai,
 						*param.HomePlmnId)
+					explanation.recordSnssai(ExplainStageMapping, *subscribedSnssai.SubscribedSnssai, false,
+						"No mapping of Subscribed S-NSSAI is configured for Home PLMN %s", explainPlmnId(param.HomePlmnId))
 					continue
 				} else {
+					explanation.recordSnssai(ExplainStageMapping, *subscribedSnssai.SubscribedSnssai, true,
+						"Subscribed S-NSSAI is mapped to serving S-NSSAI %+v", *targetMapping.ServingSnssai)
 					// Add mappings to Allowed NSSAI list
 					var allowedSnssaiElement models.AllowedSnssai
 					allowedSnssaiElement.AllowedSnssai = new(models.Snssai)
@@ -410,15 +700,8 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 					allowedSnssaiElement.MappedHomeSnssai = new(models.Snssai)
 					*allowedSnssaiElement.MappedHomeSnssai = *subscribedSnssai.SubscribedSnssai
 
-					// Default Access Type is set to 3GPP Access if no TAI is provided
-					// TODO: Depend on operator implementation, it may also return S-NSSAIs in all valid Access Type if
-					//       UE's Access Type could not be identified
-					accessType := models.AccessType__3_GPP_ACCESS
-					if param.Tai != nil {
-						accessType = util.GetAccessTypeFromConfig(*param.Tai)
-					}
-
-					util.AddAllowedSnssai(allowedSnssaiElement, accessType, authorizedNetworkSliceInfo)
+					accessTypeList := getAccessTypeList(param, *allowedSnssaiElement.AllowedSnssai)
+					util.AddAllowedSnssai(allowedSnssaiElement, accessTypeList, authorizedNetworkSliceInfo)
 				}
 			}
 
@@ -434,8 +717,12 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 					logger.NsselLog.Warnf("No mapping of Subscribed S-NSSAI %+v in PLMN %+v in NSSF configuration",
 						snssai,
 						*param.HomePlmnId)
+					explanation.recordSnssai(ExplainStageMapping, snssai, false,
+						"No mapping of S-NSSAI for mapping is configured for Home PLMN %s", explainPlmnId(param.HomePlmnId))
 					continue
 				} else {
+					explanation.recordSnssai(ExplainStageMapping, snssai, true,
+						"S-NSSAI for mapping is mapped to serving S-NSSAI %+v", *targetMapping.ServingSnssai)
 					// Add mappings to Allowed NSSAI list
 					var allowedSnssaiElement models.AllowedSnssai
 					allowedSnssaiElement.AllowedSnssai = new(models.Snssai)
@@ -443,15 +730,8 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 					allowedSnssaiElement.MappedHomeSnssai = new(models.Snssai)
 					*allowedSnssaiElement.MappedHomeSnssai = snssai
 
-					// Default Access Type is set to 3GPP Access if no TAI is provided
-					// TODO: Depend on operator implementation, it may also return S-NSSAIs in all valid Access Type if
-					//       UE's Access Type could not be identified
-					accessType := models.AccessType__3_GPP_ACCESS
-					if param.Tai != nil {
-						accessType = util.GetAccessTypeFromConfig(*param.Tai)
-					}
-
-					util.AddAllowedSnssai(allowedSnssaiElement, accessType, authorizedNetworkSliceInfo)
+					accessTypeList := getAccessTypeList(param, *allowedSnssaiElement.AllowedSnssai)
+					util.AddAllowedSnssai(allowedSnssaiElement, accessTypeList, authorizedNetworkSliceInfo)
 				}
 			}
 
@@ -459,45 +739,53 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 			return status, authorizedNetworkSliceInfo, nil
 		} else {
 			logger.NsselLog.Warnf("No S-NSSAI mapping of UE's HPLMN %+v in NSSF configuration", *param.HomePlmnId)
+			explanation.record(ExplainStageMapping, false, "No S-NSSAI mapping is configured for Home PLMN %s",
+				explainPlmnId(param.HomePlmnId))
 
 			status = http.StatusOK
 			return status, authorizedNetworkSliceInfo, nil
 		}
 	}
 
+	_, requestedSpan := tracing.Start(ctx, "nsselection.checkRequestedNssai")
 	checkInvalidRequestedNssai := false
 	if len(param.SliceInfoRequestForRegistration.RequestedNssai) != 0 {
 		// Requested NSSAI is provided
 		// Verify which S-NSSAI(s) in the Requested NSSAI are permitted based on comparing the Subscribed S-NSSAI(s)
 
-		if param.Tai != nil &&
-			!util.CheckSupportedNssaiInPlmn(param.SliceInfoRequestForRegistration.RequestedNssai, *param.Tai.PlmnId) {
-			// Return ProblemDetails indicating S-NSSAI is not supported
-			// TODO: Based on TS 23.501 V15.2.0, if the Requested NSSAI includes an S-NSSAI that is not valid in the
-			//       Serving PLMN, the NSSF may derive the Configured NSSAI for Serving PLMN
-			problemDetails := &models.ProblemDetails{
-				Title:  util.UNSUPPORTED_RESOURCE,
-				Status: http.StatusForbidden,
-				Detail: "S-NSSAI in Requested NSSAI is not supported in PLMN",
-				Cause:  "SNSSAI_NOT_SUPPORTED",
-			}
-
-			status = http.StatusForbidden
-			return status, nil, problemDetails
-		}
-
 		// Check if any Requested S-NSSAIs is present in Subscribed S-NSSAIs
 		checkIfRequestAllowed := false
 
 		for _, requestedSnssai := range param.SliceInfoRequestForRegistration.RequestedNssai {
+			if param.Tai != nil && !util.CheckSupportedSnssaiInPlmn(requestedSnssai, *param.Tai.PlmnId) {
+				// Based on TS 23.501 V15.2.0, if the Requested NSSAI includes an S-NSSAI that is not valid in the
+				// Serving PLMN, the NSSF may derive the Configured NSSAI for Serving PLMN
+				// Add it to Rejected NSSAI in PLMN, and the Configured NSSAI is determined below
+				checkInvalidRequestedNssai = true
+				authorizedNetworkSliceInfo.RejectedNssaiInPlmn = append(
+					authorizedNetworkSliceInfo.RejectedNssaiInPlmn,
+					requestedSnssai)
+				explanation.recordSnssai(ExplainStagePlmn, requestedSnssai, false,
+					"Requested S-NSSAI is not supported in serving PLMN %s, it is rejected in PLMN",
+					explainPlmnId(param.Tai.PlmnId))
+				continue
+			}
+
 			if param.Tai != nil && !util.CheckSupportedSnssaiInTa(requestedSnssai, *param.Tai) {
 				// Requested S-NSSAI does not supported in UE's current TA
 				// Add it to Rejected NSSAI in TA
 				authorizedNetworkSliceInfo.RejectedNssaiInTa = append(
 					authorizedNetworkSliceInfo.RejectedNssaiInTa,
 					requestedSnssai)
+				explanation.recordSnssai(ExplainStageTa, requestedSnssai, false,
+					"Requested S-NSSAI is not supported in TA %s, it is rejected in TA", explainTai(*param.Tai))
 				continue
 			}
+			if param.Tai != nil {
+				explanation.recordSnssai(ExplainStageTa, requestedSnssai, true,
+					"Requested S-NSSAI is supported in serving PLMN and TA %s", explainTai(*param.Tai))
+			}
+			explainRestrictedSnssai(explanation, param, requestedSnssai)
 
 			var mappingOfRequestedSnssai models.Snssai
 			// TODO: Compared with Restricted S-NSSAI list in configuration under roaming scenario
@@ -515,11 +803,15 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 					authorizedNetworkSliceInfo.RejectedNssaiInPlmn = append(
 						authorizedNetworkSliceInfo.RejectedNssaiInPlmn,
 						requestedSnssai)
+					explanation.recordSnssai(ExplainStageMapping, requestedSnssai, false,
+						"No mapping of Requested S-NSSAI is provided in mappingOfNssai, it is rejected in PLMN")
 					continue
 				} else {
 					// TODO: Check if mappings of S-NSSAIs are correct
 					//       If not, update UE's Configured NSSAI
 					mappingOfRequestedSnssai = *targetMapping.HomeSnssai
+					explanation.recordSnssai(ExplainStageMapping, requestedSnssai, true,
+						"Requested S-NSSAI is mapped to home S-NSSAI %+v by mappingOfNssai", mappingOfRequestedSnssai)
 				}
 			} else {
 				mappingOfRequestedSnssai = requestedSnssai
@@ -531,6 +823,14 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 					// Requested S-NSSAI matches one of Subscribed S-NSSAI
 					// Add it to Allowed NSSAI list
 					hitSubscription = true
+					explanation.recordSnssai(ExplainStageSubscription, requestedSnssai, true,
+						"Requested S-NSSAI matches Subscribed S-NSSAI %+v", *subscribedSnssai.SubscribedSnssai)
+
+					if !p.admitUe(param.ueHolder(), requestedSnssai, param.Tai, authorizedNetworkSliceInfo) {
+						explanation.recordSnssai(ExplainStageAdmission, requestedSnssai, false,
+							"Requested S-NSSAI is rejected by network slice admission control")
+						break
+					}
 
 					var allowedSnssaiElement models.AllowedSnssai
 					allowedSnssaiElement.AllowedSnssai = new(models.Snssai)
@@ -547,15 +847,8 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 						*allowedSnssaiElement.MappedHomeSnssai = *subscribedSnssai.SubscribedSnssai
 					}
 
-					// Default Access Type is set to 3GPP Access if no TAI is provided
-					// TODO: Depend on operator implementation, it may also return S-NSSAIs in all valid Access Type if
-					//       UE's Access Type could not be identified
-					accessType := models.AccessType__3_GPP_ACCESS
-					if param.Tai != nil {
-						accessType = util.GetAccessTypeFromConfig(*param.Tai)
-					}
-
-					util.AddAllowedSnssai(allowedSnssaiElement, accessType, authorizedNetworkSliceInfo)
+					accessTypeList := getAccessTypeList(param, *allowedSnssaiElement.AllowedSnssai)
+					util.AddAllowedSnssai(allowedSnssaiElement, accessTypeList, authorizedNetworkSliceInfo)
 
 					checkIfRequestAllowed = true
 					break
@@ -569,24 +862,46 @@ func nsselectionForRegistration(param NetworkSliceInformationGetQuery) (
 				authorizedNetworkSliceInfo.RejectedNssaiInPlmn = append(
 					authorizedNetworkSliceInfo.RejectedNssaiInPlmn,
 					requestedSnssai)
+				explanation.recordSnssai(ExplainStageSubscription, requestedSnssai, false,
+					"Requested S-NSSAI matches no Subscribed S-NSSAI, it is rejected in PLMN")
 			}
 		}
 
 		if !checkIfRequestAllowed {
 			// No S-NSSAI from Requested NSSAI is present in Subscribed S-NSSAIs
 			// Subscribed S-NSSAIs marked as default are used
-			useDefaultSubscribedSnssai(param, authorizedNetworkSliceInfo)
+			explanation.record(ExplainStageSubscription, false,
+				"No Requested S-NSSAI is allowed, default Subscribed S-NSSAIs are used")
+			p.useDefaultSubscribedSnssai(ctx, param, authorizedNetworkSliceInfo)
 		}
 	} else {
 		// No Requested NSSAI is provided
 		// Subscribed S-NSSAIs marked as default are used
 		checkInvalidRequestedNssai = true
-		useDefaultSubscribedSnssai(param, authorizedNetworkSliceInfo)
+		explanation.record(ExplainStageSubscription, true,
+			"No Requested NSSAI is provided, default Subscribed S-NSSAIs are used")
+		p.useDefaultSubscribedSnssai(ctx, param, authorizedNetworkSliceInfo)
 	}
+	requestedSpan.SetAttributes(
+		attribute.Int("nssf.rejected_nssai_in_plmn", len(authorizedNetworkSliceInfo.RejectedNssaiInPlmn)),
+		attribute.Int("nssf.rejected_nssai_in_ta", len(authorizedNetworkSliceInfo.RejectedNssaiInTa)),
+	)
+	requestedSpan.End()
 
-	if param.Tai != nil &&
-		!util.CheckAllowedNssaiInAmfTa(authorizedNetworkSliceInfo.AllowedNssaiList, param.NfId, *param.Tai) {
-		util.AddAmfInformation(*param.Tai, authorizedNetworkSliceInfo)
+	if param.Tai != nil {
+		_, amfSpan := tracing.Start(ctx, "nsselection.selectAmf")
+		if !util.CheckAllowedNssaiInAmfTa(authorizedNetworkSliceInfo.AllowedNssaiList, param.NfId, *param.Tai) {
+			explanation.record(ExplainStageAmf, false,
+				"Requesting AMF %s does not support all Allowed S-NSSAIs in TA %s", param.NfId, explainTai(*param.Tai))
+			util.AddAmfInformation(*param.Tai, authorizedNetworkSliceInfo)
+			explainAmfSelection(explanation, *param.Tai, authorizedNetworkSliceInfo)
+		} else {
+			explanation.record(ExplainStageAmf, true,
+				"Requesting AMF %s supports all Allowed S-NSSAIs in TA %s", param.NfId, explainTai(*param.Tai))
+		}
+		amfSpan.SetAttributes(attribute.Int("nssf.candidate_amf_count",
+			len(authorizedNetworkSliceInfo.CandidateAmfList)))
+		amfSpan.End()
 	}
 
 	if param.SliceInfoRequestForRegistration.DefaultConfiguredSnssaiInd {
@@ -618,9 +933,13 @@ func selectNsiInformation(nsiInformationList []models.NsiInformation) models.Nsi
 
 // Network slice selection for PDU session
 // The function is executed when the IE, `slice-info-for-pdu-session`, is provided in query parameters
-func nsselectionForPduSession(param NetworkSliceInformationGetQuery) (
+func (p *Processor) nsselectionForPduSession(ctx context.Context, param NetworkSliceInformationGetQuery) (
 	int, *models.AuthorizedNetworkSliceInfo, *models.ProblemDetails,
 ) {
+	ctx, span := tracing.Start(ctx, "nsselection.pduSession")
+	defer span.End()
+	explanation := explanationFrom(ctx)
+
 	var status int
 	authorizedNetworkSliceInfo := &models.AuthorizedNetworkSliceInfo{}
 
@@ -641,45 +960,25 @@ func nsselectionForPduSession(param NetworkSliceInformationGetQuery) (
 		return status, nil, problemDetails
 	}
 
-	if param.HomePlmnId != nil {
-		// Check whether UE's Home PLMN is supported when UE is a roamer
-		if !util.CheckSupportedHplmn(*param.HomePlmnId) {
-			authorizedNetworkSliceInfo.RejectedNssaiInPlmn = append(
-				authorizedNetworkSliceInfo.RejectedNssaiInPlmn,
-				*param.SliceInfoRequestForPduSession.SNssai)
-
-			status = http.StatusOK
-			return status, authorizedNetworkSliceInfo, nil
-		}
-	}
-
-	if param.Tai != nil {
-		// Check whether UE's current TA is supported when UE provides TAI
-		if !util.CheckSupportedTa(*param.Tai) {
-			authorizedNetworkSliceInfo.RejectedNssaiInTa = append(
-				authorizedNetworkSliceInfo.RejectedNssaiInTa,
-				*param.SliceInfoRequestForPduSession.SNssai)
-
-			status = http.StatusOK
-			return status, authorizedNetworkSliceInfo, nil
-		}
+	if !checkServingArea(ctx, param, []models.Snssai{*param.SliceInfoRequestForPduSession.SNssai},
+		authorizedNetworkSliceInfo) {
+		status = http.StatusOK
+		return status, authorizedNetworkSliceInfo, nil
 	}
 
 	if param.Tai != nil &&
 		!util.CheckSupportedSnssaiInPlmn(*param.SliceInfoRequestForPduSession.SNssai, *param.Tai.PlmnId) {
-		// Return ProblemDetails indicating S-NSSAI is not supported
-		// TODO: Based on TS 23.501 V15.2.0, if the Requested NSSAI includes an S-NSSAI that is not valid in the
-		//       Serving PLMN, the NSSF may derive the Configured NSSAI for Serving PLMN
-
-		problemDetails := &models.ProblemDetails{
-			Title:  util.UNSUPPORTED_RESOURCE,
-			Status: http.StatusForbidden,
-			Detail: "S-NSSAI in Requested NSSAI is not supported in PLMN",
-			Cause:  "SNSSAI_NOT_SUPPORTED",
-		}
+		// Based on TS 23.501 V15.2.0, if the Requested NSSAI includes an S-NSSAI that is not valid in the
+		// Serving PLMN, the NSSF may derive the Configured NSSAI for Serving PLMN
+		authorizedNetworkSliceInfo.RejectedNssaiInPlmn = append(
+			authorizedNetworkSliceInfo.RejectedNssaiInPlmn,
+			*param.SliceInfoRequestForPduSession.SNssai)
+		explanation.recordSnssai(ExplainStagePlmn, *param.SliceInfoRequestForPduSession.SNssai, false,
+			"S-NSSAI is not supported in serving PLMN %s, it is rejected in PLMN", explainPlmnId(param.Tai.PlmnId))
+		deriveConfiguredNssaiInPlmn(param.HomePlmnId, *param.Tai.PlmnId, authorizedNetworkSliceInfo)
 
-		status = http.StatusForbidden
-		return status, nil, problemDetails
+		status = http.StatusOK
+		return status, authorizedNetworkSliceInfo, nil
 	}
 
 	if param.HomePlmnId != nil {
@@ -727,20 +1026,46 @@ func nsselectionForPduSession(param NetworkSliceInformationGetQuery) (
 		authorizedNetworkSliceInfo.RejectedNssaiInTa = append(
 			authorizedNetworkSliceInfo.RejectedNssaiInTa,
 			*param.SliceInfoRequestForPduSession.SNssai)
+		explanation.recordSnssai(ExplainStageTa, *param.SliceInfoRequestForPduSession.SNssai, false,
+			"S-NSSAI is not supported in TA %s, it is rejected in TA", explainTai(*param.Tai))
 		status = http.StatusOK
 		return status, authorizedNetworkSliceInfo, nil
 	}
+	if param.Tai != nil {
+		explanation.recordSnssai(ExplainStageTa, *param.SliceInfoRequestForPduSession.SNssai, true,
+			"S-NSSAI is supported in serving PLMN and TA %s", explainTai(*param.Tai))
+	}
+	explainRestrictedSnssai(explanation, param, *param.SliceInfoRequestForPduSession.SNssai)
 
+	_, nsiSpan := tracing.Start(ctx, "nsselection.selectNsi")
 	nsiInformationList := util.GetNsiInformationListFromConfig(*param.SliceInfoRequestForPduSession.SNssai)
 
 	if len(nsiInformationList) == 0 {
+		nsiSpan.End()
 		*authorizedNetworkSliceInfo = models.AuthorizedNetworkSliceInfo{}
-	} else {
-		nsiInformation := selectNsiInformation(nsiInformationList)
-		authorizedNetworkSliceInfo.NsiInformation = new(models.NsiInformation)
-		*authorizedNetworkSliceInfo.NsiInformation = nsiInformation
+		explanation.recordSnssai(ExplainStageNsi, *param.SliceInfoRequestForPduSession.SNssai, false,
+			"No NSI is configured for S-NSSAI")
+		logger.NsselLog.Infof("authorizedNetworkSliceInfo: %+v", authorizedNetworkSliceInfo)
+		return http.StatusOK, authorizedNetworkSliceInfo, nil
+	}
+	nsiInformation := selectNsiInformation(nsiInformationList)
+	nsiSpan.SetAttributes(attribute.String("nssf.nsi_id", nsiInformation.NsiId))
+	nsiSpan.End()
+
+	// The PDU session is admitted only once the selection succeeds, so that failed selections hold no quota
+	if !p.admitPduSession(param.pduSessionHolder(), *param.SliceInfoRequestForPduSession.SNssai, param.Tai,
+		authorizedNetworkSliceInfo) {
+		explanation.recordSnssai(ExplainStageAdmission, *param.SliceInfoRequestForPduSession.SNssai, false,
+			"S-NSSAI is rejected by network slice admission control")
+		status = http.StatusOK
+		return status, authorizedNetworkSliceInfo, nil
 	}
 
+	authorizedNetworkSliceInfo.NsiInformation = new(models.NsiInformation)
+	*authorizedNetworkSliceInfo.NsiInformation = nsiInformation
+	explanation.recordSnssai(ExplainStageNsi, *param.SliceInfoRequestForPduSession.SNssai, true,
+		"NSI %s is selected among %d NSIs configured for S-NSSAI", nsiInformation.NsiId, len(nsiInformationList))
+
 	logger.NsselLog.Infof("authorizedNetworkSliceInfo: %+v", authorizedNetworkSliceInfo)
 
 	return http.StatusOK, authorizedNetworkSliceInfo, nil
@@ -0,0 +1,392 @@
+package processor_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+
+	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+var (
+	nsselectionPlmnId     = models.PlmnId{Mcc: "208", Mnc: "93"}
+	nsselectionHomePlmnId = models.PlmnId{Mcc: "466", Mnc: "92"}
+	nsselectionTai        = models.Tai{PlmnId: &nsselectionPlmnId, Tac: "000001"}
+)
+
+func setNsselectionConfig() {
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+				{
+					PlmnId: &nsselectionPlmnId,
+					SupportedSnssaiList: []models.Snssai{
+						{Sst: 1, Sd: "010203"},
+						{Sst: 1, Sd: "112233"},
+					},
+				},
+			},
+			TaList: []factory.TaConfig{
+				{
+					Tai: &nsselectionTai,
+					SupportedSnssaiList: []models.ExtSnssai{
+						{Sst: 1, Sd: "010203"},
+						{Sst: 1, Sd: "112233"},
+					},
+				},
+			},
+			MappingListFromPlmn: []factory.MappingFromPlmnConfig{
+				{
+					HomePlmnId: &nsselectionHomePlmnId,
+					MappingOfSnssai: []models.MappingOfSnssai{
+						{
+							ServingSnssai: &models.Snssai{Sst: 1, Sd: "010203"},
+							HomeSnssai:    &models.Snssai{Sst: 1, Sd: "000001"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Run NSSelection Get, and decode Authorized Network Slice Info or Problem Details by the status
+func getNetworkSliceInformation(t *testing.T, p *processor.Processor, param processor.NetworkSliceInformationGetQuery) (
+	int, *models.AuthorizedNetworkSliceInfo, *models.ProblemDetails,
+) {
+	httpRecorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(httpRecorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/network-slice-information", nil)
+	p.NSSelectionSliceInformationGet(c, param)
+
+	if httpRecorder.Code != http.StatusOK {
+		var problemDetails models.ProblemDetails
+		if err := json.Unmarshal(httpRecorder.Body.Bytes(), &problemDetails); err != nil {
+			t.Fatalf("Error unmarshalling response body: %v", err)
+		}
+		return httpRecorder.Code, nil, &problemDetails
+	}
+	var authorizedNetworkSliceInfo models.AuthorizedNetworkSliceInfo
+	if err := json.Unmarshal(httpRecorder.Body.Bytes(), &authorizedNetworkSliceInfo); err != nil {
+		t.Fatalf("Error unmarshalling response body: %v", err)
+	}
+	return httpRecorder.Code, &authorizedNetworkSliceInfo, nil
+}
+
+func snssaiString(snssai models.Snssai) string {
+	if snssai.Sd == "" {
+		return fmt.Sprintf("%d", snssai.Sst)
+	}
+	return fmt.Sprintf("%d/%s", snssai.Sst, snssai.Sd)
+}
+
+// Configured S-NSSAIs as strings, along with their mapped home S-NSSAIs if any
+func configuredNssaiStrings(configuredNssai []models.ConfiguredSnssai) []string {
+	var s []string
+	for _, configuredSnssai := range configuredNssai {
+		v := snssaiString(*configuredSnssai.ConfiguredSnssai)
+		if configuredSnssai.MappedHomeSnssai != nil {
+			v += "->" + snssaiString(*configuredSnssai.MappedHomeSnssai)
+		}
+		s = append(s, v)
+	}
+	return s
+}
+
+func snssaiStrings(nssai []models.Snssai) []string {
+	var s []string
+	for _, snssai := range nssai {
+		s = append(s, snssaiString(snssai))
+	}
+	return s
+}
+
+func TestNSSelectionConfiguredNssai(t *testing.T) {
+	p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+	setNsselectionConfig()
+
+	testCases := []struct {
+		name                string
+		param               processor.NetworkSliceInformationGetQuery
+		expectRejectedPlmn  []string
+		expectConfigured    []string
+		expectAllowedSnssai []string
+	}{
+		{
+			name: "Registration requesting S-NSSAI not valid in serving PLMN",
+			param: processor.NetworkSliceInformationGetQuery{
+				SliceInfoRequestForRegistration: &models.SliceInfoForRegistration{
+					SubscribedNssai: []models.SubscribedSnssai{
+						{SubscribedSnssai: &models.Snssai{Sst: 1, Sd: "010203"}},
+						{SubscribedSnssai: &models.Snssai{Sst: 1, Sd: "112233"}},
+						{SubscribedSnssai: &models.Snssai{Sst: 1, Sd: "ffffff"}},
+					},
+					RequestedNssai: []models.Snssai{
+						{Sst: 1, Sd: "010203"},
+						{Sst: 1, Sd: "ffffff"},
+					},
+				},
+			},
+			expectRejectedPlmn:  []string{"1/ffffff"},
+			expectConfigured:    []string{"1/010203", "1/112233"},
+			expectAllowedSnssai: []string{"1/010203"},
+		},
+		{
+			name: "Registration requesting S-NSSAIs valid in serving PLMN",
+			param: processor.NetworkSliceInformationGetQuery{
+				SliceInfoRequestForRegistration: &models.SliceInfoForRegistration{
+					SubscribedNssai: []models.SubscribedSnssai{
+						{SubscribedSnssai: &models.Snssai{Sst: 1, Sd: "010203"}},
+					},
+					RequestedNssai: []models.Snssai{{Sst: 1, Sd: "010203"}},
+				},
+			},
+			expectAllowedSnssai: []string{"1/010203"},
+		},
+		{
+			name: "PDU session of S-NSSAI not valid in serving PLMN",
+			param: processor.NetworkSliceInformationGetQuery{
+				SliceInfoRequestForPduSession: &models.SliceInfoForPduSession{
+					SNssai:            &models.Snssai{Sst: 1, Sd: "ffffff"},
+					RoamingIndication: models.RoamingIndication_NON_ROAMING,
+				},
+			},
+			expectRejectedPlmn: []string{"1/ffffff"},
+			expectConfigured:   []string{"1/010203", "1/112233"},
+		},
+		{
+			name: "PDU session of roamer with S-NSSAI not valid in serving PLMN",
+			param: processor.NetworkSliceInformationGetQuery{
+				SliceInfoRequestForPduSession: &models.SliceInfoForPduSession{
+					SNssai:            &models.Snssai{Sst: 1, Sd: "ffffff"},
+					RoamingIndication: models.RoamingIndication_LOCAL_BREAKOUT,
+				},
+				HomePlmnId: &nsselectionHomePlmnId,
+			},
+			expectRejectedPlmn: []string{"1/ffffff"},
+			// S-NSSAIs without mapping to the HPLMN are not configured for the roamer
+			expectConfigured: []string{"1/010203->1/000001"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.param.NfType = models.NrfNfManagementNfType_AMF
+			tc.param.NfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+			tc.param.Tai = &nsselectionTai
+
+			status, authorizedNetworkSliceInfo, problemDetails := getNetworkSliceInformation(t, p, tc.param)
+			if status != http.StatusOK {
+				t.Fatalf("Expected status code %d, got: %d, %+v", http.StatusOK, status, problemDetails)
+			}
+
+			if rejected := snssaiStrings(authorizedNetworkSliceInfo.RejectedNssaiInPlmn); !reflect.DeepEqual(
+				rejected, tc.expectRejectedPlmn) {
+				t.Errorf("Expected S-NSSAIs %v rejected in PLMN, got: %v", tc.expectRejectedPlmn, rejected)
+			}
+			if configured := configuredNssaiStrings(authorizedNetworkSliceInfo.ConfiguredNssai); !reflect.DeepEqual(
+				configured, tc.expectConfigured) {
+				t.Errorf("Expected Configured NSSAI %v, got: %v", tc.expectConfigured, configured)
+			}
+
+			var allowed []string
+			for _, allowedNssai := range authorizedNetworkSliceInfo.AllowedNssaiList {
+				for _, allowedSnssai := range allowedNssai.AllowedSnssaiList {
+					allowed = append(allowed, snssaiString(*allowedSnssai.AllowedSnssai))
+				}
+			}
+			if !reflect.DeepEqual(allowed, tc.expectAllowedSnssai) {
+				t.Errorf("Expected Allowed S-NSSAIs %v, got: %v", tc.expectAllowedSnssai, allowed)
+			}
+		})
+	}
+}
+
+func TestNSSelectionAccessTypes(t *testing.T) {
+	// Supported features of consumers supporting Allowed NSSAI in multiple access types
+	const multiAccessFeature = "4"
+
+	p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+	access3gpp := models.AccessType__3_GPP_ACCESS
+	accessNon3gpp := models.AccessType_NON_3_GPP_ACCESS
+
+	testCases := []struct {
+		name              string
+		policy            string
+		supportedFeatures string
+		tai               *models.Tai
+		homePlmnId        *models.PlmnId
+		requestedNssai    []models.Snssai
+		expectAllowed     map[models.AccessType][]string
+	}{
+		{
+			name:           "S-NSSAI supported in 3GPP access only",
+			policy:         factory.UnknownAccessTypeAllAccess,
+			tai:            &nsselectionTai,
+			requestedNssai: []models.Snssai{{Sst: 1, Sd: "112233"}},
+			expectAllowed:  map[models.AccessType][]string{access3gpp: {"1/112233"}},
+		},
+		{
+			name:           "S-NSSAI supported in both access types with 3GPP access policy",
+			policy:         factory.UnknownAccessType3gppAccess,
+			tai:            &nsselectionTai,
+			requestedNssai: []models.Snssai{{Sst: 1, Sd: "010203"}},
+			expectAllowed:  map[models.AccessType][]string{access3gpp: {"1/010203"}},
+		},
+		{
+			name:              "S-NSSAI supported in both access types with all access policy",
+			policy:            factory.UnknownAccessTypeAllAccess,
+			supportedFeatures: multiAccessFeature,
+			tai:               &nsselectionTai,
+			requestedNssai:    []models.Snssai{{Sst: 1, Sd: "010203"}, {Sst: 1, Sd: "112233"}},
+			expectAllowed: map[models.AccessType][]string{
+				access3gpp:    {"1/010203", "1/112233"},
+				accessNon3gpp: {"1/010203"},
+			},
+		},
+		{
+			// Allowed NSSAI of a single access type is returned to consumers not supporting multiple access types
+			name:           "S-NSSAI supported in both access types with all access policy to legacy consumer",
+			policy:         factory.UnknownAccessTypeAllAccess,
+			tai:            &nsselectionTai,
+			requestedNssai: []models.Snssai{{Sst: 1, Sd: "010203"}, {Sst: 1, Sd: "112233"}},
+			expectAllowed:  map[models.AccessType][]string{access3gpp: {"1/010203", "1/112233"}},
+		},
+		{
+			name:              "Without TAI with 3GPP access policy",
+			policy:            factory.UnknownAccessType3gppAccess,
+			supportedFeatures: multiAccessFeature,
+			homePlmnId:        &nsselectionHomePlmnId,
+			requestedNssai:    []models.Snssai{{Sst: 1}},
+			expectAllowed:     map[models.AccessType][]string{access3gpp: {"1"}},
+		},
+		{
+			name:              "Without TAI with all access policy",
+			policy:            factory.UnknownAccessTypeAllAccess,
+			supportedFeatures: multiAccessFeature,
+			homePlmnId:        &nsselectionHomePlmnId,
+			requestedNssai:    []models.Snssai{{Sst: 1}},
+			expectAllowed: map[models.AccessType][]string{
+				access3gpp:    {"1"},
+				accessNon3gpp: {"1"},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setNsselectionConfig()
+			factory.NssfConfig.Configuration.UnknownAccessTypePolicy = tc.policy
+			factory.NssfConfig.Configuration.TaList[0].AccessList = []factory.TaAccessConfig{
+				{
+					AccessType:          &access3gpp,
+					AccessNetwork:       factory.AccessNetworkNgRan,
+					SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "010203"}, {Sst: 1, Sd: "112233"}},
+				},
+				{
+					AccessType:          &accessNon3gpp,
+					AccessNetwork:       factory.AccessNetworkN3iwf,
+					SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "010203"}},
+				},
+			}
+
+			var subscribedNssai []models.SubscribedSnssai
+			for i := range tc.requestedNssai {
+				subscribedNssai = append(subscribedNssai, models.SubscribedSnssai{
+					SubscribedSnssai: &tc.requestedNssai[i],
+				})
+			}
+			param := processor.NetworkSliceInformationGetQuery{
+				NfType: models.NrfNfManagementNfType_AMF,
+				NfId:   "469de254-2fe5-4ca0-8381-af3f500af77c",
+				SliceInfoRequestForRegistration: &models.SliceInfoForRegistration{
+					SubscribedNssai: subscribedNssai,
+					RequestedNssai:  tc.requestedNssai,
+				},
+				Tai:               tc.tai,
+				HomePlmnId:        tc.homePlmnId,
+				SupportedFeatures: tc.supportedFeatures,
+			}
+
+			status, authorizedNetworkSliceInfo, problemDetails := getNetworkSliceInformation(t, p, param)
+			if status != http.StatusOK {
+				t.Fatalf("Expected status code %d, got: %d, %+v", http.StatusOK, status, problemDetails)
+			}
+
+			allowed := make(map[models.AccessType][]string)
+			for _, allowedNssai := range authorizedNetworkSliceInfo.AllowedNssaiList {
+				if _, ok := allowed[allowedNssai.AccessType]; ok {
+					t.Errorf("Expected one Allowed NSSAI per access type, got another of %s", allowedNssai.AccessType)
+				}
+				allowed[allowedNssai.AccessType] = []string{}
+				for _, allowedSnssai := range allowedNssai.AllowedSnssaiList {
+					allowed[allowedNssai.AccessType] = append(allowed[allowedNssai.AccessType],
+						snssaiString(*allowedSnssai.AllowedSnssai))
+				}
+			}
+			if !reflect.DeepEqual(allowed, tc.expectAllowed) {
+				t.Errorf("Expected Allowed NSSAI %v, got: %v", tc.expectAllowed, allowed)
+			}
+		})
+	}
+}
+
+func TestNSSelectionSupportedFeatures(t *testing.T) {
+	p := processor.NewProcessor(app.NewMockNssfApp(gomock.NewController(t)))
+	setNsselectionConfig()
+
+	testCases := []struct {
+		name              string
+		supportedFeatures string
+		status            int
+		expectFeatures    string
+	}{
+		{"Not indicated", "", http.StatusOK, ""},
+		{"Multiple access types", "4", http.StatusOK, "4"},
+		{"Features unknown to NSSF", "f8", http.StatusOK, ""},
+		{"All features", "ff", http.StatusOK, "7"},
+		{"Invalid hexadecimal digits", "g", http.StatusBadRequest, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			param := processor.NetworkSliceInformationGetQuery{
+				NfType: models.NrfNfManagementNfType_AMF,
+				NfId:   "469de254-2fe5-4ca0-8381-af3f500af77c",
+				SliceInfoRequestForRegistration: &models.SliceInfoForRegistration{
+					SubscribedNssai: []models.SubscribedSnssai{
+						{SubscribedSnssai: &models.Snssai{Sst: 1, Sd: "010203"}},
+					},
+					RequestedNssai: []models.Snssai{{Sst: 1, Sd: "010203"}},
+				},
+				Tai:               &nsselectionTai,
+				SupportedFeatures: tc.supportedFeatures,
+			}
+
+			status, authorizedNetworkSliceInfo, problemDetails := getNetworkSliceInformation(t, p, param)
+			if status != tc.status {
+				t.Fatalf("Expected status code %d, got: %d", tc.status, status)
+			}
+			if status != http.StatusOK {
+				if len(problemDetails.InvalidParams) != 1 || problemDetails.InvalidParams[0].Param != "supported-features" {
+					t.Errorf("Expected invalid supported-features, got: %+v", problemDetails.InvalidParams)
+				}
+				return
+			}
+			// Negotiated features are echoed back
+			if authorizedNetworkSliceInfo.SupportedFeatures != tc.expectFeatures {
+				t.Errorf("Expected supported features %q, got: %q", tc.expectFeatures,
+					authorizedNetworkSliceInfo.SupportedFeatures)
+			}
+		})
+	}
+}
@@ -1,11 +1,31 @@
 package processor
 
 import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/free5gc/nssf/internal/nsac"
 	"github.com/free5gc/nssf/pkg/app"
+	"github.com/free5gc/openapi/models"
 )
 
 type Processor struct {
 	app.NssfApp
+
+	// Network Slice Admission Control, nil if disabled
+	admission nsac.Controller
+	// Sender of NSSAI availability notifications, nil if notifications are not sent
+	notifier NssfEventNotifier
+	// Whether every network slice selection is explained in debug log
+	explainAll atomic.Bool
+	// Notifications being sent to subscribers
+	notifications sync.WaitGroup
+}
+
+// Sender of NSSAI availability notifications to subscribers
+type NssfEventNotifier interface {
+	SendNssfEventNotification(ctx context.Context, uri string, notification models.NssfEventNotification) error
 }
 
 func NewProcessor(nssf app.NssfApp) *Processor {
@@ -13,3 +33,35 @@ func NewProcessor(nssf app.NssfApp) *Processor {
 		NssfApp: nssf,
 	}
 }
+
+func (p *Processor) SetAdmissionController(admission nsac.Controller) {
+	p.admission = admission
+}
+
+func (p *Processor) SetNotifier(notifier NssfEventNotifier) {
+	p.notifier = notifier
+}
+
+func (p *Processor) SetExplainMode(enable bool) {
+	p.explainAll.Store(enable)
+}
+
+func (p *Processor) ExplainMode() bool {
+	return p.explainAll.Load()
+}
+
+// Wait until pending notifications are sent, or ctx is done
+func (p *Processor) WaitNotifications(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.notifications.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
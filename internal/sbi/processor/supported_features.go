@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+)
+
+// Remove optional IEs of Authorized Network Slice Info which are not supported by the NF service consumer,
+// and echo back the negotiated supported features
+func applyNsselSupportedFeatures(
+	negotiatedFeatures openapi.SupportedFeature, authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo,
+) {
+	if !negotiatedFeatures.GetFeature(util.NsselFeatureMultiAccess) &&
+		len(authorizedNetworkSliceInfo.AllowedNssaiList) > 1 {
+		// Only keep the Allowed NSSAI of a single Access Type, preferably 3GPP Access
+		allowedNssai := authorizedNetworkSliceInfo.AllowedNssaiList[0]
+		for _, a := range authorizedNetworkSliceInfo.AllowedNssaiList {
+			if a.AccessType == models.AccessType__3_GPP_ACCESS {
+				allowedNssai = a
+				break
+			}
+		}
+		authorizedNetworkSliceInfo.AllowedNssaiList = []models.AllowedNssai{allowedNssai}
+	}
+
+	if !negotiatedFeatures.GetFeature(util.NsselFeatureNSAG) {
+		authorizedNetworkSliceInfo.NsagInfos = nil
+	}
+
+	authorizedNetworkSliceInfo.SupportedFeatures = util.FormatSupportedFeatures(negotiatedFeatures)
+}
+
+// Remove optional IEs of authorized NSSAI availability data which are not supported by the NF service consumer
+// The returned list is a copy which is safe to modify
+func applyNssaiavailSupportedFeatures(
+	negotiatedFeatures openapi.SupportedFeature,
+	authorizedNssaiAvailabilityDataList []models.AuthorizedNssaiAvailabilityData,
+) []models.AuthorizedNssaiAvailabilityData {
+	if authorizedNssaiAvailabilityDataList == nil {
+		return nil
+	}
+
+	sdRange := negotiatedFeatures.GetFeature(util.NssaiavailFeatureSdRange)
+	nsag := negotiatedFeatures.GetFeature(util.NssaiavailFeatureNSAG)
+
+	result := make([]models.AuthorizedNssaiAvailabilityData, 0, len(authorizedNssaiAvailabilityDataList))
+	for _, a := range authorizedNssaiAvailabilityDataList {
+		if !sdRange {
+			supportedSnssaiList := make([]models.ExtSnssai, 0, len(a.SupportedSnssaiList))
+			for _, snssai := range a.SupportedSnssaiList {
+				if snssai.WildcardSd || (snssai.Sd == "" && len(snssai.SdRanges) != 0) {
+					// The S-NSSAI could not be represented without SD ranges
+					continue
+				}
+				supportedSnssaiList = append(supportedSnssaiList, models.ExtSnssai{
+					Sst: snssai.Sst,
+					Sd:  snssai.Sd,
+				})
+			}
+			a.SupportedSnssaiList = supportedSnssaiList
+		}
+		if !nsag {
+			a.NsagInfos = nil
+		}
+		result = append(result, a)
+	}
+	return result
+}
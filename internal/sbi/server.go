@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
-	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/free5gc/nssf/internal/health"
 	"github.com/free5gc/nssf/internal/logger"
 	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/internal/tracing"
 	"github.com/free5gc/nssf/internal/util"
 	"github.com/free5gc/nssf/pkg/app"
 	"github.com/free5gc/nssf/pkg/factory"
@@ -31,13 +32,18 @@ type Server struct {
 
 	httpServer *http.Server
 	router     *gin.Engine
-	processor  *processor.Processor
+	// Listener of management API for operators, nil if management is disabled
+	managementServer *http.Server
+	processor        *processor.Processor
+	// Requests being handled, which are checked by liveness probe
+	inflight *health.InflightTracker
 }
 
-func NewServer(nssf nssfApp, tlsKeyLogPath string) *Server {
+func NewServer(nssf nssfApp, tlsKeyLogPath string) (*Server, error) {
 	s := &Server{
 		nssfApp:   nssf,
 		processor: nssf.Processor(),
+		inflight:  health.NewInflightTracker(),
 	}
 
 	s.router = newRouter(s)
@@ -47,10 +53,23 @@ func NewServer(nssf nssfApp, tlsKeyLogPath string) *Server {
 
 	if err != nil {
 		logger.SBILog.Errorf("bind Router Error: %+v", err)
-		panic("Server initialization failed")
+		return nil, fmt.Errorf("server initialization failed: %w", err)
 	}
 
-	return s
+	if s.Config().IsManagementEnabled() {
+		token, err := util.LoadManagementToken(s.Config().GetManagementTokenFile())
+		if err != nil {
+			return nil, err
+		}
+		s.managementServer, err = httpwrapper.NewHttp2Server(s.Config().GetManagementBindingAddr(), tlsKeyLogPath,
+			newManagementRouter(s, token))
+		if err != nil {
+			logger.SBILog.Errorf("bind management Router Error: %+v", err)
+			return nil, fmt.Errorf("management server initialization failed: %w", err)
+		}
+	}
+
+	return s, nil
 }
 
 func (s *Server) Processor() *processor.Processor {
@@ -64,31 +83,59 @@ func (s *Server) Run(wg *sync.WaitGroup) {
 	go func() {
 		defer wg.Done()
 
-		err := s.serve()
+		err := s.serve(s.httpServer)
 		if err != http.ErrServerClosed {
 			logger.SBILog.Panicf("HTTP server setup failed: %+v", err)
 		}
 	}()
-}
 
-func (s *Server) Shutdown() {
-	s.shutdownHttpServer()
+	if s.managementServer != nil {
+		logger.SBILog.Infof("Starting management server on %s...", s.managementServer.Addr)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := s.serve(s.managementServer)
+			if err != http.ErrServerClosed {
+				logger.SBILog.Panicf("Management server setup failed: %+v", err)
+			}
+		}()
+	}
 }
 
-func (s *Server) shutdownHttpServer() {
-	const shutdownTimeout time.Duration = 2 * time.Second
+// Stop accepting requests, and wait for in-flight requests until ctx is done
+// Connections which are still active when ctx is done are closed
+func (s *Server) Shutdown(ctx context.Context) error {
+	if s.managementServer != nil {
+		if err := s.managementServer.Shutdown(ctx); err != nil {
+			logger.SBILog.Warnf("Management server shutdown failed: %+v", err)
+			if closeErr := s.managementServer.Close(); closeErr != nil {
+				logger.SBILog.Errorf("Management server close failed: %+v", closeErr)
+			}
+		}
+	}
 
 	if s.httpServer == nil {
-		return
+		return nil
 	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
-	defer cancel()
-
-	err := s.httpServer.Shutdown(shutdownCtx)
+	err := s.httpServer.Shutdown(ctx)
+	if err == nil {
+		// Connections upgraded to HTTP/2 without TLS are not tracked by HTTP server, so requests are waited for here
+		err = s.inflight.Wait(ctx)
+	}
 	if err != nil {
-		logger.SBILog.Errorf("HTTP server shutdown failed: %+v", err)
+		if closeErr := s.httpServer.Close(); closeErr != nil {
+			logger.SBILog.Errorf("HTTP server close failed: %+v", closeErr)
+		}
 	}
+	return err
+}
+
+// Get the number of requests being handled
+func (s *Server) InflightRequests() int {
+	return s.inflight.Count()
 }
 
 func bindRouter(nssf app.NssfApp, router *gin.Engine, tlsKeyLogPath string) (*http.Server, error) {
@@ -100,7 +147,15 @@ func bindRouter(nssf app.NssfApp, router *gin.Engine, tlsKeyLogPath string) (*ht
 
 func newRouter(s *Server) *gin.Engine {
 	router := logger_util.NewGinWithLogrus(logger.GinLog)
+
+	// Probes are added ahead of other middlewares, so that they are not traced, measured or tracked
+	healthGroup := router.Group("")
+	healthRoutes := s.getHealthRoutes()
+	AddService(healthGroup, healthRoutes)
+
+	router.Use(tracing.Middleware())
 	router.Use(metrics.InboundMetrics())
+	router.Use(s.inflight.Middleware())
 
 	for _, serviceName := range s.Config().Configuration.ServiceNameList {
 		switch serviceName {
@@ -127,14 +182,35 @@ func newRouter(s *Server) *gin.Engine {
 		}
 	}
 
+	// Notifications from NRF are sent without access token, so no oauth middleware is applied,
+	// and they are authenticated by the token in the notification URI given to NRF instead
+	callbackGroup := router.Group(factory.NssfCallbackResUriPrefix)
+	callbackRoutes := s.getCallbackRoutes()
+	AddService(callbackGroup, callbackRoutes)
+
+	return router
+}
+
+// Management API is for operators, so it is served on its own listener which NF service consumers
+// are not supposed to reach, and every request is authenticated with the bearer token of operators
+func newManagementRouter(s *Server, token string) *gin.Engine {
+	router := logger_util.NewGinWithLogrus(logger.GinLog)
+
+	managementGroup := router.Group(factory.NssfManagementResUriPrefix)
+	managementAuthorizationCheck := util.NewManagementAuthorizationCheck(token)
+	managementGroup.Use(managementAuthorizationCheck.Check)
+	managementRoutes := s.getManagementRoutes()
+	AddService(managementGroup, managementRoutes)
+
 	return router
 }
 
-func (s *Server) unsecureServe() error {
-	return s.httpServer.ListenAndServe()
+func (s *Server) unsecureServe(httpServer *http.Server) error {
+	return httpServer.ListenAndServe()
 }
 
-func (s *Server) secureServe() error {
+// Serve with the certificate and key of SBI, which are also used by management server
+func (s *Server) secureServe(httpServer *http.Server) error {
 	sbiConfig := s.Config().Configuration.Sbi
 
 	pemPath := sbiConfig.Tls.Pem
@@ -147,17 +223,17 @@ func (s *Server) secureServe() error {
 		keyPath = factory.NssfDefaultPrivateKeyPath
 	}
 
-	return s.httpServer.ListenAndServeTLS(pemPath, keyPath)
+	return httpServer.ListenAndServeTLS(pemPath, keyPath)
 }
 
-func (s *Server) serve() error {
+func (s *Server) serve(httpServer *http.Server) error {
 	sbiConfig := s.Config().Configuration.Sbi
 
 	switch sbiConfig.Scheme {
 	case "http":
-		return s.unsecureServe()
+		return s.unsecureServe(httpServer)
 	case "https":
-		return s.secureServe()
+		return s.secureServe(httpServer)
 	default:
 		return fmt.Errorf("invalid SBI scheme: %s", sbiConfig.Scheme)
 	}
@@ -0,0 +1,38 @@
+// Package testutil provides NSSF configuration fixtures shared by tests and benchmarks of several packages
+package testutil
+
+import (
+	"fmt"
+
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+// Set up NSSF configuration with the given number of TAs in the PLMN, where the PLMN and every TA support
+// the S-NSSAIs, and return the TAIs in the order of TaList
+func SetupTaListConfig(plmnId models.PlmnId, supportedSnssaiList []models.ExtSnssai, taCount int) []models.Tai {
+	configuration := &factory.Configuration{
+		SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+			{PlmnId: &plmnId},
+		},
+	}
+	for _, snssai := range supportedSnssaiList {
+		configuration.SupportedNssaiInPlmnList[0].SupportedSnssaiList = append(
+			configuration.SupportedNssaiInPlmnList[0].SupportedSnssaiList,
+			models.Snssai{Sst: snssai.Sst, Sd: snssai.Sd})
+	}
+
+	taiList := make([]models.Tai, taCount)
+	for i := range taiList {
+		taiList[i] = models.Tai{PlmnId: &plmnId, Tac: fmt.Sprintf("%06x", i+1)}
+		configuration.TaList = append(configuration.TaList, factory.TaConfig{
+			Tai:                 &taiList[i],
+			SupportedSnssaiList: supportedSnssaiList,
+		})
+	}
+
+	factory.NssfConfig = &factory.Config{
+		Configuration: configuration,
+	}
+	return taiList
+}
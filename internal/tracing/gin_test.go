@@ -0,0 +1,160 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/free5gc/nssf/pkg/factory"
+)
+
+func TestMiddleware(t *testing.T) {
+	if _, err := Init(factory.Tracing{}, ""); err != nil {
+		t.Fatalf("Init failed: %+v", err)
+	}
+
+	const (
+		callerTraceId = "4bf92f3577b34da6a3ce929d0e0e4736"
+		callerSpanId  = "00f067aa0ba902b7"
+	)
+
+	router := gin.New()
+	router.Use(Middleware())
+	var handlerSpan trace.SpanContext
+	router.GET("/nnssf-nsselection/v2/network-slice-information", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(Context(c))
+		c.Status(http.StatusOK)
+	})
+	router.DELETE("/nnssf-nssaiavailability/v1/nssai-availability/:nfId", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		traceparent  string
+		expectName   string
+		expectRoute  string
+		expectStatus int
+		expectError  bool
+	}{
+		{
+			name:         "Trace continued from caller",
+			method:       http.MethodGet,
+			path:         "/nnssf-nsselection/v2/network-slice-information?nf-type=AMF",
+			traceparent:  "00-" + callerTraceId + "-" + callerSpanId + "-01",
+			expectName:   "GET /nnssf-nsselection/v2/network-slice-information",
+			expectRoute:  "/nnssf-nsselection/v2/network-slice-information",
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "Trace started without caller",
+			method:       http.MethodGet,
+			path:         "/nnssf-nsselection/v2/network-slice-information",
+			expectName:   "GET /nnssf-nsselection/v2/network-slice-information",
+			expectRoute:  "/nnssf-nsselection/v2/network-slice-information",
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "Span named after route",
+			method:       http.MethodDelete,
+			path:         "/nnssf-nssaiavailability/v1/nssai-availability/amf-1",
+			expectName:   "DELETE /nnssf-nssaiavailability/v1/nssai-availability/:nfId",
+			expectRoute:  "/nnssf-nssaiavailability/v1/nssai-availability/:nfId",
+			expectStatus: http.StatusInternalServerError,
+			expectError:  true,
+		},
+		{
+			name:         "Unknown route",
+			method:       http.MethodGet,
+			path:         "/unknown",
+			expectName:   "GET",
+			expectStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ended := len(recorder.Ended())
+			handlerSpan = trace.SpanContext{}
+
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.traceparent != "" {
+				req.Header.Set("traceparent", tc.traceparent)
+			}
+			rsp := httptest.NewRecorder()
+			router.ServeHTTP(rsp, req)
+
+			spans := recorder.Ended()[ended:]
+			if len(spans) != 1 {
+				t.Fatalf("Expected 1 span, got: %d", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tc.expectName {
+				t.Errorf("Expected span name %q, got: %q", tc.expectName, span.Name())
+			}
+			if span.SpanKind() != trace.SpanKindServer {
+				t.Errorf("Expected server span, got: %s", span.SpanKind())
+			}
+			if handlerSpan.IsValid() && handlerSpan.SpanID() != span.SpanContext().SpanID() {
+				t.Errorf("Expected span of the request in the context of handler")
+			}
+
+			if tc.traceparent != "" {
+				if span.SpanContext().TraceID().String() != callerTraceId {
+					t.Errorf("Expected trace %s of caller, got: %s", callerTraceId, span.SpanContext().TraceID())
+				}
+				if span.Parent().SpanID().String() != callerSpanId || !span.Parent().IsRemote() {
+					t.Errorf("Expected span of caller %s as parent, got: %s", callerSpanId, span.Parent().SpanID())
+				}
+			} else if span.Parent().IsValid() {
+				t.Errorf("Expected root span, got parent: %s", span.Parent().SpanID())
+			}
+
+			attrs := attributesOf(span)
+			if attrs[semconv.HTTPRouteKey] != attribute.StringValue(tc.expectRoute) {
+				t.Errorf("Expected route %q, got: %v", tc.expectRoute, attrs[semconv.HTTPRouteKey].Emit())
+			}
+			if attrs[semconv.HTTPResponseStatusCodeKey] != attribute.IntValue(tc.expectStatus) {
+				t.Errorf("Expected status %d, got: %v", tc.expectStatus, attrs[semconv.HTTPResponseStatusCodeKey].Emit())
+			}
+			if (span.Status().Code == codes.Error) != tc.expectError {
+				t.Errorf("Expected span failed: %v, got status: %v", tc.expectError, span.Status())
+			}
+		})
+	}
+}
+
+func attributesOf(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+// The propagator set up by Init injects the span of outbound requests
+func TestInjectTraceContext(t *testing.T) {
+	if _, err := Init(factory.Tracing{}, ""); err != nil {
+		t.Fatalf("Init failed: %+v", err)
+	}
+	ctx, span := Start(t.Context(), "test.inject")
+	defer span.End()
+
+	header := make(http.Header)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+	expect := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	if header.Get("traceparent") != expect {
+		t.Errorf("Expected traceparent %q, got: %q", expect, header.Get("traceparent"))
+	}
+}
@@ -0,0 +1,163 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/free5gc/nssf/pkg/factory"
+)
+
+// Recorder of spans started by tracer
+// The tracer of NSSF delegates to the first tracer provider set globally, so it is set before any test
+var recorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	os.Exit(m.Run())
+}
+
+func TestNewExporter(t *testing.T) {
+	dir := t.TempDir()
+
+	testCases := []struct {
+		name         string
+		cfg          factory.Tracing
+		expectErr    bool
+		expectCloser bool
+		check        func(exporter sdktrace.SpanExporter) bool
+	}{
+		{
+			name: "OTLP exporter",
+			cfg:  factory.Tracing{Exporter: factory.TracingExporterOtlp, Endpoint: "127.0.0.1:4318", Insecure: true},
+			check: func(exporter sdktrace.SpanExporter) bool {
+				_, ok := exporter.(*otlptrace.Exporter)
+				return ok
+			},
+		},
+		{
+			name:         "File exporter",
+			cfg:          factory.Tracing{Exporter: factory.TracingExporterFile, File: filepath.Join(dir, "spans.json")},
+			expectCloser: true,
+			check: func(exporter sdktrace.SpanExporter) bool {
+				_, ok := exporter.(*stdouttrace.Exporter)
+				return ok
+			},
+		},
+		{
+			name: "Stdout exporter",
+			cfg:  factory.Tracing{Exporter: factory.TracingExporterStdout},
+			check: func(exporter sdktrace.SpanExporter) bool {
+				_, ok := exporter.(*stdouttrace.Exporter)
+				return ok
+			},
+		},
+		{
+			name:      "File exporter with file not writable",
+			cfg:       factory.Tracing{Exporter: factory.TracingExporterFile, File: filepath.Join(dir, "none", "spans.json")},
+			expectErr: true,
+		},
+		{
+			name:      "Unsupported exporter",
+			cfg:       factory.Tracing{Exporter: "jaeger"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			exporter, closer, err := newExporter(tc.cfg)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected error, got exporter: %T", exporter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected exporter, got error: %+v", err)
+			}
+			defer func() {
+				if shutdownErr := exporter.Shutdown(context.Background()); shutdownErr != nil {
+					t.Errorf("Shutdown exporter failed: %+v", shutdownErr)
+				}
+				if closer != nil {
+					if closeErr := closer.Close(); closeErr != nil {
+						t.Errorf("Close exporter failed: %+v", closeErr)
+					}
+				}
+			}()
+			if !tc.check(exporter) {
+				t.Errorf("Unexpected exporter: %T", exporter)
+			}
+			if (closer != nil) != tc.expectCloser {
+				t.Errorf("Expected closer: %v, got: %v", tc.expectCloser, closer)
+			}
+		})
+	}
+}
+
+func TestInit(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		provider := otel.GetTracerProvider()
+		shutdown, err := Init(factory.Tracing{Enable: false, Exporter: "jaeger"}, "")
+		if err != nil {
+			t.Fatalf("Expected no exporter to be created, got: %+v", err)
+		}
+		if err = shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown failed: %+v", err)
+		}
+		if otel.GetTracerProvider() != provider {
+			t.Errorf("Expected tracer provider to be kept")
+		}
+		fields := otel.GetTextMapPropagator().Fields()
+		if !slices.Contains(fields, "traceparent") {
+			t.Errorf("Expected W3C Trace Context to be propagated, got fields: %v", fields)
+		}
+	})
+
+	t.Run("Unsupported exporter", func(t *testing.T) {
+		if _, err := Init(factory.Tracing{Enable: true, Exporter: "jaeger"}, ""); err == nil {
+			t.Errorf("Expected error of unsupported exporter")
+		}
+	})
+
+	t.Run("Spans exported to file", func(t *testing.T) {
+		provider := otel.GetTracerProvider()
+		defer otel.SetTracerProvider(provider)
+
+		file := filepath.Join(t.TempDir(), "spans.json")
+		shutdown, err := Init(factory.Tracing{
+			Enable:      true,
+			Exporter:    factory.TracingExporterFile,
+			File:        file,
+			SampleRatio: 1,
+		}, "b9a9ce1c-6c3b-4f44-9a3c-2b2d1d1c3a01")
+		if err != nil {
+			t.Fatalf("Init failed: %+v", err)
+		}
+		_, span := otel.Tracer(tracerName).Start(context.Background(), "test.export")
+		span.End()
+		if err = shutdown(context.Background()); err != nil {
+			t.Errorf("Shutdown failed: %+v", err)
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Read exported spans failed: %+v", err)
+		}
+		for _, expect := range []string{"test.export", serviceName, "b9a9ce1c-6c3b-4f44-9a3c-2b2d1d1c3a01"} {
+			if !strings.Contains(string(content), expect) {
+				t.Errorf("Expected %q in exported spans, got: %s", expect, content)
+			}
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package util
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+// NF Set ID of AMF set defined in TS 29.571, i.e. "set<Set ID>.region<Region ID>.amfset.5gc.mnc<MNC>.mcc<MCC>"
+// Without the set part, it identifies all AMF sets in the AMF region
+var amfNfSetIdPattern = regexp.MustCompile(
+	`^(?i)(?:set([0-9a-f]+)\.)?region([0-9a-f]+)\.amfset\.5gc\.mnc([0-9]{2,3})\.mcc([0-9]{3})$`)
+
+type amfSetIdentity struct {
+	setId    string
+	regionId string
+	mnc      string
+	mcc      string
+}
+
+func parseAmfNfSetId(amfSetId string) (amfSetIdentity, bool) {
+	m := amfNfSetIdPattern.FindStringSubmatch(amfSetId)
+	if m == nil {
+		return amfSetIdentity{}, false
+	}
+	return amfSetIdentity{
+		setId:    strings.ToLower(m[1]),
+		regionId: strings.ToLower(m[2]),
+		mnc:      m[3],
+		mcc:      m[4],
+	}, true
+}
+
+// Check whether the AMF set ID matches the target, which is an AMF set ID or an AMF region
+func MatchAmfSetId(target, amfSetId string) bool {
+	if target == "" || amfSetId == "" {
+		return false
+	}
+	if strings.EqualFold(target, amfSetId) {
+		return true
+	}
+
+	t, ok := parseAmfNfSetId(target)
+	if !ok || t.setId != "" {
+		return false
+	}
+	s, ok := parseAmfNfSetId(amfSetId)
+	if !ok {
+		return false
+	}
+	return t.regionId == s.regionId && t.mnc == s.mnc && t.mcc == s.mcc
+}
+
+// Check whether the AMF belongs to the AMF set or region
+// AMF set membership comes from AMF set configuration as well as the AMF set ID reported by the AMF
+func CheckAmfInAmfSet(nfId string, target string) bool {
+	factory.NssfConfig.RLock()
+	defer factory.NssfConfig.RUnlock()
+	return CheckAmfInAmfSetLocked(nfId, target)
+}
+
+// Same as CheckAmfInAmfSet, but the caller must hold the lock of NSSF configuration
+func CheckAmfInAmfSetLocked(nfId string, target string) bool {
+	for _, amfSetConfig := range factory.NssfConfig.Configuration.AmfSetList {
+		if MatchAmfSetId(target, amfSetConfig.AmfSetId) && Contain(nfId, amfSetConfig.AmfList) {
+			return true
+		}
+	}
+	for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
+		if amfConfig.NfId == nfId && MatchAmfSetId(target, amfConfig.AmfSetId) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get authorized NSSAI availability data of the AMF set or region
+// S-NSSAIs supported by AMFs in the target are combined per TAI, and only TAIs in the TAI list are returned if given
+func AuthorizeOfAmfSetFromConfig(target string, taiList []models.Tai) []models.AuthorizedNssaiAvailabilityData {
+	factory.NssfConfig.RLock()
+	authorizedNssaiAvailabilityDataList := combineAmfNssaiAvailabilityLocked(func(nfId string) bool {
+		return CheckAmfInAmfSetLocked(nfId, target)
+	}, taiList)
+	factory.NssfConfig.RUnlock()
+
+	for i := range authorizedNssaiAvailabilityDataList {
+		authorizedNssaiAvailabilityDataList[i].RestrictedSnssaiList = GetRestrictedSnssaiListFromConfig(
+			*authorizedNssaiAvailabilityDataList[i].Tai)
+	}
+	return authorizedNssaiAvailabilityDataList
+}
+
+// Combine S-NSSAIs supported by the AMFs accepted by match per TAI, where only TAIs in the TAI list are kept if given
+// The caller must hold the lock of NSSF configuration
+func combineAmfNssaiAvailabilityLocked(
+	match func(nfId string) bool, taiList []models.Tai,
+) []models.AuthorizedNssaiAvailabilityData {
+	var authorizedNssaiAvailabilityDataList []models.AuthorizedNssaiAvailabilityData
+
+	for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
+		if !match(amfConfig.NfId) {
+			continue
+		}
+
+		for _, s := range amfConfig.SupportedNssaiAvailabilityData {
+			if len(taiList) != 0 && !Contain(*s.Tai, taiList) {
+				continue
+			}
+
+			hitTai := false
+			for i := range authorizedNssaiAvailabilityDataList {
+				authorizedNssaiAvailabilityData := &authorizedNssaiAvailabilityDataList[i]
+				if reflect.DeepEqual(*authorizedNssaiAvailabilityData.Tai, *s.Tai) {
+					for _, snssai := range s.SupportedSnssaiList {
+						if !checkExtSnssaiInNssai(snssai, authorizedNssaiAvailabilityData.SupportedSnssaiList) {
+							authorizedNssaiAvailabilityData.SupportedSnssaiList = append(
+								authorizedNssaiAvailabilityData.SupportedSnssaiList, snssai)
+						}
+					}
+					hitTai = true
+					break
+				}
+			}
+			if !hitTai {
+				tai := *s.Tai
+				authorizedNssaiAvailabilityDataList = append(authorizedNssaiAvailabilityDataList,
+					models.AuthorizedNssaiAvailabilityData{
+						Tai:                 &tai,
+						SupportedSnssaiList: append([]models.ExtSnssai{}, s.SupportedSnssaiList...),
+					})
+			}
+		}
+	}
+	return authorizedNssaiAvailabilityDataList
+}
@@ -0,0 +1,224 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+const (
+	testAmfSet1   = "set1.region48.amfset.5gc.mnc93.mcc208"
+	testAmfSet2   = "set2.region48.amfset.5gc.mnc93.mcc208"
+	testAmfRegion = "region48.amfset.5gc.mnc93.mcc208"
+)
+
+func setAmfSetConfig() {
+	plmnId := &models.PlmnId{Mcc: "208", Mnc: "93"}
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			AmfSetList: []factory.AmfSetConfig{
+				{AmfSetId: testAmfSet1, AmfList: []string{"amf1"}},
+			},
+			AmfList: []factory.AmfConfig{
+				{
+					NfId: "amf1",
+					SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{
+							Tai:                 &models.Tai{PlmnId: plmnId, Tac: "33456"},
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}, {Sst: 1, Sd: "000001"}},
+						},
+					},
+				},
+				{
+					NfId:     "amf2",
+					AmfSetId: "SET1.REGION48.AMFSET.5GC.MNC93.MCC208",
+					SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{
+							Tai:                 &models.Tai{PlmnId: plmnId, Tac: "33456"},
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}, {Sst: 2}},
+						},
+						{
+							Tai:                 &models.Tai{PlmnId: plmnId, Tac: "33457"},
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 2}},
+						},
+					},
+				},
+				{
+					NfId:     "amf3",
+					AmfSetId: testAmfSet2,
+					SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{
+							Tai:                 &models.Tai{PlmnId: plmnId, Tac: "33456"},
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 3}},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMatchAmfSetId(t *testing.T) {
+	testCases := []struct {
+		name        string
+		target      string
+		amfSetId    string
+		expectMatch bool
+	}{
+		{"Same AMF set", testAmfSet1, testAmfSet1, true},
+		{"Same AMF set in different case", testAmfSet1, "SET1.Region48.amfset.5gc.mnc93.mcc208", true},
+		{"Different AMF set", testAmfSet1, testAmfSet2, false},
+		{"AMF set in the AMF region", testAmfRegion, testAmfSet2, true},
+		{"AMF set in different AMF region", testAmfRegion, "set1.region49.amfset.5gc.mnc93.mcc208", false},
+		{"AMF set in different PLMN", testAmfRegion, "set1.region48.amfset.5gc.mnc92.mcc208", false},
+		{"AMF region is not in an AMF set", testAmfSet1, testAmfRegion, false},
+		{"Same opaque AMF set ID", "2080931", "2080931", true},
+		{"Opaque AMF set ID in the AMF region", testAmfRegion, "2080931", false},
+		{"Empty target", "", testAmfSet1, false},
+		{"Empty AMF set ID", testAmfSet1, "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if match := util.MatchAmfSetId(tc.target, tc.amfSetId); match != tc.expectMatch {
+				t.Errorf("Expected match of %s with target %s to be %v, got: %v",
+					tc.amfSetId, tc.target, tc.expectMatch, match)
+			}
+		})
+	}
+}
+
+func TestCheckAmfInAmfSet(t *testing.T) {
+	setAmfSetConfig()
+
+	testCases := []struct {
+		name         string
+		nfId         string
+		target       string
+		expectMember bool
+	}{
+		{"Member by AMF set configuration", "amf1", testAmfSet1, true},
+		{"Member by AMF set ID reported by AMF", "amf2", testAmfSet1, true},
+		{"Not a member", "amf3", testAmfSet1, false},
+		{"Member of the AMF region", "amf3", testAmfRegion, true},
+		{"Member of the AMF region by AMF set configuration", "amf1", testAmfRegion, true},
+		{"Unknown AMF", "amf4", testAmfRegion, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if member := util.CheckAmfInAmfSet(tc.nfId, tc.target); member != tc.expectMember {
+				t.Errorf("Expected membership of %s in %s to be %v, got: %v",
+					tc.nfId, tc.target, tc.expectMember, member)
+			}
+		})
+	}
+}
+
+func TestAuthorizeOfAmfSetFromConfig(t *testing.T) {
+	setAmfSetConfig()
+	plmnId := &models.PlmnId{Mcc: "208", Mnc: "93"}
+
+	testCases := []struct {
+		name   string
+		target string
+		tais   []models.Tai
+		// Number of supported S-NSSAIs indexed by TAC
+		expectSnssaiLen map[string]int
+	}{
+		{
+			name:            "S-NSSAIs of AMFs in the AMF set combined per TAI",
+			target:          testAmfSet1,
+			expectSnssaiLen: map[string]int{"33456": 3, "33457": 1},
+		},
+		{
+			name:            "Only TAIs in the TAI list",
+			target:          testAmfSet1,
+			tais:            []models.Tai{{PlmnId: plmnId, Tac: "33457"}},
+			expectSnssaiLen: map[string]int{"33457": 1},
+		},
+		{
+			name:            "AMF region",
+			target:          testAmfRegion,
+			expectSnssaiLen: map[string]int{"33456": 4, "33457": 1},
+		},
+		{
+			name:            "AMF set without AMF",
+			target:          "set3.region48.amfset.5gc.mnc93.mcc208",
+			expectSnssaiLen: map[string]int{},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			authorizedNssaiAvailabilityData := util.AuthorizeOfAmfSetFromConfig(tc.target, tc.tais)
+			if len(authorizedNssaiAvailabilityData) != len(tc.expectSnssaiLen) {
+				t.Fatalf("Expected %d TAIs, got: %+v", len(tc.expectSnssaiLen), authorizedNssaiAvailabilityData)
+			}
+			for _, a := range authorizedNssaiAvailabilityData {
+				expected, ok := tc.expectSnssaiLen[a.Tai.Tac]
+				if !ok {
+					t.Errorf("Unexpected TAI %+v", *a.Tai)
+					continue
+				}
+				if len(a.SupportedSnssaiList) != expected {
+					t.Errorf("Expected %d supported S-NSSAIs under TAC %s, got: %+v",
+						expected, a.Tai.Tac, a.SupportedSnssaiList)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthorizeOfTaListFromAmfs(t *testing.T) {
+	setAmfSetConfig()
+	plmnId := &models.PlmnId{Mcc: "208", Mnc: "93"}
+	// A TA which is not served by any AMF
+	unservedTai := models.Tai{PlmnId: plmnId, Tac: "33458"}
+	factory.NssfConfig.Configuration.TaList = []factory.TaConfig{
+		{Tai: &unservedTai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}}},
+	}
+
+	testCases := []struct {
+		name            string
+		tais            []models.Tai
+		expectSnssaiLen map[string]int
+	}{
+		{
+			name:            "S-NSSAIs of all AMFs combined per TAI",
+			expectSnssaiLen: map[string]int{"33456": 4, "33457": 1},
+		},
+		{
+			name:            "Only TAIs in the TAI list",
+			tais:            []models.Tai{{PlmnId: plmnId, Tac: "33457"}},
+			expectSnssaiLen: map[string]int{"33457": 1},
+		},
+		{
+			name:            "TA not served by any AMF",
+			tais:            []models.Tai{unservedTai, {PlmnId: plmnId, Tac: "33459"}},
+			expectSnssaiLen: map[string]int{"33458": 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			authorizedNssaiAvailabilityData := util.AuthorizeOfTaListFromAmfs(tc.tais)
+			if len(authorizedNssaiAvailabilityData) != len(tc.expectSnssaiLen) {
+				t.Fatalf("Expected %d TAIs, got: %+v", len(tc.expectSnssaiLen), authorizedNssaiAvailabilityData)
+			}
+			for _, a := range authorizedNssaiAvailabilityData {
+				expected, ok := tc.expectSnssaiLen[a.Tai.Tac]
+				if !ok {
+					t.Errorf("Unexpected TAI %+v", *a.Tai)
+					continue
+				}
+				if a.SupportedSnssaiList == nil || len(a.SupportedSnssaiList) != expected {
+					t.Errorf("Expected %d supported S-NSSAIs under TAC %s, got: %+v",
+						expected, a.Tai.Tac, a.SupportedSnssaiList)
+				}
+			}
+		})
+	}
+}
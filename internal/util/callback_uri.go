@@ -0,0 +1,104 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/free5gc/nssf/pkg/factory"
+)
+
+// Validate the callback URI of a subscriber against the notification policy in configuration
+func ValidateCallbackUri(uri string) error {
+	policy := factory.NssfConfig.GetNotificationConfig()
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("invalid callback URI: %w", err)
+	}
+	if !u.IsAbs() || u.Hostname() == "" {
+		return fmt.Errorf("callback URI '%s' should be an absolute URI", uri)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	allowedSchemes := policy.AllowedSchemes
+	if len(allowedSchemes) == 0 {
+		allowedSchemes = []string{"http", "https"}
+	}
+	if !Contain(scheme, allowedSchemes) {
+		return fmt.Errorf("scheme '%s' of callback URI is not allowed", u.Scheme)
+	}
+
+	host := u.Hostname()
+	allowed, listed := matchAllowedHosts(host, nil, policy.AllowedHosts)
+	if !allowed {
+		return fmt.Errorf("host '%s' of callback URI is not allowed", host)
+	}
+
+	if !policy.AllowPrivateTargets && !listed {
+		if ip := net.ParseIP(host); ip != nil && isPrivateAddress(ip) {
+			return fmt.Errorf("private address '%s' of callback URI is not allowed", host)
+		}
+		if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+			return fmt.Errorf("loopback host '%s' of callback URI is not allowed", host)
+		}
+	}
+	return nil
+}
+
+// Check the address resolved from the host of a callback URI right before connecting to the subscriber,
+// so that a host name resolved to a private address is rejected as well
+func CheckCallbackAddress(host string, ip net.IP) error {
+	policy := factory.NssfConfig.GetNotificationConfig()
+
+	allowed, listed := matchAllowedHosts(host, ip, policy.AllowedHosts)
+	if !allowed {
+		return fmt.Errorf("host '%s' of callback URI is not allowed", host)
+	}
+	if !policy.AllowPrivateTargets && !listed && isPrivateAddress(ip) {
+		return fmt.Errorf("host '%s' of callback URI is resolved to private address %s", host, ip)
+	}
+	return nil
+}
+
+// Match the host, or the address resolved from the host if given, against the allowed hosts
+// Whether the host is allowed and whether it is explicitly listed are returned
+func matchAllowedHosts(host string, ip net.IP, allowedHosts []string) (allowed bool, listed bool) {
+	if len(allowedHosts) == 0 {
+		return true, false
+	}
+
+	if ip == nil {
+		ip = net.ParseIP(host)
+	}
+	for _, allowedHost := range allowedHosts {
+		if _, ipNet, err := net.ParseCIDR(allowedHost); err == nil {
+			if ip != nil && ipNet.Contains(ip) {
+				return true, true
+			}
+			continue
+		}
+		if allowedIp := net.ParseIP(allowedHost); allowedIp != nil {
+			if ip != nil && allowedIp.Equal(ip) {
+				return true, true
+			}
+			continue
+		}
+		if domain, ok := strings.CutPrefix(allowedHost, "*."); ok {
+			if strings.HasSuffix(strings.ToLower(host), "."+strings.ToLower(domain)) {
+				return true, true
+			}
+			continue
+		}
+		if strings.EqualFold(host, allowedHost) {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+func isPrivateAddress(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
@@ -0,0 +1,141 @@
+package util_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/factory"
+)
+
+func setNotificationConfig(policy *factory.NotificationConfig) {
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			Notification: policy,
+		},
+	}
+}
+
+func TestValidateCallbackUri(t *testing.T) {
+	testCases := []struct {
+		name        string
+		policy      *factory.NotificationConfig
+		uri         string
+		expectValid bool
+	}{
+		{"Public address", nil, "http://203.0.113.10:8000/callback", true},
+		{"Public host name", nil, "https://amf.example.com/callback", true},
+		{"Relative URI", nil, "/callback", false},
+		{"Unsupported scheme", nil, "ftp://203.0.113.10/callback", false},
+		{"Malformed URI", nil, "http://[::1/callback", false},
+		{"IPv4 loopback", nil, "http://127.0.0.1:8000/callback", false},
+		{"IPv6 loopback", nil, "http://[::1]:8000/callback", false},
+		{"Localhost", nil, "http://localhost:8000/callback", false},
+		{"Subdomain of localhost", nil, "http://amf.localhost:8000/callback", false},
+		{"Unspecified address", nil, "http://0.0.0.0:8000/callback", false},
+		{"IPv4 link-local", nil, "http://169.254.169.254/latest/meta-data", false},
+		{"IPv6 link-local", nil, "http://[fe80::1]:8000/callback", false},
+		{"RFC 1918 10/8", nil, "http://10.0.0.1:8000/callback", false},
+		{"RFC 1918 172.16/12", nil, "http://172.31.255.1:8000/callback", false},
+		{"RFC 1918 192.168/16", nil, "http://192.168.1.1:8000/callback", false},
+		{"IPv6 unique local", nil, "http://[fd00::1]:8000/callback", false},
+		{"IPv4-mapped IPv6 loopback", nil, "http://[::ffff:127.0.0.1]:8000/callback", false},
+		{"IPv4-mapped IPv6 private", nil, "http://[::ffff:10.0.0.1]:8000/callback", false},
+		{"IPv4-mapped IPv6 public", nil, "http://[::ffff:203.0.113.10]:8000/callback", true},
+		{
+			"Private address allowed by policy",
+			&factory.NotificationConfig{AllowPrivateTargets: true},
+			"http://10.0.0.1:8000/callback",
+			true,
+		},
+		{
+			"Private address listed in allowed hosts",
+			&factory.NotificationConfig{AllowedHosts: []string{"10.0.0.0/8"}},
+			"http://10.0.0.1:8000/callback",
+			true,
+		},
+		{
+			"Host not in allowed hosts",
+			&factory.NotificationConfig{AllowedHosts: []string{"*.example.com"}},
+			"http://amf.example.org/callback",
+			false,
+		},
+		{
+			"Host matching wildcard of allowed hosts",
+			&factory.NotificationConfig{AllowedHosts: []string{"*.example.com"}},
+			"http://AMF.Example.com/callback",
+			true,
+		},
+		{
+			"Scheme not in allowed schemes",
+			&factory.NotificationConfig{AllowedSchemes: []string{"https"}},
+			"http://amf.example.com/callback",
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setNotificationConfig(tc.policy)
+			err := util.ValidateCallbackUri(tc.uri)
+			if tc.expectValid && err != nil {
+				t.Errorf("Expected %s to be valid, got: %+v", tc.uri, err)
+			}
+			if !tc.expectValid && err == nil {
+				t.Errorf("Expected %s to be rejected", tc.uri)
+			}
+		})
+	}
+}
+
+func TestCheckCallbackAddress(t *testing.T) {
+	// Host names are given with the addresses they are resolved to, as checked on dialing
+	testCases := []struct {
+		name          string
+		policy        *factory.NotificationConfig
+		host          string
+		ip            string
+		expectAllowed bool
+	}{
+		{"Name resolved to public address", nil, "amf.example.com", "203.0.113.10", true},
+		{"Name resolved to loopback", nil, "amf.example.com", "127.0.0.1", false},
+		{"Name resolved to IPv6 loopback", nil, "amf.example.com", "::1", false},
+		{"Name resolved to link-local", nil, "amf.example.com", "169.254.169.254", false},
+		{"Name resolved to RFC 1918", nil, "amf.example.com", "192.168.0.10", false},
+		{"Name resolved to IPv6 unique local", nil, "amf.example.com", "fc00::10", false},
+		{"Name resolved to IPv4-mapped private", nil, "amf.example.com", "::ffff:172.16.0.1", false},
+		{
+			"Private address allowed by policy",
+			&factory.NotificationConfig{AllowPrivateTargets: true},
+			"amf.example.com", "10.0.0.1", true,
+		},
+		{
+			"Name listed in allowed hosts",
+			&factory.NotificationConfig{AllowedHosts: []string{"amf.example.com"}},
+			"amf.example.com", "10.0.0.1", true,
+		},
+		{
+			"Resolved address listed in allowed hosts",
+			&factory.NotificationConfig{AllowedHosts: []string{"10.1.0.0/16"}},
+			"amf.example.com", "10.1.2.3", true,
+		},
+		{
+			"Resolved address not in allowed hosts",
+			&factory.NotificationConfig{AllowedHosts: []string{"10.1.0.0/16"}},
+			"amf.example.com", "10.2.2.3", false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			setNotificationConfig(tc.policy)
+			err := util.CheckCallbackAddress(tc.host, net.ParseIP(tc.ip))
+			if tc.expectAllowed && err != nil {
+				t.Errorf("Expected %s resolved to %s to be allowed, got: %+v", tc.host, tc.ip, err)
+			}
+			if !tc.expectAllowed && err == nil {
+				t.Errorf("Expected %s resolved to %s to be rejected", tc.host, tc.ip)
+			}
+		})
+	}
+}
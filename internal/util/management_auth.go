@@ -0,0 +1,51 @@
+package util
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/free5gc/nssf/internal/logger"
+)
+
+// Check of the bearer token of management API, which is provisioned for operators instead of issued by NRF
+type ManagementAuthorizationCheck struct {
+	token []byte
+}
+
+func NewManagementAuthorizationCheck(token string) *ManagementAuthorizationCheck {
+	return &ManagementAuthorizationCheck{
+		token: []byte(token),
+	}
+}
+
+// Load the bearer token of management API from the file, ignoring surrounding spaces and newlines
+func LoadManagementToken(tokenFile string) (string, error) {
+	content, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return "", fmt.Errorf("read management token failed: %w", err)
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", errors.New("management token is empty in " + tokenFile)
+	}
+	return token, nil
+}
+
+func (mac *ManagementAuthorizationCheck) Check(c *gin.Context) {
+	token, found := strings.CutPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), mac.token) != 1 {
+		logger.UtilLog.Warnf("ManagementAuthorizationCheck: Unauthorized request from %s", c.ClientIP())
+		c.Header("WWW-Authenticate", "Bearer")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing bearer token"})
+		c.Abort()
+		return
+	}
+
+	logger.UtilLog.Debugf("ManagementAuthorizationCheck: Check Authorized")
+}
@@ -0,0 +1,108 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/openapi/models"
+)
+
+// Change of NSSAI availability data under a TAI
+// Before is nil if the TAI is added, and After is nil if the TAI is removed
+type NssaiAvailabilityChange struct {
+	Tai    models.Tai
+	Before *models.SupportedNssaiAvailabilityData
+	After  *models.SupportedNssaiAvailabilityData
+}
+
+func (c NssaiAvailabilityChange) IsAdded() bool {
+	return c.Before == nil
+}
+
+func (c NssaiAvailabilityChange) IsRemoved() bool {
+	return c.After == nil
+}
+
+func (c NssaiAvailabilityChange) String() string {
+	switch {
+	case c.IsAdded():
+		return fmt.Sprintf("TAI %s added with S-NSSAIs %+v", taiKey(c.Tai), c.After.SupportedSnssaiList)
+	case c.IsRemoved():
+		return fmt.Sprintf("TAI %s removed", taiKey(c.Tai))
+	default:
+		return fmt.Sprintf("TAI %s changed from S-NSSAIs %+v to %+v", taiKey(c.Tai),
+			c.Before.SupportedSnssaiList, c.After.SupportedSnssaiList)
+	}
+}
+
+// Diff NSSAI availability data per TAI
+// Changes of added and modified TAIs are in the order of after, followed by removed TAIs in the order of before
+func DiffSupportedNssaiAvailabilityData(
+	before, after []models.SupportedNssaiAvailabilityData,
+) []NssaiAvailabilityChange {
+	var changes []NssaiAvailabilityChange
+
+	beforeByTai := make(map[string]*models.SupportedNssaiAvailabilityData, len(before))
+	for i := range before {
+		if before[i].Tai != nil {
+			beforeByTai[taiKey(*before[i].Tai)] = &before[i]
+		}
+	}
+
+	afterByTai := make(map[string]*models.SupportedNssaiAvailabilityData, len(after))
+	for i := range after {
+		if after[i].Tai == nil {
+			continue
+		}
+		key := taiKey(*after[i].Tai)
+		afterByTai[key] = &after[i]
+
+		b, ok := beforeByTai[key]
+		if !ok {
+			changes = append(changes, NssaiAvailabilityChange{Tai: *after[i].Tai, After: &after[i]})
+		} else if !equalSupportedNssaiAvailabilityData(*b, after[i]) {
+			changes = append(changes, NssaiAvailabilityChange{Tai: *after[i].Tai, Before: b, After: &after[i]})
+		}
+	}
+
+	for i := range before {
+		if before[i].Tai == nil {
+			continue
+		}
+		if _, ok := afterByTai[taiKey(*before[i].Tai)]; !ok {
+			changes = append(changes, NssaiAvailabilityChange{Tai: *before[i].Tai, Before: &before[i]})
+		}
+	}
+
+	return changes
+}
+
+func taiKey(tai models.Tai) string {
+	if tai.PlmnId == nil {
+		return fmt.Sprintf("%s/%s", tai.Tac, tai.Nid)
+	}
+	return fmt.Sprintf("%s-%s/%s/%s", tai.PlmnId.Mcc, tai.PlmnId.Mnc, tai.Tac, tai.Nid)
+}
+
+// Compare by JSON encoding so that absent and empty lists are regarded as the same
+func equalSupportedNssaiAvailabilityData(s, t models.SupportedNssaiAvailabilityData) bool {
+	if s.SupportedSnssaiList == nil {
+		s.SupportedSnssaiList = []models.ExtSnssai{}
+	}
+	if t.SupportedSnssaiList == nil {
+		t.SupportedSnssaiList = []models.ExtSnssai{}
+	}
+	a, err := json.Marshal(s)
+	if err != nil {
+		logger.UtilLog.Errorf("Marshal error in equalSupportedNssaiAvailabilityData: %+v", err)
+		return false
+	}
+	b, err := json.Marshal(t)
+	if err != nil {
+		logger.UtilLog.Errorf("Marshal error in equalSupportedNssaiAvailabilityData: %+v", err)
+		return false
+	}
+	return bytes.Equal(a, b)
+}
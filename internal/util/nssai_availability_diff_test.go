@@ -0,0 +1,102 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/openapi/models"
+)
+
+func TestDiffSupportedNssaiAvailabilityData(t *testing.T) {
+	plmnId := &models.PlmnId{Mcc: "208", Mnc: "93"}
+	tai1 := &models.Tai{PlmnId: plmnId, Tac: "33456"}
+	tai2 := &models.Tai{PlmnId: plmnId, Tac: "33457"}
+	tai3 := &models.Tai{PlmnId: plmnId, Tac: "33458"}
+
+	data := func(tai *models.Tai, snssaiList ...models.ExtSnssai) models.SupportedNssaiAvailabilityData {
+		return models.SupportedNssaiAvailabilityData{Tai: tai, SupportedSnssaiList: snssaiList}
+	}
+
+	type change struct {
+		tac     string
+		added   bool
+		removed bool
+	}
+
+	testCases := []struct {
+		name          string
+		before, after []models.SupportedNssaiAvailabilityData
+		expectChanges []change
+	}{
+		{
+			name:   "Unchanged",
+			before: []models.SupportedNssaiAvailabilityData{data(tai1, models.ExtSnssai{Sst: 1})},
+			after:  []models.SupportedNssaiAvailabilityData{data(tai1, models.ExtSnssai{Sst: 1})},
+		},
+		{
+			name: "Unchanged in different order",
+			before: []models.SupportedNssaiAvailabilityData{
+				data(tai1, models.ExtSnssai{Sst: 1}), data(tai2, models.ExtSnssai{Sst: 2}),
+			},
+			after: []models.SupportedNssaiAvailabilityData{
+				data(tai2, models.ExtSnssai{Sst: 2}), data(tai1, models.ExtSnssai{Sst: 1}),
+			},
+		},
+		{
+			name:   "Absent and empty lists",
+			before: []models.SupportedNssaiAvailabilityData{data(tai1)},
+			after: []models.SupportedNssaiAvailabilityData{
+				{Tai: tai1, SupportedSnssaiList: []models.ExtSnssai{}, TaiList: []models.Tai{}},
+			},
+		},
+		{
+			name:          "TAI added",
+			before:        nil,
+			after:         []models.SupportedNssaiAvailabilityData{data(tai1, models.ExtSnssai{Sst: 1})},
+			expectChanges: []change{{tac: "33456", added: true}},
+		},
+		{
+			name:          "TAI removed",
+			before:        []models.SupportedNssaiAvailabilityData{data(tai1, models.ExtSnssai{Sst: 1})},
+			after:         nil,
+			expectChanges: []change{{tac: "33456", removed: true}},
+		},
+		{
+			name:          "S-NSSAI changed",
+			before:        []models.SupportedNssaiAvailabilityData{data(tai1, models.ExtSnssai{Sst: 1})},
+			after:         []models.SupportedNssaiAvailabilityData{data(tai1, models.ExtSnssai{Sst: 1, Sd: "000001"})},
+			expectChanges: []change{{tac: "33456"}},
+		},
+		{
+			name: "Added and changed in order of after, followed by removed",
+			before: []models.SupportedNssaiAvailabilityData{
+				data(tai1, models.ExtSnssai{Sst: 1}), data(tai2, models.ExtSnssai{Sst: 2}),
+			},
+			after: []models.SupportedNssaiAvailabilityData{
+				data(tai3, models.ExtSnssai{Sst: 3}), data(tai2, models.ExtSnssai{Sst: 1}),
+			},
+			expectChanges: []change{{tac: "33458", added: true}, {tac: "33457"}, {tac: "33456", removed: true}},
+		},
+		{
+			name:          "Without TAI",
+			before:        []models.SupportedNssaiAvailabilityData{data(nil, models.ExtSnssai{Sst: 1})},
+			after:         []models.SupportedNssaiAvailabilityData{data(nil, models.ExtSnssai{Sst: 2})},
+			expectChanges: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			changes := util.DiffSupportedNssaiAvailabilityData(tc.before, tc.after)
+			if len(changes) != len(tc.expectChanges) {
+				t.Fatalf("Expected %d changes, got: %+v", len(tc.expectChanges), changes)
+			}
+			for i, c := range changes {
+				expected := tc.expectChanges[i]
+				if c.Tai.Tac != expected.tac || c.IsAdded() != expected.added || c.IsRemoved() != expected.removed {
+					t.Errorf("Expected change %+v, got: %s", expected, c)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/free5gc/openapi"
+	"github.com/free5gc/openapi/models"
+)
+
+// Features of Nnssf_NSSelection service, numbered as bits of the supported features bitmask
+const (
+	// Extended Support for 3xx redirections
+	NsselFeatureES3XX = 1
+	// Network Slice Admission Group
+	NsselFeatureNSAG = 2
+	// Allowed NSSAI in multiple Access Types
+	NsselFeatureMultiAccess = 3
+)
+
+// Features of Nnssf_NSSAIAvailability service, numbered as bits of the supported features bitmask
+const (
+	// Extended Support for 3xx redirections
+	NssaiavailFeatureES3XX = 1
+	// Network Slice Admission Group
+	NssaiavailFeatureNSAG = 2
+	// SD ranges and wildcard SD in S-NSSAIs
+	NssaiavailFeatureSdRange = 3
+)
+
+// Features supported by this NSSF per service
+var supportedFeatures = map[models.ServiceName]openapi.SupportedFeature{
+	models.ServiceName_NNSSF_NSSELECTION: newSupportedFeature(
+		NsselFeatureES3XX,
+		NsselFeatureNSAG,
+		NsselFeatureMultiAccess,
+	),
+	models.ServiceName_NNSSF_NSSAIAVAILABILITY: newSupportedFeature(
+		NssaiavailFeatureES3XX,
+		NssaiavailFeatureNSAG,
+		NssaiavailFeatureSdRange,
+	),
+}
+
+func newSupportedFeature(features ...int) openapi.SupportedFeature {
+	var maxFeature int
+	for _, feature := range features {
+		if feature > maxFeature {
+			maxFeature = feature
+		}
+	}
+
+	supportedFeature := make(openapi.SupportedFeature, (maxFeature+7)/8)
+	for _, feature := range features {
+		byteIndex := len(supportedFeature) - ((feature - 1) / 8) - 1
+		supportedFeature[byteIndex] |= 0x01 << uint8((feature-1)%8)
+	}
+	return supportedFeature
+}
+
+// Get features supported by this NSSF of the service in hexadecimal representation
+func GetSupportedFeatures(serviceName models.ServiceName) string {
+	return FormatSupportedFeatures(supportedFeatures[serviceName])
+}
+
+// Negotiate supported features of the service with the ones provided by the NF service consumer
+// Per TS 29.500 clause 6.6, features not indicated by the consumer are regarded as not supported
+func NegotiateSupportedFeatures(serviceName models.ServiceName, suppFeat string) (openapi.SupportedFeature, error) {
+	if suppFeat == "" {
+		return openapi.SupportedFeature{}, nil
+	}
+
+	incomingSuppFeat, err := openapi.NewSupportedFeature(suppFeat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid supported features: %s", suppFeat)
+	}
+
+	return supportedFeatures[serviceName].NegotiateWith(incomingSuppFeat), nil
+}
+
+// Format supported features in hexadecimal representation without leading zeros
+// An empty string is returned if no feature is supported
+func FormatSupportedFeatures(supportedFeature openapi.SupportedFeature) string {
+	return strings.TrimLeft(supportedFeature.String(), "0")
+}
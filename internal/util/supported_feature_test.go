@@ -0,0 +1,66 @@
+package util_test
+
+import (
+	"testing"
+
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/openapi/models"
+)
+
+func TestGetSupportedFeatures(t *testing.T) {
+	testCases := []struct {
+		serviceName models.ServiceName
+		expect      string
+	}{
+		// ES3XX, NSAG and multiple access types
+		{models.ServiceName_NNSSF_NSSELECTION, "7"},
+		// ES3XX, NSAG and SD ranges
+		{models.ServiceName_NNSSF_NSSAIAVAILABILITY, "7"},
+		{models.ServiceName_NAMF_COMM, ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.serviceName), func(t *testing.T) {
+			if supportedFeatures := util.GetSupportedFeatures(tc.serviceName); supportedFeatures != tc.expect {
+				t.Errorf("Expected supported features %q, got: %q", tc.expect, supportedFeatures)
+			}
+		})
+	}
+}
+
+func TestNegotiateSupportedFeatures(t *testing.T) {
+	testCases := []struct {
+		name        string
+		serviceName models.ServiceName
+		suppFeat    string
+		expect      string
+		expectErr   bool
+	}{
+		{"Not indicated", models.ServiceName_NNSSF_NSSELECTION, "", "", false},
+		{"Single feature", models.ServiceName_NNSSF_NSSELECTION, "4", "4", false},
+		{"Some features", models.ServiceName_NNSSF_NSSAIAVAILABILITY, "5", "5", false},
+		{"Uppercase hexadecimal digits", models.ServiceName_NNSSF_NSSELECTION, "F", "7", false},
+		{"Features unknown to NSSF", models.ServiceName_NNSSF_NSSELECTION, "8", "", false},
+		{"Longer bitmask than NSSF", models.ServiceName_NNSSF_NSSAIAVAILABILITY, "ff03", "3", false},
+		{"Leading zeros", models.ServiceName_NNSSF_NSSELECTION, "0002", "2", false},
+		{"Invalid hexadecimal digits", models.ServiceName_NNSSF_NSSELECTION, "xyz", "", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			negotiated, err := util.NegotiateSupportedFeatures(tc.serviceName, tc.suppFeat)
+			if tc.expectErr {
+				if err == nil {
+					t.Errorf("Expected supported features %q to be rejected", tc.suppFeat)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected supported features %q to be negotiated, got: %+v", tc.suppFeat, err)
+			}
+			if s := util.FormatSupportedFeatures(negotiated); s != tc.expect {
+				t.Errorf("Expected negotiated features %q, got: %q", tc.expect, s)
+			}
+		})
+	}
+}
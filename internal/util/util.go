@@ -22,8 +22,10 @@ const (
 	INVALID_REQUEST       = "Invalid request message framing"
 	MANDATORY_IE_MISSING  = "Mandatory IEs are missing"
 	MALFORMED_REQUEST     = "Malformed request syntax"
+	PRECONDITION_FAILED   = "Precondition failed"
 	UNAUTHORIZED_CONSUMER = "Unauthorized NF service consumer"
 	UNSUPPORTED_RESOURCE  = "Unsupported request resources"
+	UNSUPPORTED_MEDIA     = "Unsupported media type"
 )
 
 // Check if a slice contains an element
@@ -50,12 +52,8 @@ func SnssaiEqualFold(s models.ExtSnssai, t models.Snssai) bool {
 
 // Check whether UE's Home PLMN is configured/supported
 func CheckSupportedHplmn(homePlmnId models.PlmnId) bool {
-	factory.NssfConfig.RLock()
-	defer factory.NssfConfig.RUnlock()
-	for _, mappingFromPlmn := range factory.NssfConfig.Configuration.MappingListFromPlmn {
-		if *mappingFromPlmn.HomePlmnId == homePlmnId {
-			return true
-		}
+	if factory.NssfConfig.Snapshot().HasHomePlmn(homePlmnId) {
+		return true
 	}
 	logger.UtilLog.Warnf("No Home PLMN %+v in NSSF configuration", homePlmnId)
 	return false
@@ -63,12 +61,8 @@ func CheckSupportedHplmn(homePlmnId models.PlmnId) bool {
 
 // Check whether UE's current TA is configured/supported
 func CheckSupportedTa(tai models.Tai) bool {
-	factory.NssfConfig.RLock()
-	defer factory.NssfConfig.RUnlock()
-	for _, taConfig := range factory.NssfConfig.Configuration.TaList {
-		if reflect.DeepEqual(*taConfig.Tai, tai) {
-			return true
-		}
+	if factory.NssfConfig.Snapshot().HasTa(tai) {
+		return true
 	}
 	e, err := json.Marshal(tai)
 	if err != nil {
@@ -80,21 +74,13 @@ func CheckSupportedTa(tai models.Tai) bool {
 
 // Check whether the given S-NSSAI is supported or not in PLMN
 func CheckSupportedSnssaiInPlmn(snssai models.Snssai, plmnId models.PlmnId) bool {
-	factory.NssfConfig.RLock()
-	defer factory.NssfConfig.RUnlock()
 	if CheckStandardSnssai(snssai) {
 		return true
 	}
 
-	for _, supportedNssaiInPlmn := range factory.NssfConfig.Configuration.SupportedNssaiInPlmnList {
-		if *supportedNssaiInPlmn.PlmnId == plmnId {
-			for _, supportedSnssai := range supportedNssaiInPlmn.SupportedSnssaiList {
-				if openapi.SnssaiEqualFold(snssai, supportedSnssai) {
-					return true
-				}
-			}
-			return false
-		}
+	supported, found := factory.NssfConfig.Snapshot().IsSnssaiSupportedInPlmn(snssai, plmnId)
+	if found {
+		return supported
 	}
 	logger.UtilLog.Warnf("No supported S-NSSAI list of PLMNID %+v in NSSF configuration", plmnId)
 	return false
@@ -104,6 +90,11 @@ func CheckSupportedSnssaiInPlmn(snssai models.Snssai, plmnId models.PlmnId) bool
 func CheckSupportedNssaiInPlmn(nssai any, plmnId models.PlmnId) bool {
 	factory.NssfConfig.RLock()
 	defer factory.NssfConfig.RUnlock()
+	return CheckSupportedNssaiInPlmnLocked(nssai, plmnId)
+}
+
+// Same as CheckSupportedNssaiInPlmn, but the caller must hold the lock of NSSF configuration
+func CheckSupportedNssaiInPlmnLocked(nssai any, plmnId models.PlmnId) bool {
 	for _, supportedNssaiInPlmn := range factory.NssfConfig.Configuration.SupportedNssaiInPlmnList {
 		if *supportedNssaiInPlmn.PlmnId == plmnId {
 			if n, ok := nssai.([]models.ExtSnssai); ok {
@@ -158,19 +149,7 @@ func CheckSupportedNssaiInPlmn(nssai any, plmnId models.PlmnId) bool {
 
 // Check whether S-NSSAI is supported or not at UE's current TA
 func CheckSupportedSnssaiInTa(snssai models.Snssai, tai models.Tai) bool {
-	factory.NssfConfig.RLock()
-	defer factory.NssfConfig.RUnlock()
-	for _, taConfig := range factory.NssfConfig.Configuration.TaList {
-		if reflect.DeepEqual(*taConfig.Tai, tai) {
-			for _, supportedSnssai := range taConfig.SupportedSnssaiList {
-				if SnssaiEqualFold(supportedSnssai, snssai) {
-					return true
-				}
-			}
-			return false
-		}
-	}
-	return false
+	return factory.NssfConfig.Snapshot().IsSnssaiSupportedInTa(snssai, tai)
 
 	// // Check supported S-NSSAI in AmfList instead of TaList
 	// for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
@@ -203,10 +182,9 @@ func CheckSupportedSnssaiInAmfTa(snssai models.Snssai, nfId string, tai models.T
 	//     }
 	// }
 
-	for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
-		if amfConfig.NfId == nfId {
-			return CheckSupportedNssaiAvailabilityData(snssai, tai, amfConfig.SupportedNssaiAvailabilityData)
-		}
+	supported, found := factory.NssfConfig.Snapshot().IsSnssaiSupportedByAmf(snssai, nfId, tai)
+	if found {
+		return supported
 	}
 
 	logger.UtilLog.Warnf("No AMF %s in NSSF configuration", nfId)
@@ -248,57 +226,67 @@ func CheckSnssaiInNssai(targetSnssai models.Snssai, nssai []models.ExtSnssai) bo
 
 // Get S-NSSAI mappings of the given Home PLMN ID from configuration
 func GetMappingOfPlmnFromConfig(homePlmnId models.PlmnId) []models.MappingOfSnssai {
-	factory.NssfConfig.RLock()
-	defer factory.NssfConfig.RUnlock()
-	for _, mappingFromPlmn := range factory.NssfConfig.Configuration.MappingListFromPlmn {
-		if *mappingFromPlmn.HomePlmnId == homePlmnId {
-			return mappingFromPlmn.MappingOfSnssai
-		}
+	return factory.NssfConfig.Snapshot().GetMappingOfSnssai(homePlmnId)
+}
+
+// Get supported S-NSSAI list of the given PLMN ID from configuration
+func GetSupportedSnssaiListInPlmnFromConfig(plmnId models.PlmnId) []models.Snssai {
+	supportedSnssaiList, found := factory.NssfConfig.Snapshot().GetSupportedSnssaiListInPlmn(plmnId)
+	if found {
+		return supportedSnssaiList
 	}
+	logger.UtilLog.Warnf("No supported S-NSSAI list of PLMNID %+v in NSSF configuration", plmnId)
 	return nil
 }
 
 // Get NSI information list of the given S-NSSAI from configuration
 func GetNsiInformationListFromConfig(snssai models.Snssai) []models.NsiInformation {
-	factory.NssfConfig.RLock()
-	defer factory.NssfConfig.RUnlock()
-	for _, nsiConfig := range factory.NssfConfig.Configuration.NsiList {
-		if openapi.SnssaiEqualFold(*nsiConfig.Snssai, snssai) {
-			return nsiConfig.NsiInformationList
-		}
-	}
-	return nil
+	return factory.NssfConfig.Snapshot().GetNsiInformationList(snssai)
 }
 
-// Get Access Type of the given TAI from configuraion
-func GetAccessTypeFromConfig(tai models.Tai) models.AccessType {
-	factory.NssfConfig.RLock()
-	defer factory.NssfConfig.RUnlock()
-	for _, taConfig := range factory.NssfConfig.Configuration.TaList {
-		if reflect.DeepEqual(*taConfig.Tai, tai) {
-			return *taConfig.AccessType
+// Get Access Types in which the given S-NSSAI is supported at the given TAI from configuration
+// If the S-NSSAI is not supported in any Access Type, all Access Types of the TA are returned
+func GetAccessTypeListFromConfig(snssai models.Snssai, tai models.Tai) []models.AccessType {
+	accessTypeList, taAccessTypeList, found := factory.NssfConfig.Snapshot().GetAccessTypeList(snssai, tai)
+	if found {
+		if len(accessTypeList) == 0 {
+			return taAccessTypeList
 		}
+		return accessTypeList
 	}
 	e, err := json.Marshal(tai)
 	if err != nil {
-		logger.UtilLog.Errorf("Marshal error in GetAccessTypeFromConfig: %+v", err)
+		logger.UtilLog.Errorf("Marshal error in GetAccessTypeListFromConfig: %+v", err)
 	}
 	logger.UtilLog.Warnf("No TA %s in NSSF configuration", e)
-	return models.AccessType__3_GPP_ACCESS
+	return nil
+}
+
+// Get Access Types to be used when UE's Access Type could not be identified according to operator policy
+func GetUnknownAccessTypeList(candidates []models.AccessType) []models.AccessType {
+	if len(candidates) == 0 {
+		candidates = []models.AccessType{
+			models.AccessType__3_GPP_ACCESS,
+			models.AccessType_NON_3_GPP_ACCESS,
+		}
+	}
+
+	switch factory.NssfConfig.GetUnknownAccessTypePolicy() {
+	case factory.UnknownAccessTypeAllAccess:
+		return candidates
+	default:
+		if Contain(models.AccessType__3_GPP_ACCESS, candidates) {
+			return []models.AccessType{models.AccessType__3_GPP_ACCESS}
+		}
+		return candidates[:1]
+	}
 }
 
 // Get restricted S-NSSAI list of the given TAI from configuration
 func GetRestrictedSnssaiListFromConfig(tai models.Tai) []models.RestrictedSnssai {
-	factory.NssfConfig.RLock()
-	defer factory.NssfConfig.RUnlock()
-	for _, taConfig := range factory.NssfConfig.Configuration.TaList {
-		if reflect.DeepEqual(*taConfig.Tai, tai) {
-			if len(taConfig.RestrictedSnssaiList) != 0 {
-				return taConfig.RestrictedSnssaiList
-			} else {
-				return nil
-			}
-		}
+	restrictedSnssaiList, found := factory.NssfConfig.Snapshot().GetRestrictedSnssaiList(tai)
+	if found {
+		return restrictedSnssaiList
 	}
 	e, err := json.Marshal(tai)
 	if err != nil {
@@ -314,6 +302,8 @@ func AuthorizeOfAmfTaFromConfig(nfId string, tai models.Tai) (models.AuthorizedN
 	authorizedNssaiAvailabilityData.Tai = new(models.Tai)
 	*authorizedNssaiAvailabilityData.Tai = tai
 
+	factory.NssfConfig.RLock()
+	defer factory.NssfConfig.RUnlock()
 	for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
 		if amfConfig.NfId == nfId {
 			for _, supportedNssaiAvailabilityData := range amfConfig.SupportedNssaiAvailabilityData {
@@ -374,7 +364,7 @@ func AuthorizeOfTaListFromConfig(taiList []models.Tai) []models.AuthorizedNssaiA
 				var authorizedNssaiAvailabilityData models.AuthorizedNssaiAvailabilityData
 				authorizedNssaiAvailabilityData.Tai = new(models.Tai)
 				*authorizedNssaiAvailabilityData.Tai = tai
-				authorizedNssaiAvailabilityData.SupportedSnssaiList = taConfig.SupportedSnssaiList
+				authorizedNssaiAvailabilityData.SupportedSnssaiList = taConfig.GetSupportedSnssaiList()
 				authorizedNssaiAvailabilityData.RestrictedSnssaiList = GetRestrictedSnssaiListFromConfig(tai)
 
 				authorizedNssaiAvailabilityDataList = append(authorizedNssaiAvailabilityDataList, authorizedNssaiAvailabilityData)
@@ -384,8 +374,143 @@ func AuthorizeOfTaListFromConfig(taiList []models.Tai) []models.AuthorizedNssaiA
 	return authorizedNssaiAvailabilityDataList
 }
 
+// Get authorized NSSAI availability data of the TAs in the TAI list, or of all TAs if not given
+// S-NSSAIs supported by every AMF serving the TA are combined, so that the data follows NSSAI availability
+// reported by AMFs. A TA in configuration which is not served by any AMF is returned with empty S-NSSAI list
+func AuthorizeOfTaListFromAmfs(taiList []models.Tai) []models.AuthorizedNssaiAvailabilityData {
+	factory.NssfConfig.RLock()
+	authorizedNssaiAvailabilityDataList := combineAmfNssaiAvailabilityLocked(func(string) bool {
+		return true
+	}, taiList)
+	for _, tai := range taiList {
+		hitTai := false
+		for _, a := range authorizedNssaiAvailabilityDataList {
+			if reflect.DeepEqual(*a.Tai, tai) {
+				hitTai = true
+				break
+			}
+		}
+		if hitTai {
+			continue
+		}
+		for _, taConfig := range factory.NssfConfig.Configuration.TaList {
+			if reflect.DeepEqual(*taConfig.Tai, tai) {
+				authorizedNssaiAvailabilityDataList = append(authorizedNssaiAvailabilityDataList,
+					models.AuthorizedNssaiAvailabilityData{
+						Tai:                 &tai,
+						SupportedSnssaiList: []models.ExtSnssai{},
+					})
+				break
+			}
+		}
+	}
+	factory.NssfConfig.RUnlock()
+
+	for i := range authorizedNssaiAvailabilityDataList {
+		authorizedNssaiAvailabilityDataList[i].RestrictedSnssaiList = GetRestrictedSnssaiListFromConfig(
+			*authorizedNssaiAvailabilityDataList[i].Tai)
+	}
+	return authorizedNssaiAvailabilityDataList
+}
+
+// Authorize NSSAI availability data provided by the AMF with S-NSSAIs supported in the TA and in the PLMN,
+// as well as the operator policy of the AMF
+// The S-NSSAIs which are not authorized are returned per TAI as well
+// TAIs of the data must be present with PLMN IDs, which is validated by the caller
+func AuthorizeSupportedNssaiAvailabilityData(nfId string, s []models.SupportedNssaiAvailabilityData) (
+	authorized []models.SupportedNssaiAvailabilityData, unauthorized []models.SupportedNssaiAvailabilityData,
+) {
+	factory.NssfConfig.RLock()
+	defer factory.NssfConfig.RUnlock()
+	return AuthorizeSupportedNssaiAvailabilityDataLocked(nfId, s)
+}
+
+// Same as AuthorizeSupportedNssaiAvailabilityData, but the caller must hold the lock of NSSF configuration
+func AuthorizeSupportedNssaiAvailabilityDataLocked(nfId string, s []models.SupportedNssaiAvailabilityData) (
+	authorized []models.SupportedNssaiAvailabilityData, unauthorized []models.SupportedNssaiAvailabilityData,
+) {
+	var policy *factory.AmfPolicy
+	for i := range factory.NssfConfig.Configuration.AmfPolicyList {
+		if factory.NssfConfig.Configuration.AmfPolicyList[i].NfId == nfId {
+			policy = &factory.NssfConfig.Configuration.AmfPolicyList[i]
+			break
+		}
+	}
+
+	for _, supportedNssaiAvailabilityData := range s {
+		tai := *supportedNssaiAvailabilityData.Tai
+
+		var taSupportedSnssaiList []models.ExtSnssai
+		for _, taConfig := range factory.NssfConfig.Configuration.TaList {
+			if reflect.DeepEqual(*taConfig.Tai, tai) {
+				taSupportedSnssaiList = taConfig.GetSupportedSnssaiList()
+				break
+			}
+		}
+
+		var plmnSupportedSnssaiList []models.Snssai
+		for _, supportedNssaiInPlmn := range factory.NssfConfig.Configuration.SupportedNssaiInPlmnList {
+			if *supportedNssaiInPlmn.PlmnId == *tai.PlmnId {
+				plmnSupportedSnssaiList = supportedNssaiInPlmn.SupportedSnssaiList
+				break
+			}
+		}
+
+		var policySnssaiList []models.ExtSnssai
+		if policy != nil {
+			for _, p := range policy.AuthorizedNssaiAvailabilityData {
+				if p.Tai != nil && reflect.DeepEqual(*p.Tai, tai) {
+					policySnssaiList = p.SupportedSnssaiList
+					break
+				}
+			}
+		}
+
+		authorizedData := supportedNssaiAvailabilityData
+		authorizedData.SupportedSnssaiList = nil
+		unauthorizedData := supportedNssaiAvailabilityData
+		unauthorizedData.SupportedSnssaiList = nil
+		for _, snssai := range supportedNssaiAvailabilityData.SupportedSnssaiList {
+			// Standard S-NSSAIs are supposed to be supported in PLMN
+			supportedInPlmn := CheckStandardSnssai(models.Snssai{Sst: snssai.Sst, Sd: snssai.Sd})
+			for _, plmnSupportedSnssai := range plmnSupportedSnssaiList {
+				if SnssaiEqualFold(snssai, plmnSupportedSnssai) {
+					supportedInPlmn = true
+					break
+				}
+			}
+
+			if supportedInPlmn && checkExtSnssaiInNssai(snssai, taSupportedSnssaiList) &&
+				(policy == nil || checkExtSnssaiInNssai(snssai, policySnssaiList)) {
+				authorizedData.SupportedSnssaiList = append(authorizedData.SupportedSnssaiList, snssai)
+			} else {
+				unauthorizedData.SupportedSnssaiList = append(unauthorizedData.SupportedSnssaiList, snssai)
+			}
+		}
+
+		if len(authorizedData.SupportedSnssaiList) != 0 {
+			authorized = append(authorized, authorizedData)
+		}
+		if len(unauthorizedData.SupportedSnssaiList) != 0 {
+			unauthorized = append(unauthorized, unauthorizedData)
+		}
+	}
+	return authorized, unauthorized
+}
+
+func checkExtSnssaiInNssai(targetSnssai models.ExtSnssai, nssai []models.ExtSnssai) bool {
+	for _, snssai := range nssai {
+		if openapi.ExtSnssaiEqualFold(snssai, targetSnssai) {
+			return true
+		}
+	}
+	return false
+}
+
 // Get supported S-NSSAI list of the given NF ID and TAI from configuration
 func GetSupportedSnssaiListFromConfig(nfId string, tai models.Tai) []models.ExtSnssai {
+	factory.NssfConfig.RLock()
+	defer factory.NssfConfig.RUnlock()
 	for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
 		if amfConfig.NfId == nfId {
 			for _, supportedNssaiAvailabilityData := range amfConfig.SupportedNssaiAvailabilityData {
@@ -422,114 +547,80 @@ func FindMappingWithHomeSnssai(snssai models.Snssai, mappings []models.MappingOf
 }
 
 // Add Allowed S-NSSAI to Authorized Network Slice Info
-func AddAllowedSnssai(allowedSnssai models.AllowedSnssai, accessType models.AccessType,
+// An Allowed NSSAI is maintained per Access Type
+func AddAllowedSnssai(allowedSnssai models.AllowedSnssai, accessTypeList []models.AccessType,
 	authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo,
 ) {
-	hitAllowedNssai := false
-	for i := range authorizedNetworkSliceInfo.AllowedNssaiList {
-		if authorizedNetworkSliceInfo.AllowedNssaiList[i].AccessType == accessType {
-			hitAllowedNssai = true
-			const MAX_ALLOWED_SNSSAI = 8
-			if len(authorizedNetworkSliceInfo.AllowedNssaiList[i].AllowedSnssaiList) == MAX_ALLOWED_SNSSAI {
-				logger.UtilLog.Infof("Unable to add a new Allowed S-NSSAI since already eight S-NSSAIs in Allowed NSSAI")
-			} else {
-				authorizedNetworkSliceInfo.AllowedNssaiList[i].AllowedSnssaiList = append(
-					authorizedNetworkSliceInfo.AllowedNssaiList[i].AllowedSnssaiList,
-					allowedSnssai)
+	for _, accessType := range accessTypeList {
+		hitAllowedNssai := false
+		for i := range authorizedNetworkSliceInfo.AllowedNssaiList {
+			if authorizedNetworkSliceInfo.AllowedNssaiList[i].AccessType == accessType {
+				hitAllowedNssai = true
+				const MAX_ALLOWED_SNSSAI = 8
+				if len(authorizedNetworkSliceInfo.AllowedNssaiList[i].AllowedSnssaiList) == MAX_ALLOWED_SNSSAI {
+					logger.UtilLog.Infof("Unable to add a new Allowed S-NSSAI since already eight S-NSSAIs in Allowed NSSAI")
+				} else {
+					authorizedNetworkSliceInfo.AllowedNssaiList[i].AllowedSnssaiList = append(
+						authorizedNetworkSliceInfo.AllowedNssaiList[i].AllowedSnssaiList,
+						allowedSnssai)
+				}
+				break
 			}
-			break
 		}
-	}
 
-	if !hitAllowedNssai {
-		var allowedNssaiElement models.AllowedNssai
-		allowedNssaiElement.AllowedSnssaiList = append(allowedNssaiElement.AllowedSnssaiList, allowedSnssai)
-		allowedNssaiElement.AccessType = accessType
+		if !hitAllowedNssai {
+			var allowedNssaiElement models.AllowedNssai
+			allowedNssaiElement.AllowedSnssaiList = append(allowedNssaiElement.AllowedSnssaiList, allowedSnssai)
+			allowedNssaiElement.AccessType = accessType
 
-		authorizedNetworkSliceInfo.AllowedNssaiList = append(authorizedNetworkSliceInfo.AllowedNssaiList, allowedNssaiElement)
+			authorizedNetworkSliceInfo.AllowedNssaiList = append(authorizedNetworkSliceInfo.AllowedNssaiList,
+				allowedNssaiElement)
+		}
 	}
 }
 
 // Add AMF information to Authorized Network Slice Info
 func AddAmfInformation(tai models.Tai, authorizedNetworkSliceInfo *models.AuthorizedNetworkSliceInfo) {
-	factory.NssfConfig.RLock()
-	defer factory.NssfConfig.RUnlock()
 	if len(authorizedNetworkSliceInfo.AllowedNssaiList) == 0 {
 		return
 	}
 
 	// Check if any AMF can serve the UE
 	// That is, whether NSSAI of all Allowed S-NSSAIs is a subset of NSSAI supported by AMF
+	var allowedNssai []models.Snssai
+	for _, allowedNssaiElement := range authorizedNetworkSliceInfo.AllowedNssaiList {
+		for _, allowedSnssai := range allowedNssaiElement.AllowedSnssaiList {
+			allowedNssai = append(allowedNssai, *allowedSnssai.AllowedSnssai)
+		}
+	}
+	snapshot := factory.NssfConfig.Snapshot()
 
 	// Find AMF Set that could serve UE from AMF Set list in configuration
 	// Simply use the first applicable AMF set
 	// TODO: Policies of AMF selection (e.g. load balance between AMF instances)
-	for _, amfSetConfig := range factory.NssfConfig.Configuration.AmfSetList {
-		hitAllowedNssai := true
-		for _, allowedNssai := range authorizedNetworkSliceInfo.AllowedNssaiList {
-			for _, allowedSnssai := range allowedNssai.AllowedSnssaiList {
-				if CheckSupportedNssaiAvailabilityData(*allowedSnssai.AllowedSnssai,
-					tai, amfSetConfig.SupportedNssaiAvailabilityData) {
-					continue
-				} else {
-					hitAllowedNssai = false
-					break
-				}
-			}
-			if !hitAllowedNssai {
-				break
-			}
-		}
-
-		if !hitAllowedNssai {
-			continue
+	if amfSetConfig, found := snapshot.FindAmfSetServingNssai(tai, allowedNssai); found {
+		// Add AMF Set to Authorized Network Slice Info
+		if len(amfSetConfig.AmfList) != 0 {
+			// List of candidate AMF(s) provided in configuration
+			authorizedNetworkSliceInfo.CandidateAmfList = append(
+				authorizedNetworkSliceInfo.CandidateAmfList,
+				amfSetConfig.AmfList...)
 		} else {
-			// Add AMF Set to Authorized Network Slice Info
-			if len(amfSetConfig.AmfList) != 0 {
-				// List of candidate AMF(s) provided in configuration
-				authorizedNetworkSliceInfo.CandidateAmfList = append(
-					authorizedNetworkSliceInfo.CandidateAmfList,
-					amfSetConfig.AmfList...)
-			} else {
-				// TODO: Possibly querying the NRF
-				authorizedNetworkSliceInfo.TargetAmfSet = amfSetConfig.AmfSetId
-				// The API URI of the NRF may be included if target AMF Set is included
-				authorizedNetworkSliceInfo.NrfAmfSet = amfSetConfig.NrfAmfSet
-			}
-			return
+			// TODO: Possibly querying the NRF
+			authorizedNetworkSliceInfo.TargetAmfSet = amfSetConfig.AmfSetId
+			// The API URI of the NRF may be included if target AMF Set is included
+			authorizedNetworkSliceInfo.NrfAmfSet = amfSetConfig.NrfAmfSet
 		}
+		return
 	}
 
 	// No AMF Set in configuration can serve the UE
 	// Find all candidate AMFs that could serve UE from AMF list in configuration
-	hitAmf := false
-	for _, amfConfig := range factory.NssfConfig.Configuration.AmfList {
-		hitAllowedNssai := true
-		for _, allowedNssai := range authorizedNetworkSliceInfo.AllowedNssaiList {
-			for _, allowedSnssai := range allowedNssai.AllowedSnssaiList {
-				if CheckSupportedNssaiAvailabilityData(*allowedSnssai.AllowedSnssai,
-					tai, amfConfig.SupportedNssaiAvailabilityData) {
-					continue
-				} else {
-					hitAllowedNssai = false
-					break
-				}
-			}
-			if !hitAllowedNssai {
-				break
-			}
-		}
-
-		if !hitAllowedNssai {
-			continue
-		} else {
-			// Add AMF Set to Authorized Network Slice Info
-			authorizedNetworkSliceInfo.CandidateAmfList = append(authorizedNetworkSliceInfo.CandidateAmfList, amfConfig.NfId)
-			hitAmf = true
-		}
-	}
-
-	if !hitAmf {
+	candidateAmfList := snapshot.FindAmfsServingNssai(tai, allowedNssai)
+	if len(candidateAmfList) == 0 {
 		logger.UtilLog.Warnf("No candidate AMF or AMF Set can serve the UE")
+		return
 	}
+	authorizedNetworkSliceInfo.CandidateAmfList = append(authorizedNetworkSliceInfo.CandidateAmfList,
+		candidateAmfList...)
 }
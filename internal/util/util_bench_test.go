@@ -0,0 +1,134 @@
+package util_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/free5gc/nssf/internal/testutil"
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+var benchmarkTaCounts = []int{100, 1000, 10000}
+
+// Set up a configuration with the given number of TAs and an AMF serving all of them
+func setupBenchmarkConfig(taCount int) []models.Tai {
+	supportedSnssaiList := []models.ExtSnssai{
+		{Sst: 1, Sd: "010203"},
+		{Sst: 1, Sd: "112233"},
+	}
+	taiList := testutil.SetupTaListConfig(models.PlmnId{Mcc: "208", Mnc: "93"}, supportedSnssaiList, taCount)
+
+	amfConfig := factory.AmfConfig{NfId: "469de254-2fe5-4ca0-8381-af3f500af77c"}
+	for i := range taiList {
+		amfConfig.SupportedNssaiAvailabilityData = append(amfConfig.SupportedNssaiAvailabilityData,
+			models.SupportedNssaiAvailabilityData{
+				Tai:                 &taiList[i],
+				SupportedSnssaiList: supportedSnssaiList,
+			})
+	}
+	factory.NssfConfig.Configuration.AmfList = []factory.AmfConfig{amfConfig}
+	return taiList
+}
+
+// Lookup by linear search under the lock of configuration, as done before configuration snapshot
+func checkSupportedSnssaiInTaLinear(snssai models.Snssai, tai models.Tai) bool {
+	factory.NssfConfig.RLock()
+	defer factory.NssfConfig.RUnlock()
+	for _, taConfig := range factory.NssfConfig.Configuration.TaList {
+		if reflect.DeepEqual(*taConfig.Tai, tai) {
+			return util.CheckSnssaiInNssai(snssai, taConfig.GetSupportedSnssaiList())
+		}
+	}
+	return false
+}
+
+func BenchmarkCheckSupportedSnssaiInTa(b *testing.B) {
+	snssai := models.Snssai{Sst: 1, Sd: "112233"}
+	for _, taCount := range benchmarkTaCounts {
+		taiList := setupBenchmarkConfig(taCount)
+		// The last TA is the worst case of linear search
+		tai := taiList[len(taiList)-1]
+
+		b.Run(fmt.Sprintf("Snapshot/%d", taCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !util.CheckSupportedSnssaiInTa(snssai, tai) {
+					b.Fatal("S-NSSAI should be supported in TA")
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Linear/%d", taCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if !checkSupportedSnssaiInTaLinear(snssai, tai) {
+					b.Fatal("S-NSSAI should be supported in TA")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCheckSupportedSnssaiInTaParallel(b *testing.B) {
+	snssai := models.Snssai{Sst: 1, Sd: "112233"}
+	for _, taCount := range benchmarkTaCounts {
+		taiList := setupBenchmarkConfig(taCount)
+
+		b.Run(fmt.Sprintf("Snapshot/%d", taCount), func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					util.CheckSupportedSnssaiInTa(snssai, taiList[i%len(taiList)])
+				}
+			})
+		})
+		b.Run(fmt.Sprintf("Linear/%d", taCount), func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					checkSupportedSnssaiInTaLinear(snssai, taiList[i%len(taiList)])
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkAddAmfInformation(b *testing.B) {
+	for _, taCount := range benchmarkTaCounts {
+		taiList := setupBenchmarkConfig(taCount)
+		tai := taiList[len(taiList)-1]
+
+		b.Run(fmt.Sprintf("Snapshot/%d", taCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				authorizedNetworkSliceInfo := &models.AuthorizedNetworkSliceInfo{
+					AllowedNssaiList: []models.AllowedNssai{
+						{
+							AllowedSnssaiList: []models.AllowedSnssai{
+								{AllowedSnssai: &models.Snssai{Sst: 1, Sd: "010203"}},
+								{AllowedSnssai: &models.Snssai{Sst: 1, Sd: "112233"}},
+							},
+							AccessType: models.AccessType__3_GPP_ACCESS,
+						},
+					},
+				}
+				util.AddAmfInformation(tai, authorizedNetworkSliceInfo)
+				if len(authorizedNetworkSliceInfo.CandidateAmfList) != 1 {
+					b.Fatal("AMF should be a candidate")
+				}
+			}
+		})
+	}
+}
+
+// Rebuilding the snapshot is the cost paid by writers on every change
+func BenchmarkRefreshSnapshot(b *testing.B) {
+	for _, taCount := range benchmarkTaCounts {
+		setupBenchmarkConfig(taCount)
+
+		b.Run(fmt.Sprintf("%d", taCount), func(b *testing.B) {
+			factory.NssfConfig.Lock()
+			defer factory.NssfConfig.Unlock()
+			for i := 0; i < b.N; i++ {
+				factory.NssfConfig.RefreshSnapshotLocked()
+			}
+		})
+	}
+}
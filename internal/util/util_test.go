@@ -0,0 +1,258 @@
+package util_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/free5gc/nssf/internal/util"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+func TestAuthorizeSupportedNssaiAvailabilityData(t *testing.T) {
+	const amfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+
+	plmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	tai := models.Tai{PlmnId: &plmnId, Tac: "000001"}
+	otherTai := models.Tai{PlmnId: &plmnId, Tac: "000002"}
+	snssai := models.ExtSnssai{Sst: 1, Sd: "010203"}
+	otherSnssai := models.ExtSnssai{Sst: 1, Sd: "112233"}
+	configuration := func(amfPolicyList []factory.AmfPolicy) *factory.Configuration {
+		return &factory.Configuration{
+			SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+				{
+					PlmnId:              &plmnId,
+					SupportedSnssaiList: []models.Snssai{{Sst: 1, Sd: "010203"}, {Sst: 1, Sd: "112233"}},
+				},
+			},
+			TaList: []factory.TaConfig{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{snssai, otherSnssai}},
+				{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{snssai}},
+			},
+			AmfPolicyList: amfPolicyList,
+		}
+	}
+
+	testCases := []struct {
+		name               string
+		amfPolicyList      []factory.AmfPolicy
+		s                  []models.SupportedNssaiAvailabilityData
+		expectAuthorized   []models.SupportedNssaiAvailabilityData
+		expectUnauthorized []models.SupportedNssaiAvailabilityData
+	}{
+		{
+			name: "Without policy, S-NSSAIs supported in TA are authorized",
+			s: []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{snssai, otherSnssai}},
+				{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{snssai, otherSnssai}},
+			},
+			expectAuthorized: []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{snssai, otherSnssai}},
+				{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{snssai}},
+			},
+			expectUnauthorized: []models.SupportedNssaiAvailabilityData{
+				{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{otherSnssai}},
+			},
+		},
+		{
+			name: "Policy limits S-NSSAIs per TA",
+			amfPolicyList: []factory.AmfPolicy{
+				{
+					NfId: amfId,
+					AuthorizedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{otherSnssai}},
+					},
+				},
+			},
+			s: []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{snssai, otherSnssai}},
+				{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{snssai}},
+			},
+			expectAuthorized: []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{otherSnssai}},
+			},
+			expectUnauthorized: []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{snssai}},
+				{Tai: &otherTai, SupportedSnssaiList: []models.ExtSnssai{snssai}},
+			},
+		},
+		{
+			name: "Policy entries without TAI authorize nothing",
+			amfPolicyList: []factory.AmfPolicy{
+				{
+					NfId: amfId,
+					AuthorizedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{SupportedSnssaiList: []models.ExtSnssai{snssai}},
+					},
+				},
+			},
+			s: []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{snssai}},
+			},
+			expectUnauthorized: []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{snssai}},
+			},
+		},
+		{
+			name: "Policy of another AMF is not applied",
+			amfPolicyList: []factory.AmfPolicy{
+				{NfId: "469de254-2fe5-4ca0-8381-af3f500af77d"},
+			},
+			s: []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{snssai}},
+			},
+			expectAuthorized: []models.SupportedNssaiAvailabilityData{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{snssai}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory.NssfConfig = &factory.Config{Configuration: configuration(tc.amfPolicyList)}
+
+			authorized, unauthorized := util.AuthorizeSupportedNssaiAvailabilityData(amfId, tc.s)
+			if !reflect.DeepEqual(authorized, tc.expectAuthorized) {
+				t.Errorf("Expected authorized %+v, got: %+v", tc.expectAuthorized, authorized)
+			}
+			if !reflect.DeepEqual(unauthorized, tc.expectUnauthorized) {
+				t.Errorf("Expected unauthorized %+v, got: %+v", tc.expectUnauthorized, unauthorized)
+			}
+		})
+	}
+}
+
+func TestGetAccessTypeListFromConfig(t *testing.T) {
+	plmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	tai := models.Tai{PlmnId: &plmnId, Tac: "000001"}
+	singleAccessTai := models.Tai{PlmnId: &plmnId, Tac: "000002"}
+	access3gpp := models.AccessType__3_GPP_ACCESS
+	accessNon3gpp := models.AccessType_NON_3_GPP_ACCESS
+	factory.NssfConfig = &factory.Config{
+		Configuration: &factory.Configuration{
+			TaList: []factory.TaConfig{
+				{
+					Tai: &tai,
+					AccessList: []factory.TaAccessConfig{
+						{
+							AccessType:          &access3gpp,
+							AccessNetwork:       factory.AccessNetworkNgRan,
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}, {Sst: 1, Sd: "010203"}},
+						},
+						{
+							AccessType:          &accessNon3gpp,
+							AccessNetwork:       factory.AccessNetworkN3iwf,
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}, {Sst: 2}},
+						},
+						{
+							AccessType:          &accessNon3gpp,
+							AccessNetwork:       factory.AccessNetworkWagf,
+							SupportedSnssaiList: []models.ExtSnssai{{Sst: 2}, {Sst: 3}},
+						},
+					},
+				},
+				{
+					Tai:                 &singleAccessTai,
+					AccessType:          &accessNon3gpp,
+					SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}},
+				},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name   string
+		snssai models.Snssai
+		tai    models.Tai
+		expect []models.AccessType
+	}{
+		{"Supported in 3GPP access only", models.Snssai{Sst: 1, Sd: "010203"}, tai, []models.AccessType{access3gpp}},
+		{"Supported in both access types", models.Snssai{Sst: 1}, tai, []models.AccessType{access3gpp, accessNon3gpp}},
+		{"Supported by access networks of non-3GPP access", models.Snssai{Sst: 2}, tai,
+			[]models.AccessType{accessNon3gpp}},
+		{"Not supported in TA", models.Snssai{Sst: 4}, tai, []models.AccessType{access3gpp, accessNon3gpp}},
+		{"Single access type of TA", models.Snssai{Sst: 1}, singleAccessTai, []models.AccessType{accessNon3gpp}},
+		{"Unknown TA", models.Snssai{Sst: 1}, models.Tai{PlmnId: &plmnId, Tac: "000003"}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if accessTypeList := util.GetAccessTypeListFromConfig(tc.snssai, tc.tai); !reflect.DeepEqual(
+				accessTypeList, tc.expect) {
+				t.Errorf("Expected access types %v, got: %v", tc.expect, accessTypeList)
+			}
+		})
+	}
+}
+
+func TestGetUnknownAccessTypeList(t *testing.T) {
+	access3gpp := models.AccessType__3_GPP_ACCESS
+	accessNon3gpp := models.AccessType_NON_3_GPP_ACCESS
+
+	testCases := []struct {
+		name       string
+		policy     string
+		candidates []models.AccessType
+		expect     []models.AccessType
+	}{
+		{"Default policy without candidates", "", nil, []models.AccessType{access3gpp}},
+		{
+			"Default policy with candidates", "",
+			[]models.AccessType{accessNon3gpp, access3gpp}, []models.AccessType{access3gpp},
+		},
+		{
+			"3GPP access policy without 3GPP access in candidates", factory.UnknownAccessType3gppAccess,
+			[]models.AccessType{accessNon3gpp}, []models.AccessType{accessNon3gpp},
+		},
+		{
+			"All access policy without candidates", factory.UnknownAccessTypeAllAccess,
+			nil, []models.AccessType{access3gpp, accessNon3gpp},
+		},
+		{
+			"All access policy with candidates", factory.UnknownAccessTypeAllAccess,
+			[]models.AccessType{accessNon3gpp}, []models.AccessType{accessNon3gpp},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			factory.NssfConfig = &factory.Config{
+				Configuration: &factory.Configuration{UnknownAccessTypePolicy: tc.policy},
+			}
+			if accessTypeList := util.GetUnknownAccessTypeList(tc.candidates); !reflect.DeepEqual(
+				accessTypeList, tc.expect) {
+				t.Errorf("Expected access types %v, got: %v", tc.expect, accessTypeList)
+			}
+		})
+	}
+}
+
+func TestAddAllowedSnssai(t *testing.T) {
+	access3gpp := models.AccessType__3_GPP_ACCESS
+	accessNon3gpp := models.AccessType_NON_3_GPP_ACCESS
+	allowedSnssai := func(sst int32) models.AllowedSnssai {
+		return models.AllowedSnssai{AllowedSnssai: &models.Snssai{Sst: sst}}
+	}
+
+	authorizedNetworkSliceInfo := &models.AuthorizedNetworkSliceInfo{}
+	util.AddAllowedSnssai(allowedSnssai(1), []models.AccessType{access3gpp, accessNon3gpp},
+		authorizedNetworkSliceInfo)
+	util.AddAllowedSnssai(allowedSnssai(2), []models.AccessType{accessNon3gpp}, authorizedNetworkSliceInfo)
+
+	// One Allowed NSSAI per access type
+	expected := []models.AllowedNssai{
+		{AllowedSnssaiList: []models.AllowedSnssai{allowedSnssai(1)}, AccessType: access3gpp},
+		{AllowedSnssaiList: []models.AllowedSnssai{allowedSnssai(1), allowedSnssai(2)}, AccessType: accessNon3gpp},
+	}
+	if !reflect.DeepEqual(authorizedNetworkSliceInfo.AllowedNssaiList, expected) {
+		t.Errorf("Expected Allowed NSSAI list %+v, got: %+v", expected, authorizedNetworkSliceInfo.AllowedNssaiList)
+	}
+
+	// At most 8 S-NSSAIs in an Allowed NSSAI
+	for sst := int32(3); sst <= 10; sst++ {
+		util.AddAllowedSnssai(allowedSnssai(sst), []models.AccessType{access3gpp}, authorizedNetworkSliceInfo)
+	}
+	if n := len(authorizedNetworkSliceInfo.AllowedNssaiList[0].AllowedSnssaiList); n != 8 {
+		t.Errorf("Expected 8 S-NSSAIs in Allowed NSSAI of 3GPP access, got: %d", n)
+	}
+}
@@ -7,9 +7,13 @@ package factory
 import (
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/asaskevich/govalidator"
 	"github.com/google/uuid"
@@ -19,29 +23,77 @@ import (
 )
 
 const (
-	NssfDefaultTLSKeyLogPath      = "./log/nssfsslkey.log"
-	NssfDefaultCertPemPath        = "./cert/nssf.pem"
-	NssfDefaultPrivateKeyPath     = "./cert/nssf.key"
-	NssfDefaultConfigPath         = "./config/nssfcfg.yaml"
-	NssfDefaultNfInstanceIdEnvVar = "NSSF_NF_INSTANCE_ID"
-	NssfSbiDefaultIPv4            = "127.0.0.31"
-	NssfSbiDefaultPort            = 8000
-	NssfSbiDefaultScheme          = "https"
-	NssfDefaultNrfUri             = "https://127.0.0.10:8000"
-	NssfMetricsDefaultEnabled     = false
-	NssfMetricsDefaultPort        = 9091
-	NssfMetricsDefaultScheme      = "https"
-	NssfMetricsDefaultNamespace   = "free5gc"
-	NssfNssaiavailResUriPrefix    = "/nnssf-nssaiavailability/v1"
-	NssfNsselectResUriPrefix      = "/nnssf-nsselection/v2"
+	NssfDefaultTLSKeyLogPath        = "./log/nssfsslkey.log"
+	NssfDefaultCertPemPath          = "./cert/nssf.pem"
+	NssfDefaultPrivateKeyPath       = "./cert/nssf.key"
+	NssfDefaultConfigPath           = "./config/nssfcfg.yaml"
+	NssfDefaultNfInstanceIdEnvVar   = "NSSF_NF_INSTANCE_ID"
+	NssfSbiDefaultIPv4              = "127.0.0.31"
+	NssfSbiDefaultPort              = 8000
+	NssfSbiDefaultScheme            = "https"
+	NssfDefaultNrfUri               = "https://127.0.0.10:8000"
+	NssfMetricsDefaultEnabled       = false
+	NssfMetricsDefaultPort          = 9091
+	NssfMetricsDefaultScheme        = "https"
+	NssfMetricsDefaultNamespace     = "free5gc"
+	NssfTracingDefaultEnabled       = false
+	NssfTracingDefaultExporter      = TracingExporterOtlp
+	NssfTracingDefaultEndpoint      = "127.0.0.1:4318"
+	NssfTracingDefaultSampleRatio   = 1.0
+	NssfShutdownDefaultDrainTimeout = 10 * time.Second
+	NssfManagementDefaultIPv4       = "127.0.0.1"
+	NssfManagementDefaultPort       = 8001
+	NssfNssaiavailResUriPrefix      = "/nnssf-nssaiavailability/v1"
+	NssfNsselectResUriPrefix        = "/nnssf-nsselection/v2"
+	NssfCallbackResUriPrefix        = "/nnssf-callback/v1"
+	NssfManagementResUriPrefix      = "/nssf-mgmt/v1"
+)
+
+// Policies of Allowed NSSAI when UE's Access Type could not be identified
+const (
+	// Only return S-NSSAIs in 3GPP Access
+	UnknownAccessType3gppAccess = "3GPP_ACCESS"
+	// Return S-NSSAIs in all valid Access Types
+	UnknownAccessTypeAllAccess = "ALL_ACCESS"
+)
+
+// Modes of Network Slice Admission Control
+const (
+	// Count UEs and PDU sessions holding network slices in NSSF
+	NsacModeLocal = "local"
+	// Consult the NSACF
+	NsacModeNsacf = "nsacf"
+)
+
+// Access networks through which S-NSSAIs in a TA could be reached
+const (
+	AccessNetworkNgRan = "NG-RAN"
+	AccessNetworkN3iwf = "N3IWF"
+	AccessNetworkTngf  = "TNGF"
+	// Wireline access through W-AGF
+	AccessNetworkWagf = "W-AGF"
+)
+
+// Exporters of OpenTelemetry traces
+const (
+	// Export to an OpenTelemetry collector over OTLP/HTTP
+	TracingExporterOtlp = "otlp"
+	// Write spans as JSON to a file or stdout for offline use
+	TracingExporterFile   = "file"
+	TracingExporterStdout = "stdout"
 )
 
 type Config struct {
 	Info          *Info          `yaml:"info" valid:"required"`
 	Configuration *Configuration `yaml:"configuration" valid:"required"`
+	// Subscriptions provisioned at startup, which are loaded into the subscription registry of NSSF context
 	Subscriptions []Subscription `yaml:"subscriptions,omitempty"`
 	Logger        *Logger        `yaml:"logger" valid:"required"`
 	sync.RWMutex
+	// Indexed snapshot of Configuration, which is replaced as a whole on every change
+	snapshot atomic.Pointer[Snapshot]
+	// Sources of effective values when the configuration is loaded
+	sources []ConfigSource
 }
 
 func (c *Config) Validate() (bool, error) {
@@ -68,6 +120,9 @@ type Configuration struct {
 	NfInstanceId             string                  `yaml:"nfInstanceId,omitempty" valid:"optional,uuidv4"`
 	Sbi                      *Sbi                    `yaml:"sbi"`
 	Metrics                  *Metrics                `yaml:"metrics,omitempty" valid:"optional"`
+	Tracing                  *Tracing                `yaml:"tracing,omitempty" valid:"optional"`
+	Management               *Management             `yaml:"management,omitempty" valid:"optional"`
+	Shutdown                 *Shutdown               `yaml:"shutdown,omitempty" valid:"optional"`
 	ServiceNameList          []models.ServiceName    `yaml:"serviceNameList"`
 	NrfUri                   string                  `yaml:"nrfUri"`
 	NrfCertPem               string                  `yaml:"nrfCertPem,omitempty" valid:"optional"`
@@ -78,6 +133,11 @@ type Configuration struct {
 	AmfList                  []AmfConfig             `yaml:"amfList"`
 	TaList                   []TaConfig              `yaml:"taList"`
 	MappingListFromPlmn      []MappingFromPlmnConfig `yaml:"mappingListFromPlmn"`
+	// nolint: lll
+	UnknownAccessTypePolicy string              `yaml:"unknownAccessTypePolicy,omitempty" valid:"optional,in(3GPP_ACCESS|ALL_ACCESS)"`
+	Nsac                    *NsacConfig         `yaml:"nsac,omitempty" valid:"optional"`
+	AmfPolicyList           []AmfPolicy         `yaml:"amfPolicyList,omitempty"`
+	Notification            *NotificationConfig `yaml:"notification,omitempty" valid:"optional"`
 }
 
 type Logger struct {
@@ -111,6 +171,25 @@ func (c *Configuration) validate() (bool, error) {
 		}
 	}
 
+	if c.Tracing != nil {
+		if result, err := c.Tracing.validate(); err != nil {
+			return result, err
+		}
+	}
+
+	if c.Management != nil {
+		if result, err := c.Management.validate(c.Sbi); err != nil {
+			return result, err
+		}
+	}
+
+	if c.Shutdown != nil && c.Shutdown.DrainTimeout < 0 {
+		var errs govalidator.Errors
+		errs = append(errs, fmt.Errorf("invalid shutdown.drainTimeout: %s, should not be negative",
+			c.Shutdown.DrainTimeout))
+		return false, error(errs)
+	}
+
 	for index, serviceName := range c.ServiceNameList {
 		switch serviceName {
 		case "nnssf-nsselection":
@@ -136,6 +215,34 @@ func (c *Configuration) validate() (bool, error) {
 		}
 	}
 
+	if c.Nsac != nil {
+		if result, err := c.Nsac.validate(); err != nil {
+			return result, err
+		}
+	}
+
+	if c.Notification != nil {
+		if result, err := c.Notification.validate(); err != nil {
+			return result, err
+		}
+	}
+
+	for index, taConfig := range c.TaList {
+		if result, err := taConfig.validate(); err != nil {
+			var errs govalidator.Errors
+			errs = append(errs, fmt.Errorf("invalid taList[%d].%w", index, err))
+			return result, error(errs)
+		}
+	}
+
+	for index, amfPolicy := range c.AmfPolicyList {
+		if result, err := amfPolicy.validate(); err != nil {
+			var errs govalidator.Errors
+			errs = append(errs, fmt.Errorf("invalid amfPolicyList[%d].%w", index, err))
+			return result, error(errs)
+		}
+	}
+
 	result, err := govalidator.ValidateStruct(c)
 	return result, appendInvalid(err)
 }
@@ -171,7 +278,7 @@ type Sbi struct {
 	RegisterIPv4 string `yaml:"registerIPv4,omitempty" valid:"host,required"` // IP that is registered at NRF.
 	// IPv6Addr string `yaml:"ipv6Addr,omitempty"`
 	BindingIPv4 string `yaml:"bindingIPv4,omitempty" valid:"host,required"` // IP used to run the server in the node.
-	Port        int    `yaml:"port"`
+	Port        int    `yaml:"port" valid:"optional,port"`
 	Tls         *Tls   `yaml:"tls,omitempty" valid:"optional"`
 }
 
@@ -226,6 +333,95 @@ func (m *Metrics) validate() (bool, error) {
 	return true, nil
 }
 
+// Export of OpenTelemetry traces of SBI requests and outbound calls
+type Tracing struct {
+	Enable   bool   `yaml:"enable" valid:"optional"`
+	Exporter string `yaml:"exporter,omitempty" valid:"optional,in(otlp|file|stdout)"`
+	// host:port of OTLP/HTTP collector
+	Endpoint string `yaml:"endpoint,omitempty" valid:"optional,dialstring"`
+	// Export to OTLP/HTTP collector without TLS
+	Insecure bool `yaml:"insecure,omitempty"`
+	// Path of the file to which spans are appended by the file exporter
+	File string `yaml:"file,omitempty" valid:"optional"`
+	// Ratio of traces sampled among those started by NSSF, sampling decisions of callers are respected
+	// All traces are sampled if not set
+	SampleRatio float64 `yaml:"sampleRatio,omitempty"`
+}
+
+func (t *Tracing) validate() (bool, error) {
+	var errs govalidator.Errors
+
+	if t.Exporter == TracingExporterFile && t.File == "" {
+		errs = append(errs, fmt.Errorf("tracing.file is required with %s exporter", TracingExporterFile))
+	}
+
+	if t.SampleRatio < 0 || t.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("invalid tracing.sampleRatio: %v, should be between 0 and 1", t.SampleRatio))
+	}
+
+	if _, err := govalidator.ValidateStruct(t); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return false, error(errs)
+	}
+	return true, nil
+}
+
+// Management API for operators, served on its own listener apart from the SBI interface
+// so that NF service consumers could not reach it, and authenticated with a bearer token
+type Management struct {
+	Enable      bool   `yaml:"enable" valid:"optional"`
+	BindingIPv4 string `yaml:"bindingIPv4,omitempty" valid:"optional,host"`
+	Port        int    `yaml:"port,omitempty" valid:"optional,port"`
+	// File holding the bearer token required in Authorization header of every request
+	TokenFile string `yaml:"tokenFile,omitempty" valid:"optional"`
+}
+
+func (m *Management) validate(sbi *Sbi) (bool, error) {
+	var errs govalidator.Errors
+
+	if _, err := govalidator.ValidateStruct(m); err != nil {
+		errs = append(errs, err)
+	}
+
+	if m.Enable {
+		if m.TokenFile == "" {
+			errs = append(errs, errors.New("management.tokenFile is required when management is enabled"))
+		}
+		if sbi != nil && m.getBindingIPv4() == sbi.BindingIPv4 && m.getPort() == sbi.Port {
+			errs = append(errs, fmt.Errorf("sbi and management bindings IPv4: %s and port: %d cannot be the same",
+				sbi.BindingIPv4, sbi.Port))
+		}
+	}
+
+	if len(errs) > 0 {
+		return false, error(errs)
+	}
+	return true, nil
+}
+
+func (m *Management) getBindingIPv4() string {
+	if m.BindingIPv4 != "" {
+		return m.BindingIPv4
+	}
+	return NssfManagementDefaultIPv4
+}
+
+func (m *Management) getPort() int {
+	if m.Port != 0 {
+		return m.Port
+	}
+	return NssfManagementDefaultPort
+}
+
+// Graceful shutdown of NSSF
+type Shutdown struct {
+	// Time allowed for in-flight requests and pending notifications to complete once requests are no longer accepted
+	DrainTimeout time.Duration `yaml:"drainTimeout,omitempty"`
+}
+
 func appendInvalid(err error) error {
 	var errs govalidator.Errors
 
@@ -244,6 +440,31 @@ func appendInvalid(err error) error {
 type AmfConfig struct {
 	NfId                           string                                  `yaml:"nfId"`
 	SupportedNssaiAvailabilityData []models.SupportedNssaiAvailabilityData `yaml:"supportedNssaiAvailabilityData"`
+	// Supported features negotiated with the AMF when NSSAI availability information is updated
+	SupportedFeatures string `yaml:"supportedFeatures,omitempty"`
+	// AMF set ID reported by the AMF when NSSAI availability information is updated
+	AmfSetId string `yaml:"amfSetId,omitempty"`
+	// Version of NSSAI availability information, which is increased on every update and used as entity tag
+	Version uint64 `yaml:"-"`
+}
+
+// Operator policy of S-NSSAIs which the AMF is authorized to serve per TA
+// An AMF without policy is authorized to serve all S-NSSAIs supported in the TAs
+type AmfPolicy struct {
+	NfId                            string                                  `yaml:"nfId"`
+	AuthorizedNssaiAvailabilityData []models.SupportedNssaiAvailabilityData `yaml:"authorizedNssaiAvailabilityData"`
+}
+
+func (a *AmfPolicy) validate() (bool, error) {
+	if a.NfId == "" {
+		return false, errors.New("nfId is required")
+	}
+	for index, authorizedNssaiAvailabilityData := range a.AuthorizedNssaiAvailabilityData {
+		if tai := authorizedNssaiAvailabilityData.Tai; tai == nil || tai.PlmnId == nil {
+			return false, fmt.Errorf("authorizedNssaiAvailabilityData[%d].tai and its plmnId are required", index)
+		}
+	}
+	return true, nil
 }
 
 type TaConfig struct {
@@ -251,6 +472,86 @@ type TaConfig struct {
 	AccessType           *models.AccessType        `yaml:"accessType"`
 	SupportedSnssaiList  []models.ExtSnssai        `yaml:"supportedSnssaiList"`
 	RestrictedSnssaiList []models.RestrictedSnssai `yaml:"restrictedSnssaiList,omitempty"`
+	// S-NSSAIs supported per Access Type
+	// If provided, `accessType` and `supportedSnssaiList` above are ignored
+	AccessList []TaAccessConfig `yaml:"accessList,omitempty"`
+}
+
+type TaAccessConfig struct {
+	AccessType *models.AccessType `yaml:"accessType"`
+	// nolint: lll
+	AccessNetwork       string             `yaml:"accessNetwork,omitempty" valid:"optional,in(NG-RAN|N3IWF|TNGF|W-AGF)"`
+	SupportedSnssaiList []models.ExtSnssai `yaml:"supportedSnssaiList"`
+}
+
+func (t *TaConfig) validate() (bool, error) {
+	for index, access := range t.AccessList {
+		if access.AccessType == nil {
+			return false, fmt.Errorf("accessList[%d].accessType is required", index)
+		}
+
+		switch access.AccessNetwork {
+		case "":
+		case AccessNetworkNgRan:
+			if *access.AccessType != models.AccessType__3_GPP_ACCESS {
+				return false, fmt.Errorf("accessList[%d].accessNetwork %s should be of %s",
+					index, access.AccessNetwork, models.AccessType__3_GPP_ACCESS)
+			}
+		default:
+			if *access.AccessType != models.AccessType_NON_3_GPP_ACCESS {
+				return false, fmt.Errorf("accessList[%d].accessNetwork %s should be of %s",
+					index, access.AccessNetwork, models.AccessType_NON_3_GPP_ACCESS)
+			}
+		}
+
+		if _, err := govalidator.ValidateStruct(access); err != nil {
+			return false, fmt.Errorf("accessList[%d]: %w", index, err)
+		}
+	}
+	return true, nil
+}
+
+// Get S-NSSAIs supported per Access Type in the TA
+// Falls back to the single `accessType` if no `accessList` is configured
+func (t *TaConfig) GetAccessList() []TaAccessConfig {
+	if len(t.AccessList) != 0 {
+		return t.AccessList
+	}
+
+	accessType := models.AccessType__3_GPP_ACCESS
+	if t.AccessType != nil {
+		accessType = *t.AccessType
+	}
+	return []TaAccessConfig{
+		{
+			AccessType:          &accessType,
+			SupportedSnssaiList: t.SupportedSnssaiList,
+		},
+	}
+}
+
+// Get S-NSSAIs supported in the TA in any Access Type
+func (t *TaConfig) GetSupportedSnssaiList() []models.ExtSnssai {
+	if len(t.AccessList) == 0 {
+		return t.SupportedSnssaiList
+	}
+
+	var supportedSnssaiList []models.ExtSnssai
+	for _, access := range t.AccessList {
+		for _, snssai := range access.SupportedSnssaiList {
+			hit := false
+			for _, s := range supportedSnssaiList {
+				if s.Sst == snssai.Sst && strings.EqualFold(s.Sd, snssai.Sd) {
+					hit = true
+					break
+				}
+			}
+			if !hit {
+				supportedSnssaiList = append(supportedSnssaiList, snssai)
+			}
+		}
+	}
+	return supportedSnssaiList
 }
 
 type SupportedNssaiInPlmn struct {
@@ -270,6 +571,107 @@ type AmfSetConfig struct {
 	SupportedNssaiAvailabilityData []models.SupportedNssaiAvailabilityData `yaml:"supportedNssaiAvailabilityData"`
 }
 
+type NsacConfig struct {
+	Enable    bool          `yaml:"enable" valid:"optional"`
+	Mode      string        `yaml:"mode" valid:"required,in(local|nsacf)"`
+	NsacfUri  string        `yaml:"nsacfUri,omitempty" valid:"optional,url"`
+	QuotaList []SnssaiQuota `yaml:"quotaList,omitempty"`
+}
+
+// Delivery policy of NSSAI availability notifications to subscribers
+type NotificationConfig struct {
+	// Schemes allowed in callback URIs, both "http" and "https" are allowed if empty
+	AllowedSchemes []string `yaml:"allowedSchemes,omitempty"`
+	// Hosts allowed in callback URIs, given as host names, "*.<domain>" wildcards, IP addresses or CIDRs
+	// All hosts are allowed if empty
+	AllowedHosts []string `yaml:"allowedHosts,omitempty"`
+	// Private, loopback and link-local targets are rejected unless allowed here or listed in AllowedHosts
+	AllowPrivateTargets bool `yaml:"allowPrivateTargets,omitempty"`
+	// PEM file of CA certificates to verify subscribers over TLS, system roots are used if empty
+	CaCertPem          string `yaml:"caCertPem,omitempty" valid:"optional"`
+	InsecureSkipVerify bool   `yaml:"insecureSkipVerify,omitempty"`
+}
+
+func (n *NotificationConfig) validate() (bool, error) {
+	var errs govalidator.Errors
+
+	for index, scheme := range n.AllowedSchemes {
+		if scheme != "http" && scheme != "https" {
+			errs = append(errs, fmt.Errorf("invalid notification.allowedSchemes[%d]: %s, should be http or https",
+				index, scheme))
+		}
+	}
+
+	for index, host := range n.AllowedHosts {
+		if _, _, err := net.ParseCIDR(host); err == nil {
+			continue
+		}
+		if !govalidator.IsHost(strings.TrimPrefix(host, "*.")) {
+			errs = append(errs, fmt.Errorf("invalid notification.allowedHosts[%d]: %s", index, host))
+		}
+	}
+
+	if n.CaCertPem != "" {
+		if _, err := os.Stat(n.CaCertPem); err != nil {
+			errs = append(errs, fmt.Errorf("invalid notification.caCertPem: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return false, error(errs)
+	}
+	return true, nil
+}
+
+// Quota of an S-NSSAI in the PLMN and optionally in specific TAs
+// A zero maximum indicates that the number is not limited
+type SnssaiQuota struct {
+	Snssai              *models.Snssai `yaml:"snssai"`
+	MaxNumOfUes         int            `yaml:"maxNumOfUes,omitempty"`
+	MaxNumOfPduSessions int            `yaml:"maxNumOfPduSessions,omitempty"`
+	TaQuotaList         []TaQuota      `yaml:"taQuotaList,omitempty"`
+}
+
+type TaQuota struct {
+	Tai                 *models.Tai `yaml:"tai"`
+	MaxNumOfUes         int         `yaml:"maxNumOfUes,omitempty"`
+	MaxNumOfPduSessions int         `yaml:"maxNumOfPduSessions,omitempty"`
+}
+
+func (n *NsacConfig) validate() (bool, error) {
+	var errs govalidator.Errors
+
+	if n.Mode == NsacModeNsacf && n.NsacfUri == "" {
+		errs = append(errs, fmt.Errorf("nsac.nsacfUri is required in %s mode", NsacModeNsacf))
+	}
+
+	for index, quota := range n.QuotaList {
+		if quota.Snssai == nil {
+			errs = append(errs, fmt.Errorf("nsac.quotaList[%d].snssai is required", index))
+		}
+		if quota.MaxNumOfUes < 0 || quota.MaxNumOfPduSessions < 0 {
+			errs = append(errs, fmt.Errorf("nsac.quotaList[%d] should not be negative", index))
+		}
+		for i, taQuota := range quota.TaQuotaList {
+			if taQuota.Tai == nil {
+				errs = append(errs, fmt.Errorf("nsac.quotaList[%d].taQuotaList[%d].tai is required", index, i))
+			}
+			if taQuota.MaxNumOfUes < 0 || taQuota.MaxNumOfPduSessions < 0 {
+				errs = append(errs, fmt.Errorf("nsac.quotaList[%d].taQuotaList[%d] should not be negative", index, i))
+			}
+		}
+	}
+
+	if _, err := govalidator.ValidateStruct(n); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return false, error(errs)
+	}
+	return true, nil
+}
+
 type MappingFromPlmnConfig struct {
 	OperatorName    string                   `yaml:"operatorName,omitempty"`
 	HomePlmnId      *models.PlmnId           `yaml:"homePlmnId"`
@@ -365,6 +767,72 @@ func (c *Config) GetLogReportCaller() bool {
 	return c.Logger.ReportCaller
 }
 
+func (c *Config) GetUnknownAccessTypePolicy() string {
+	c.RLock()
+	defer c.RUnlock()
+	if c.Configuration != nil && c.Configuration.UnknownAccessTypePolicy != "" {
+		return c.Configuration.UnknownAccessTypePolicy
+	}
+	return UnknownAccessType3gppAccess
+}
+
+func (c *Config) IsNsacEnabled() bool {
+	c.RLock()
+	defer c.RUnlock()
+	if c.Configuration != nil && c.Configuration.Nsac != nil {
+		return c.Configuration.Nsac.Enable
+	}
+	return false
+}
+
+func (c *Config) GetNsacMode() string {
+	c.RLock()
+	defer c.RUnlock()
+	if c.Configuration != nil && c.Configuration.Nsac != nil && c.Configuration.Nsac.Mode != "" {
+		return c.Configuration.Nsac.Mode
+	}
+	return NsacModeLocal
+}
+
+func (c *Config) GetNsacfUri() string {
+	c.RLock()
+	defer c.RUnlock()
+	if c.Configuration != nil && c.Configuration.Nsac != nil {
+		return c.Configuration.Nsac.NsacfUri
+	}
+	return ""
+}
+
+// Get the snapshot of configuration for lookups without locking
+// The snapshot is built on first use, so the caller must not hold the write lock of configuration
+func (c *Config) Snapshot() *Snapshot {
+	if s := c.snapshot.Load(); s != nil {
+		return s
+	}
+
+	c.RLock()
+	s := newSnapshot(c.Configuration)
+	c.RUnlock()
+	// A snapshot published by a writer in the meantime is newer than the one built here
+	c.snapshot.CompareAndSwap(nil, s)
+	return c.snapshot.Load()
+}
+
+// Rebuild and publish the snapshot after configuration is changed
+// The caller must hold the write lock of configuration
+func (c *Config) RefreshSnapshotLocked() {
+	c.snapshot.Store(newSnapshot(c.Configuration))
+}
+
+func (c *Config) GetNotificationConfig() NotificationConfig {
+	c.RLock()
+	defer c.RUnlock()
+	if c.Configuration != nil && c.Configuration.Notification != nil {
+		return *c.Configuration.Notification
+	}
+	return NotificationConfig{}
+}
+
 func (c *Config) AreMetricsEnabled() bool {
 	c.RLock()
 	defer c.RUnlock()
@@ -446,3 +914,77 @@ func (c *Config) GetMetricsNamespace() string {
 	}
 	return NssfMetricsDefaultNamespace
 }
+
+func (c *Config) IsTracingEnabled() bool {
+	c.RLock()
+	defer c.RUnlock()
+	if c.Configuration != nil && c.Configuration.Tracing != nil {
+		return c.Configuration.Tracing.Enable
+	}
+	return NssfTracingDefaultEnabled
+}
+
+// Get tracing configuration with defaults applied to the fields not set
+func (c *Config) GetTracingConfig() Tracing {
+	c.RLock()
+	defer c.RUnlock()
+	tracing := Tracing{Enable: NssfTracingDefaultEnabled}
+	if c.Configuration != nil && c.Configuration.Tracing != nil {
+		tracing = *c.Configuration.Tracing
+	}
+	if tracing.Exporter == "" {
+		tracing.Exporter = NssfTracingDefaultExporter
+	}
+	if tracing.Endpoint == "" {
+		tracing.Endpoint = NssfTracingDefaultEndpoint
+	}
+	if tracing.SampleRatio == 0 {
+		tracing.SampleRatio = NssfTracingDefaultSampleRatio
+	}
+	return tracing
+}
+
+// Get the effective values with their sources when the configuration is loaded
+func (c *Config) GetConfigSources() []ConfigSource {
+	c.RLock()
+	defer c.RUnlock()
+	return c.sources
+}
+
+func (c *Config) IsManagementEnabled() bool {
+	c.RLock()
+	defer c.RUnlock()
+	if c.Configuration != nil && c.Configuration.Management != nil {
+		return c.Configuration.Management.Enable
+	}
+	return false
+}
+
+// Get the address of the listener of management API
+func (c *Config) GetManagementBindingAddr() string {
+	c.RLock()
+	defer c.RUnlock()
+	management := &Management{}
+	if c.Configuration != nil && c.Configuration.Management != nil {
+		management = c.Configuration.Management
+	}
+	return fmt.Sprintf("%s:%d", management.getBindingIPv4(), management.getPort())
+}
+
+func (c *Config) GetManagementTokenFile() string {
+	c.RLock()
+	defer c.RUnlock()
+	if c.Configuration != nil && c.Configuration.Management != nil {
+		return c.Configuration.Management.TokenFile
+	}
+	return ""
+}
+
+func (c *Config) GetShutdownDrainTimeout() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	if c.Configuration != nil && c.Configuration.Shutdown != nil && c.Configuration.Shutdown.DrainTimeout != 0 {
+		return c.Configuration.Shutdown.DrainTimeout
+	}
+	return NssfShutdownDefaultDrainTimeout
+}
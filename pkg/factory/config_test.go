@@ -0,0 +1,176 @@
+package factory
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/free5gc/openapi/models"
+)
+
+func TestAmfPolicyListValidate(t *testing.T) {
+	const amfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+
+	tai := models.Tai{PlmnId: &models.PlmnId{Mcc: "208", Mnc: "93"}, Tac: "000001"}
+	testCases := []struct {
+		name          string
+		amfPolicyList []AmfPolicy
+		expectErr     string
+	}{
+		{
+			name: "Valid policy",
+			amfPolicyList: []AmfPolicy{
+				{
+					NfId: amfId,
+					AuthorizedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}}},
+					},
+				},
+			},
+		},
+		{
+			name:          "Missing nfId",
+			amfPolicyList: []AmfPolicy{{}},
+			expectErr:     "invalid amfPolicyList[0].nfId is required",
+		},
+		{
+			name: "Missing tai",
+			amfPolicyList: []AmfPolicy{
+				{NfId: amfId},
+				{
+					NfId: amfId,
+					AuthorizedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{Tai: &tai},
+						{SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}}},
+					},
+				},
+			},
+			expectErr: "invalid amfPolicyList[1].authorizedNssaiAvailabilityData[1].tai",
+		},
+		{
+			name: "Missing plmnId of tai",
+			amfPolicyList: []AmfPolicy{
+				{
+					NfId: amfId,
+					AuthorizedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{Tai: &models.Tai{Tac: "000001"}},
+					},
+				},
+			},
+			expectErr: "invalid amfPolicyList[0].authorizedNssaiAvailabilityData[0].tai",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Configuration{AmfPolicyList: tc.amfPolicyList}
+			_, err := c.validate()
+			if tc.expectErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %+v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestTaListValidate(t *testing.T) {
+	tai := models.Tai{PlmnId: &models.PlmnId{Mcc: "208", Mnc: "93"}, Tac: "000001"}
+	access3gpp := models.AccessType__3_GPP_ACCESS
+	accessNon3gpp := models.AccessType_NON_3_GPP_ACCESS
+
+	testCases := []struct {
+		name       string
+		accessList []TaAccessConfig
+		expectErr  string
+	}{
+		{
+			name: "Access types without access networks",
+			accessList: []TaAccessConfig{
+				{AccessType: &access3gpp},
+				{AccessType: &accessNon3gpp},
+			},
+		},
+		{
+			name: "Access networks matching access types",
+			accessList: []TaAccessConfig{
+				{AccessType: &access3gpp, AccessNetwork: AccessNetworkNgRan},
+				{AccessType: &accessNon3gpp, AccessNetwork: AccessNetworkN3iwf},
+				{AccessType: &accessNon3gpp, AccessNetwork: AccessNetworkTngf},
+				{AccessType: &accessNon3gpp, AccessNetwork: AccessNetworkWagf},
+			},
+		},
+		{
+			name:       "Missing accessType",
+			accessList: []TaAccessConfig{{AccessType: &access3gpp}, {AccessNetwork: AccessNetworkN3iwf}},
+			expectErr:  "invalid taList[0].accessList[1].accessType is required",
+		},
+		{
+			name:       "NG-RAN of non-3GPP access",
+			accessList: []TaAccessConfig{{AccessType: &accessNon3gpp, AccessNetwork: AccessNetworkNgRan}},
+			expectErr:  "invalid taList[0].accessList[0].accessNetwork NG-RAN should be of 3GPP_ACCESS",
+		},
+		{
+			name:       "N3IWF of 3GPP access",
+			accessList: []TaAccessConfig{{AccessType: &access3gpp, AccessNetwork: AccessNetworkN3iwf}},
+			expectErr:  "invalid taList[0].accessList[0].accessNetwork N3IWF should be of NON_3GPP_ACCESS",
+		},
+		{
+			name:       "Unknown access network",
+			accessList: []TaAccessConfig{{AccessType: &accessNon3gpp, AccessNetwork: "WLAN"}},
+			expectErr:  "invalid taList[0].accessList[0]",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &Configuration{TaList: []TaConfig{{Tai: &tai, AccessList: tc.accessList}}}
+			_, err := c.validate()
+			if tc.expectErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %+v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+				t.Errorf("Expected error containing %q, got: %v", tc.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestTaConfigAccessList(t *testing.T) {
+	accessNon3gpp := models.AccessType_NON_3_GPP_ACCESS
+
+	// Without accessList, the single accessType defaulting to 3GPP access is used
+	taConfig := TaConfig{SupportedSnssaiList: []models.ExtSnssai{{Sst: 1}}}
+	accessList := taConfig.GetAccessList()
+	if len(accessList) != 1 || *accessList[0].AccessType != models.AccessType__3_GPP_ACCESS {
+		t.Errorf("Expected 3GPP access only, got: %+v", accessList)
+	}
+	taConfig.AccessType = &accessNon3gpp
+	accessList = taConfig.GetAccessList()
+	if len(accessList) != 1 || *accessList[0].AccessType != accessNon3gpp ||
+		len(accessList[0].SupportedSnssaiList) != 1 {
+		t.Errorf("Expected non-3GPP access with the supported S-NSSAIs, got: %+v", accessList)
+	}
+
+	// With accessList, accessType and supportedSnssaiList are ignored, and S-NSSAIs of all access types are
+	// supported once regardless of the case of SDs
+	access3gpp := models.AccessType__3_GPP_ACCESS
+	taConfig.AccessList = []TaAccessConfig{
+		{AccessType: &access3gpp, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "0a0b0c"}, {Sst: 2}}},
+		{AccessType: &accessNon3gpp, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "0A0B0C"}, {Sst: 3}}},
+	}
+	if accessList = taConfig.GetAccessList(); len(accessList) != 2 {
+		t.Errorf("Expected 2 access types, got: %+v", accessList)
+	}
+	expected := []models.ExtSnssai{{Sst: 1, Sd: "0a0b0c"}, {Sst: 2}, {Sst: 3}}
+	if supported := taConfig.GetSupportedSnssaiList(); !reflect.DeepEqual(supported, expected) {
+		t.Errorf("Expected supported S-NSSAIs %+v, got: %+v", expected, supported)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"os"
 
 	"github.com/asaskevich/govalidator"
-	"gopkg.in/yaml.v2"
 
 	"github.com/free5gc/nssf/internal/logger"
 )
@@ -17,27 +16,16 @@ import (
 var NssfConfig *Config
 
 // TODO: Support configuration update from REST api
+// Environment variables are not applied, so that configuration loaded by embedders is exactly the one in the file
 func InitConfigFactory(f string, cfg *Config) error {
-	if f == "" {
-		// Use default config path
-		f = NssfDefaultConfigPath
-	}
-
-	if content, err := os.ReadFile(f); err != nil {
-		return fmt.Errorf("[Factory] %+v", err)
-	} else {
-		logger.CfgLog.Infof("Read config from [%s]", f)
-		if yamlErr := yaml.Unmarshal(content, cfg); yamlErr != nil {
-			return fmt.Errorf("[Factory] %+v", yamlErr)
-		}
-	}
-
-	return nil
+	return InitConfigLayers(f, nil, nil, cfg)
 }
 
-func ReadConfig(cfgPath string) (*Config, error) {
+// Read configuration from the file, with fragments in drop-in directories merged over it in order,
+// and environment variables prefixed with NssfConfigEnvPrefix over them
+func ReadConfig(cfgPath string, dropInDirs ...string) (*Config, error) {
 	cfg := &Config{}
-	if err := InitConfigFactory(cfgPath, cfg); err != nil {
+	if err := InitConfigLayers(cfgPath, dropInDirs, os.Environ(), cfg); err != nil {
 		return nil, fmt.Errorf("ReadConfig [%s] Error: %+v", cfgPath, err)
 	}
 	if _, err := cfg.Validate(); err != nil {
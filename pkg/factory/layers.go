@@ -0,0 +1,375 @@
+/*
+ * NSSF Configuration Factory
+ *
+ * Layered configuration with the source of every effective value
+ */
+
+package factory
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/free5gc/nssf/internal/logger"
+)
+
+// Prefix of environment variables overriding configuration
+// A field is addressed by its YAML path in upper case, with "_" between keys and list indices,
+// e.g. NSSF_CONFIGURATION_SBI_PORT or NSSF_CONFIGURATION_TALIST_0_TAI
+// Since the name is split on "_", only YAML keys without "_" could be addressed, and keys are matched
+// case-insensitively. An item could be appended to a list at the index of its length. Values other than
+// strings are parsed as YAML, so that a list or a map could be set as a whole in flow style, e.g. "[1, 2]"
+const NssfConfigEnvPrefix = "NSSF_"
+
+// Sources of configuration values other than files and environment variables
+const (
+	ConfigSourceDefault = "default"
+)
+
+// Effective configuration value and where it comes from
+type ConfigSource struct {
+	// YAML path of the value
+	Path   string
+	Value  string
+	Source string
+}
+
+// Configuration being merged layer by layer, where a later layer overrides the values set by earlier ones
+// Maps are merged key by key, and other values including lists are replaced as a whole
+type layeredConfig struct {
+	tree map[string]any
+	// Source of the values by YAML path, the source of a value is recorded on itself or its closest ancestor
+	sources map[string]string
+}
+
+func newLayeredConfig() *layeredConfig {
+	return &layeredConfig{
+		tree:    make(map[string]any),
+		sources: make(map[string]string),
+	}
+}
+
+// Values defaulted before any layer is applied
+func defaultLayer() map[string]any {
+	return map[string]any{
+		"configuration": map[string]any{
+			"sbi": map[string]any{
+				"scheme":       NssfSbiDefaultScheme,
+				"registerIPv4": NssfSbiDefaultIPv4,
+				"bindingIPv4":  NssfSbiDefaultIPv4,
+				"port":         NssfSbiDefaultPort,
+			},
+		},
+		"logger": map[string]any{
+			"enable":       true,
+			"level":        "info",
+			"reportCaller": false,
+		},
+	}
+}
+
+func (l *layeredConfig) setSource(path string, source string) {
+	for p := range l.sources {
+		if strings.HasPrefix(p, path+".") || strings.HasPrefix(p, path+"[") {
+			delete(l.sources, p)
+		}
+	}
+	l.sources[path] = source
+}
+
+func (l *layeredConfig) merge(path string, dst map[string]any, src map[string]any, source string) {
+	for k, v := range src {
+		p := joinYamlPath(path, k)
+		srcMap, srcIsMap := v.(map[string]any)
+		dstMap, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			l.merge(p, dstMap, srcMap, source)
+			continue
+		}
+		dst[k] = v
+		l.setSource(p, source)
+	}
+}
+
+func (l *layeredConfig) mergeFile(file string, source string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	logger.CfgLog.Infof("Read config from [%s]", file)
+
+	var layer any
+	if err = yaml.Unmarshal(content, &layer); err != nil {
+		return fmt.Errorf("%s: %w", file, err)
+	}
+	if layer == nil {
+		return nil
+	}
+	layerMap, ok := normalizeYaml(layer).(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: configuration should be a mapping", file)
+	}
+	l.merge("", l.tree, layerMap, source)
+	return nil
+}
+
+// Merge configuration fragments in the directory in the lexical order of file names
+func (l *layeredConfig) mergeDropInDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		if err = l.mergeFile(file, "drop-in "+file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply environment variables prefixed with NssfConfigEnvPrefix in the order of names
+// Variables not addressing any top level key, such as those set by Kubernetes for services, are ignored
+func (l *layeredConfig) mergeEnv(environ []string) error {
+	sort.Strings(environ)
+	for _, env := range environ {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, NssfConfigEnvPrefix) || name == NssfDefaultNfInstanceIdEnvVar {
+			continue
+		}
+
+		segments := strings.Split(strings.TrimPrefix(name, NssfConfigEnvPrefix), "_")
+		if _, ok := yamlField(reflect.TypeOf(Config{}), segments[0]); !ok {
+			logger.CfgLog.Debugf("Env var \"%s\" does not address configuration, ignored", name)
+			continue
+		}
+
+		tree, path, err := setEnvValue(l.tree, reflect.TypeOf(Config{}), segments, value, "")
+		if err != nil {
+			return fmt.Errorf("env var %s: %w", name, err)
+		}
+		l.tree = tree.(map[string]any)
+		l.setSource(path, "env "+name)
+	}
+	return nil
+}
+
+// Set the value at the path of segments in the node of type t, and return the updated node and the YAML path
+// The node is left untouched on error
+func setEnvValue(node any, t reflect.Type, segments []string, value string, path string) (any, string, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if len(segments) == 0 {
+		v, err := parseEnvValue(t, value)
+		if err != nil {
+			return node, "", fmt.Errorf("invalid value of %s: %w", path, err)
+		}
+		return v, path, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		field, ok := yamlField(t, segments[0])
+		if !ok {
+			return node, "", fmt.Errorf("no key %s in %s", strings.ToLower(segments[0]), displayYamlPath(path))
+		}
+		m, _ := node.(map[string]any)
+		if m == nil {
+			m = make(map[string]any)
+		}
+		name := yamlName(field)
+		child, p, err := setEnvValue(m[name], field.Type, segments[1:], value, joinYamlPath(path, name))
+		if err != nil {
+			return node, "", err
+		}
+		m[name] = child
+		return m, p, nil
+	case reflect.Slice:
+		list, _ := node.([]any)
+		index, err := strconv.Atoi(segments[0])
+		if err != nil || index < 0 || index > len(list) {
+			return node, "", fmt.Errorf("index %s out of range of %s with %d item(s)",
+				segments[0], displayYamlPath(path), len(list))
+		}
+		if index == len(list) {
+			list = append(list, nil)
+		}
+		child, p, err := setEnvValue(list[index], t.Elem(), segments[1:], value, fmt.Sprintf("%s[%d]", path, index))
+		if err != nil {
+			return node, "", err
+		}
+		list[index] = child
+		return list, p, nil
+	default:
+		return node, "", fmt.Errorf("%s could only be set as a whole", displayYamlPath(path))
+	}
+}
+
+// Strings are taken as is, and other values are parsed as YAML, e.g. lists in flow style
+func parseEnvValue(t reflect.Type, value string) (any, error) {
+	if t.Kind() == reflect.String {
+		return value, nil
+	}
+	if err := yaml.Unmarshal([]byte(value), reflect.New(t).Interface()); err != nil {
+		return nil, err
+	}
+	var v any
+	if err := yaml.Unmarshal([]byte(value), &v); err != nil {
+		return nil, err
+	}
+	return normalizeYaml(v), nil
+}
+
+func yamlName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// Find the field of struct by its YAML name case-insensitively
+func yamlField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Anonymous || field.Tag.Get("yaml") == "-" {
+			continue
+		}
+		if strings.EqualFold(yamlName(field), name) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// Convert maps decoded by yaml.v2 to be keyed by strings, so that they could be merged and addressed
+func normalizeYaml(v any) any {
+	switch value := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(value))
+		for k, item := range value {
+			m[fmt.Sprint(k)] = normalizeYaml(item)
+		}
+		return m
+	case []any:
+		for i, item := range value {
+			value[i] = normalizeYaml(item)
+		}
+		return value
+	default:
+		return v
+	}
+}
+
+func joinYamlPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayYamlPath(path string) string {
+	if path == "" {
+		return "configuration root"
+	}
+	return path
+}
+
+// Get the source of the value at the path, which is recorded on itself or its closest ancestor
+func (l *layeredConfig) sourceOf(path string) string {
+	for path != "" {
+		if source, ok := l.sources[path]; ok {
+			return source
+		}
+		index := strings.LastIndexAny(path, ".[")
+		if index < 0 {
+			break
+		}
+		path = path[:index]
+	}
+	return ConfigSourceDefault
+}
+
+// List the effective values with their sources in the order of YAML paths
+func (l *layeredConfig) configSources() []ConfigSource {
+	var sources []ConfigSource
+	var walk func(path string, v any)
+	walk = func(path string, v any) {
+		switch value := v.(type) {
+		case map[string]any:
+			if len(value) == 0 {
+				break
+			}
+			keys := make([]string, 0, len(value))
+			for k := range value {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(joinYamlPath(path, k), value[k])
+			}
+			return
+		case []any:
+			if len(value) == 0 {
+				break
+			}
+			for i, item := range value {
+				walk(fmt.Sprintf("%s[%d]", path, i), item)
+			}
+			return
+		}
+		sources = append(sources, ConfigSource{
+			Path:   path,
+			Value:  fmt.Sprint(v),
+			Source: l.sourceOf(path),
+		})
+	}
+	walk("", l.tree)
+	return sources
+}
+
+// Load configuration from defaults, the file, fragments in drop-in directories and environment variables in order
+// Environment variables are given as in os.Environ, and none is applied if environ is nil
+func InitConfigLayers(f string, dropInDirs []string, environ []string, cfg *Config) error {
+	if f == "" {
+		// Use default config path
+		f = NssfDefaultConfigPath
+	}
+
+	l := newLayeredConfig()
+	l.merge("", l.tree, defaultLayer(), ConfigSourceDefault)
+	if err := l.mergeFile(f, "file "+f); err != nil {
+		return fmt.Errorf("[Factory] %+v", err)
+	}
+	for _, dir := range dropInDirs {
+		if err := l.mergeDropInDir(dir); err != nil {
+			return fmt.Errorf("[Factory] %+v", err)
+		}
+	}
+	if err := l.mergeEnv(environ); err != nil {
+		return fmt.Errorf("[Factory] %+v", err)
+	}
+
+	content, err := yaml.Marshal(l.tree)
+	if err != nil {
+		return fmt.Errorf("[Factory] %+v", err)
+	}
+	if err = yaml.Unmarshal(content, cfg); err != nil {
+		return fmt.Errorf("[Factory] %+v", err)
+	}
+	cfg.sources = l.configSources()
+	return nil
+}
@@ -0,0 +1,322 @@
+package factory
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const layersTestConfig = `info:
+  version: 1.0.2
+configuration:
+  nssfName: NSSF
+  sbi:
+    scheme: http
+    registerIPv4: 127.0.0.31
+    bindingIPv4: 127.0.0.31
+    port: 8000
+  serviceNameList:
+    - nnssf-nsselection
+    - nnssf-nssaiavailability
+  nrfUri: http://127.0.0.10:8000
+  supportedPlmnList:
+    - mcc: "208"
+      mnc: "93"
+  supportedNssaiInPlmnList:
+    - plmnId:
+        mcc: "208"
+        mnc: "93"
+      supportedSnssaiList:
+        - sst: 1
+          sd: "010203"
+  amfSetList: []
+  amfList: []
+  taList:
+    - tai:
+        plmnId:
+          mcc: "208"
+          mnc: "93"
+        tac: "000001"
+      supportedSnssaiList:
+        - sst: 1
+          sd: "010203"
+  mappingListFromPlmn: []
+`
+
+func writeLayersTestFile(t *testing.T, file string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatalf("Create directory failed: %+v", err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatalf("Write %s failed: %+v", file, err)
+	}
+}
+
+func TestInitConfigLayers(t *testing.T) {
+	testCases := []struct {
+		name    string
+		dropIns map[string]string
+		environ []string
+		// Error expected to contain, or empty if configuration is loaded
+		expectErr string
+		check     func(t *testing.T, cfg *Config)
+		// Expected sources by YAML path, where "file", "drop-in" and "env" prefix the full source
+		expectSources map[string]string
+	}{
+		{
+			name: "File over defaults",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Configuration.Sbi.Port != 8000 {
+					t.Errorf("Expected port of file, got: %d", cfg.Configuration.Sbi.Port)
+				}
+				if cfg.Logger == nil || cfg.Logger.Level != "info" {
+					t.Errorf("Expected default log level, got: %+v", cfg.Logger)
+				}
+			},
+			expectSources: map[string]string{
+				"configuration.sbi.port": "file",
+				"logger.level":           ConfigSourceDefault,
+			},
+		},
+		{
+			name: "Drop-ins over file in lexical order",
+			dropIns: map[string]string{
+				"20-port.yaml": "configuration:\n  sbi:\n    port: 8002\n",
+				"10-port.yml":  "configuration:\n  sbi:\n    port: 8001\n    scheme: https\n",
+				"30-port.txt":  "configuration:\n  sbi:\n    port: 8003\n",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Configuration.Sbi.Port != 8002 {
+					t.Errorf("Expected port of the last drop-in, got: %d", cfg.Configuration.Sbi.Port)
+				}
+				if cfg.Configuration.Sbi.Scheme != "https" {
+					t.Errorf("Expected scheme of drop-in, got: %s", cfg.Configuration.Sbi.Scheme)
+				}
+				if cfg.Configuration.Sbi.RegisterIPv4 != "127.0.0.31" {
+					t.Errorf("Expected keys not in drop-in to be kept, got: %s", cfg.Configuration.Sbi.RegisterIPv4)
+				}
+			},
+			expectSources: map[string]string{
+				"configuration.sbi.port":         "drop-in",
+				"configuration.sbi.registerIPv4": "file",
+			},
+		},
+		{
+			name:    "Env over drop-ins",
+			dropIns: map[string]string{"10-port.yaml": "configuration:\n  sbi:\n    port: 8001\n"},
+			environ: []string{"NSSF_CONFIGURATION_SBI_PORT=8100", "NSSF_CONFIGURATION_NSSFNAME=nssf-1"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Configuration.Sbi.Port != 8100 {
+					t.Errorf("Expected port of env, got: %d", cfg.Configuration.Sbi.Port)
+				}
+				if cfg.Configuration.NssfName != "nssf-1" {
+					t.Errorf("Expected name of env, got: %s", cfg.Configuration.NssfName)
+				}
+			},
+			expectSources: map[string]string{
+				"configuration.sbi.port": "env NSSF_CONFIGURATION_SBI_PORT",
+				"configuration.nssfName": "env NSSF_CONFIGURATION_NSSFNAME",
+			},
+		},
+		{
+			name: "Lists replaced as a whole by drop-ins",
+			dropIns: map[string]string{
+				"10-plmn.yaml": "configuration:\n  supportedPlmnList:\n    - {mcc: \"466\", mnc: \"92\"}\n",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if len(cfg.Configuration.SupportedPlmnList) != 1 || cfg.Configuration.SupportedPlmnList[0].Mcc != "466" {
+					t.Errorf("Expected PLMN list of drop-in, got: %+v", cfg.Configuration.SupportedPlmnList)
+				}
+			},
+			expectSources: map[string]string{
+				"configuration.supportedPlmnList[0].mcc": "drop-in",
+			},
+		},
+		{
+			name: "List items addressed by index",
+			environ: []string{
+				"NSSF_CONFIGURATION_TALIST_0_TAI_TAC=000002",
+				"NSSF_CONFIGURATION_SERVICENAMELIST_1=nnssf-nsselection",
+				"NSSF_CONFIGURATION_SUPPORTEDPLMNLIST_1={mcc: \"466\", mnc: \"92\"}",
+			},
+			check: func(t *testing.T, cfg *Config) {
+				if tac := cfg.Configuration.TaList[0].Tai.Tac; tac != "000002" {
+					t.Errorf("Expected TAC of env, got: %s", tac)
+				}
+				if plmnId := cfg.Configuration.TaList[0].Tai.PlmnId; plmnId == nil || plmnId.Mcc != "208" {
+					t.Errorf("Expected PLMN ID of file to be kept, got: %+v", plmnId)
+				}
+				if serviceName := cfg.Configuration.ServiceNameList[1]; serviceName != "nnssf-nsselection" {
+					t.Errorf("Expected service name of env, got: %s", serviceName)
+				}
+				if len(cfg.Configuration.SupportedPlmnList) != 2 || cfg.Configuration.SupportedPlmnList[1].Mnc != "92" {
+					t.Errorf("Expected PLMN appended by env, got: %+v", cfg.Configuration.SupportedPlmnList)
+				}
+			},
+			expectSources: map[string]string{
+				"configuration.taList[0].tai.tac":                                     "env NSSF_CONFIGURATION_TALIST_0_TAI_TAC",
+				"configuration.taList[0].tai.plmnId.mcc":                              "file",
+				"configuration.supportedPlmnList[0].mcc":                              "file",
+				"configuration.supportedPlmnList[1].mcc":                              "env NSSF_CONFIGURATION_SUPPORTEDPLMNLIST_1",
+				"configuration.serviceNameList[1]":                                    "env NSSF_CONFIGURATION_SERVICENAMELIST_1",
+				"configuration.supportedNssaiInPlmnList[0].supportedSnssaiList[0].sd": "file",
+			},
+		},
+		{
+			name:    "Env not addressing configuration is ignored",
+			environ: []string{"NSSF_SERVICE_HOST=10.0.0.1", "NSSF_NF_INSTANCE_ID=1", "AMF_CONFIGURATION_SBI_PORT=1"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Configuration.Sbi.Port != 8000 {
+					t.Errorf("Expected port of file, got: %d", cfg.Configuration.Sbi.Port)
+				}
+			},
+		},
+		{
+			name:      "Index out of range",
+			environ:   []string{"NSSF_CONFIGURATION_TALIST_2_TAI_TAC=000002"},
+			expectErr: "index 2 out of range of configuration.taList",
+		},
+		{
+			name:      "Index not a number",
+			environ:   []string{"NSSF_CONFIGURATION_TALIST_FIRST_TAI_TAC=000002"},
+			expectErr: "index FIRST out of range of configuration.taList",
+		},
+		{
+			name:      "Unknown key",
+			environ:   []string{"NSSF_CONFIGURATION_SBI_PORTS=8100"},
+			expectErr: "no key ports in configuration.sbi",
+		},
+		{
+			name:      "Key with underscore not addressable",
+			environ:   []string{"NSSF_CONFIGURATION_NRF_URI=http://127.0.0.10:8000"},
+			expectErr: "no key nrf in configuration",
+		},
+		{
+			name:      "Value of wrong type",
+			environ:   []string{"NSSF_CONFIGURATION_SBI_PORT=http"},
+			expectErr: "invalid value of configuration.sbi.port",
+		},
+		{
+			name:      "Scalar addressed below",
+			environ:   []string{"NSSF_CONFIGURATION_SBI_PORT_0=8100"},
+			expectErr: "configuration.sbi.port could only be set as a whole",
+		},
+		{
+			name:      "Invalid drop-in",
+			dropIns:   map[string]string{"10-invalid.yaml": "- configuration\n"},
+			expectErr: "configuration should be a mapping",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			file := filepath.Join(dir, "nssfcfg.yaml")
+			writeLayersTestFile(t, file, layersTestConfig)
+			dropInDir := filepath.Join(dir, "nssfcfg.d")
+			if err := os.Mkdir(dropInDir, 0o755); err != nil {
+				t.Fatalf("Create drop-in directory failed: %+v", err)
+			}
+			for name, content := range tc.dropIns {
+				writeLayersTestFile(t, filepath.Join(dropInDir, name), content)
+			}
+
+			cfg := &Config{}
+			err := InitConfigLayers(file, []string{dropInDir}, tc.environ, cfg)
+			if tc.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectErr) {
+					t.Fatalf("Expected error containing %q, got: %+v", tc.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected configuration to be loaded, got: %+v", err)
+			}
+			tc.check(t, cfg)
+
+			sources := make(map[string]string)
+			for _, source := range cfg.GetConfigSources() {
+				sources[source.Path] = source.Source
+			}
+			for path, expect := range tc.expectSources {
+				source, ok := sources[path]
+				if !ok {
+					t.Errorf("Expected source of %s, got sources: %+v", path, sources)
+					continue
+				}
+				switch expect {
+				case "file":
+					expect = "file " + file
+				case "drop-in":
+					if !strings.HasPrefix(source, "drop-in "+dropInDir) {
+						t.Errorf("Expected source of %s in %s, got: %s", path, dropInDir, source)
+					}
+					continue
+				}
+				if source != expect {
+					t.Errorf("Expected source of %s to be %q, got: %q", path, expect, source)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigSourcesValues(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nssfcfg.yaml")
+	writeLayersTestFile(t, file, layersTestConfig)
+
+	cfg := &Config{}
+	if err := InitConfigLayers(file, nil, []string{"NSSF_CONFIGURATION_SBI_PORT=8100"}, cfg); err != nil {
+		t.Fatalf("Expected configuration to be loaded, got: %+v", err)
+	}
+
+	sources := cfg.GetConfigSources()
+	expect := map[string]ConfigSource{
+		"configuration.sbi.port": {
+			Path: "configuration.sbi.port", Value: "8100", Source: "env NSSF_CONFIGURATION_SBI_PORT",
+		},
+		"configuration.sbi.scheme": {Path: "configuration.sbi.scheme", Value: "http", Source: "file " + file},
+		"configuration.amfList":    {Path: "configuration.amfList", Value: "[]", Source: "file " + file},
+		"logger.reportCaller":      {Path: "logger.reportCaller", Value: "false", Source: ConfigSourceDefault},
+		"configuration.taList[0].tai.tac": {
+			Path: "configuration.taList[0].tai.tac", Value: "000001", Source: "file " + file,
+		},
+	}
+	found := 0
+	for _, source := range sources {
+		if e, ok := expect[source.Path]; ok {
+			found++
+			if source != e {
+				t.Errorf("Expected %+v, got: %+v", e, source)
+			}
+		}
+	}
+	if found != len(expect) {
+		t.Errorf("Expected %d of the sources, found %d in: %+v", len(expect), found, sources)
+	}
+}
+
+func TestEnvOptIn(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "nssfcfg.yaml")
+	writeLayersTestFile(t, file, layersTestConfig)
+	t.Setenv("NSSF_CONFIGURATION_SBI_PORT", "8100")
+
+	cfg := &Config{}
+	if err := InitConfigFactory(file, cfg); err != nil {
+		t.Fatalf("Expected configuration to be loaded, got: %+v", err)
+	}
+	if cfg.Configuration.Sbi.Port != 8000 {
+		t.Errorf("Expected environment not to be applied by InitConfigFactory, got port: %d",
+			cfg.Configuration.Sbi.Port)
+	}
+
+	cfg, err := ReadConfig(file)
+	if err != nil {
+		t.Fatalf("Expected configuration to be read, got: %+v", err)
+	}
+	if cfg.Configuration.Sbi.Port != 8100 {
+		t.Errorf("Expected environment to be applied by ReadConfig, got port: %d", cfg.Configuration.Sbi.Port)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+ * NSSF Configuration Factory
+ *
+ * Semantic checks of configuration across its sections
+ */
+
+package factory
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/free5gc/openapi/models"
+)
+
+var sdRegexp = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+// Problem found in configuration by lint, located by its YAML path
+type LintProblem struct {
+	Path    string
+	Message string
+}
+
+func (p LintProblem) String() string {
+	return p.Path + ": " + p.Message
+}
+
+type linter struct {
+	problems []LintProblem
+}
+
+func (l *linter) report(path string, format string, args ...any) {
+	l.problems = append(l.problems, LintProblem{
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) checkSd(path string, sd string) {
+	if sd != "" && !sdRegexp.MatchString(sd) {
+		l.report(path+".sd", "SD %q should be 6 hexadecimal digits", sd)
+	}
+}
+
+func checkNssai[T models.Snssai | models.ExtSnssai](l *linter, path string, nssai []T) {
+	for index, snssai := range nssai {
+		switch s := any(snssai).(type) {
+		case models.Snssai:
+			l.checkSd(fmt.Sprintf("%s[%d]", path, index), s.Sd)
+		case models.ExtSnssai:
+			l.checkSd(fmt.Sprintf("%s[%d]", path, index), s.Sd)
+		}
+	}
+}
+
+// Check that the TAIs of NSSAI availability are present, configured in taList, and not duplicated
+func (l *linter) checkNssaiAvailability(path string, s []models.SupportedNssaiAvailabilityData,
+	tas map[taiKey]struct{},
+) {
+	seen := make(map[taiKey]int)
+	for index, supportedNssaiAvailabilityData := range s {
+		itemPath := fmt.Sprintf("%s[%d]", path, index)
+		checkNssai(l, itemPath+".supportedSnssaiList", supportedNssaiAvailabilityData.SupportedSnssaiList)
+		if supportedNssaiAvailabilityData.Tai == nil {
+			l.report(itemPath+".tai", "TAI is missing")
+			continue
+		}
+
+		k := newTaiKey(*supportedNssaiAvailabilityData.Tai)
+		if _, ok := tas[k]; !ok {
+			l.report(itemPath+".tai", "TAI %s is not configured in configuration.taList", lintTai(k))
+		}
+		if first, ok := seen[k]; ok {
+			l.report(itemPath+".tai", "TAI %s is duplicated with %s[%d].tai", lintTai(k), path, first)
+		} else {
+			seen[k] = index
+		}
+	}
+}
+
+// Lint the configuration for problems not caught by validation, such as references to S-NSSAIs or TAs
+// which are not configured
+// Problems are returned in the order of the configuration, and the configuration is usable even if any is found
+func (c *Config) Lint() []LintProblem {
+	c.RLock()
+	defer c.RUnlock()
+
+	l := &linter{}
+	if c.Configuration == nil {
+		return nil
+	}
+	cfg := c.Configuration
+
+	// S-NSSAIs supported in any PLMN
+	supported := make(snssaiSet)
+	for index, supportedNssaiInPlmn := range cfg.SupportedNssaiInPlmnList {
+		path := fmt.Sprintf("configuration.supportedNssaiInPlmnList[%d].supportedSnssaiList", index)
+		checkNssai(l, path, supportedNssaiInPlmn.SupportedSnssaiList)
+		for k := range newSnssaiSet(supportedNssaiInPlmn.SupportedSnssaiList) {
+			supported[k] = struct{}{}
+		}
+	}
+
+	tas := make(map[taiKey]struct{})
+	taIndex := make(map[taiKey]int)
+	for index, taConfig := range cfg.TaList {
+		path := fmt.Sprintf("configuration.taList[%d]", index)
+		checkNssai(l, path+".supportedSnssaiList", taConfig.SupportedSnssaiList)
+		for i, access := range taConfig.AccessList {
+			checkNssai(l, fmt.Sprintf("%s.accessList[%d].supportedSnssaiList", path, i), access.SupportedSnssaiList)
+		}
+		for i, restrictedSnssai := range taConfig.RestrictedSnssaiList {
+			checkNssai(l, fmt.Sprintf("%s.restrictedSnssaiList[%d].sNssaiList", path, i), restrictedSnssai.SNssaiList)
+		}
+		if taConfig.Tai == nil {
+			continue
+		}
+
+		k := newTaiKey(*taConfig.Tai)
+		if first, ok := taIndex[k]; ok {
+			l.report(path+".tai", "TAI %s is duplicated with configuration.taList[%d].tai", lintTai(k), first)
+			continue
+		}
+		tas[k] = struct{}{}
+		taIndex[k] = index
+	}
+
+	for index, nsiConfig := range cfg.NsiList {
+		path := fmt.Sprintf("configuration.nsiList[%d].snssai", index)
+		if nsiConfig.Snssai == nil {
+			continue
+		}
+		l.checkSd(path, nsiConfig.Snssai.Sd)
+		if _, ok := supported[newSnssaiKey(nsiConfig.Snssai.Sst, nsiConfig.Snssai.Sd)]; !ok {
+			l.report(path, "S-NSSAI %s is not supported in any PLMN of configuration.supportedNssaiInPlmnList",
+				lintSnssai(*nsiConfig.Snssai))
+		}
+	}
+
+	for index, amfSetConfig := range cfg.AmfSetList {
+		l.checkNssaiAvailability(fmt.Sprintf("configuration.amfSetList[%d].supportedNssaiAvailabilityData", index),
+			amfSetConfig.SupportedNssaiAvailabilityData, tas)
+	}
+
+	for index, amfConfig := range cfg.AmfList {
+		l.checkNssaiAvailability(fmt.Sprintf("configuration.amfList[%d].supportedNssaiAvailabilityData", index),
+			amfConfig.SupportedNssaiAvailabilityData, tas)
+	}
+
+	for index, amfPolicy := range cfg.AmfPolicyList {
+		l.checkNssaiAvailability(fmt.Sprintf("configuration.amfPolicyList[%d].authorizedNssaiAvailabilityData", index),
+			amfPolicy.AuthorizedNssaiAvailabilityData, tas)
+	}
+
+	for index, mappingFromPlmn := range cfg.MappingListFromPlmn {
+		for i, mapping := range mappingFromPlmn.MappingOfSnssai {
+			path := fmt.Sprintf("configuration.mappingListFromPlmn[%d].mappingOfSnssai[%d]", index, i)
+			if mapping.HomeSnssai != nil {
+				l.checkSd(path+".homeSnssai", mapping.HomeSnssai.Sd)
+			}
+			if mapping.ServingSnssai == nil {
+				continue
+			}
+			l.checkSd(path+".servingSnssai", mapping.ServingSnssai.Sd)
+			if _, ok := supported[newSnssaiKey(mapping.ServingSnssai.Sst, mapping.ServingSnssai.Sd)]; !ok {
+				l.report(path+".servingSnssai",
+					"S-NSSAI %s is not supported in any PLMN of configuration.supportedNssaiInPlmnList",
+					lintSnssai(*mapping.ServingSnssai))
+			}
+		}
+	}
+
+	if cfg.Nsac != nil {
+		for index, quota := range cfg.Nsac.QuotaList {
+			if quota.Snssai != nil {
+				l.checkSd(fmt.Sprintf("configuration.nsac.quotaList[%d].snssai", index), quota.Snssai.Sd)
+			}
+		}
+	}
+
+	for index, subscription := range c.Subscriptions {
+		if subscription.SubscriptionData == nil {
+			continue
+		}
+		for i, tai := range subscription.SubscriptionData.TaiList {
+			if _, ok := tas[newTaiKey(tai)]; !ok {
+				l.report(fmt.Sprintf("subscriptions[%d].subscriptionData.taiList[%d]", index, i),
+					"TAI %s is not configured in configuration.taList", lintTai(newTaiKey(tai)))
+			}
+		}
+	}
+
+	return l.problems
+}
+
+func lintSnssai(snssai models.Snssai) string {
+	if snssai.Sd == "" {
+		return fmt.Sprintf("%d", snssai.Sst)
+	}
+	return fmt.Sprintf("%d/%s", snssai.Sst, snssai.Sd)
+}
+
+func lintTai(k taiKey) string {
+	tai := k.plmnId.Mcc + k.plmnId.Mnc + "/" + k.tac
+	if k.nid != "" {
+		tai += "/" + k.nid
+	}
+	return tai
+}
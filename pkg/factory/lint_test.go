@@ -0,0 +1,54 @@
+package factory
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+var update = flag.Bool("update", false, "update golden files of tests")
+
+func TestLintGolden(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "lint.yaml"))
+	if err != nil {
+		t.Fatalf("Error reading configuration: %v", err)
+	}
+	cfg := &Config{}
+	if err = yaml.Unmarshal(content, cfg); err != nil {
+		t.Fatalf("Error unmarshalling configuration: %v", err)
+	}
+
+	var b strings.Builder
+	for _, problem := range cfg.Lint() {
+		b.WriteString(problem.String() + "\n")
+	}
+	got := b.String()
+
+	golden := filepath.Join("testdata", "lint.golden")
+	if *update {
+		if err = os.WriteFile(golden, []byte(got), 0o600); err != nil {
+			t.Fatalf("Error writing golden file: %v", err)
+		}
+	}
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("Error reading golden file: %v", err)
+	}
+	if got != string(expected) {
+		t.Errorf("Lint problems differ from %s\ngot:\n%s\nexpected:\n%s", golden, got, expected)
+	}
+}
+
+func TestLintClean(t *testing.T) {
+	cfg := &Config{}
+	if err := yaml.Unmarshal([]byte(layersTestConfig), cfg); err != nil {
+		t.Fatalf("Error unmarshalling configuration: %v", err)
+	}
+	if problems := cfg.Lint(); len(problems) != 0 {
+		t.Errorf("Expected no lint problems, got: %v", problems)
+	}
+}
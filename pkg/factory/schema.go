@@ -0,0 +1,177 @@
+/*
+ * NSSF Configuration Factory
+ *
+ * JSON Schema of configuration
+ */
+
+package factory
+
+import (
+	"encoding/json"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version of configuration supported, which should be in line with the constraint of Info.Version
+const NssfExpectedConfigVersion = "1.0.2"
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var validatorParamRegexp = regexp.MustCompile(`^(\w+)\((.*)\)$`)
+
+// Constraints of fields of OpenAPI models, which have no govalidator tags, keyed by type and YAML name
+// They follow the patterns of TS 29.571
+var modelFieldConstraints = map[string]map[string]any{
+	"models.Snssai.sst":    {"minimum": 0, "maximum": 255},
+	"models.Snssai.sd":     {"pattern": sdRegexp.String()},
+	"models.ExtSnssai.sst": {"minimum": 0, "maximum": 255},
+	"models.ExtSnssai.sd":  {"pattern": sdRegexp.String()},
+	"models.PlmnId.mcc":    {"pattern": `^[0-9]{3}$`},
+	"models.PlmnId.mnc":    {"pattern": `^[0-9]{2,3}$`},
+}
+
+type schemaGenerator struct {
+	defs map[string]map[string]any
+}
+
+// Generate JSON Schema of configuration from the types of Config, with the constraints of govalidator tags
+// The schema is identified by the configuration version, so that a schema is published per version
+func JSONSchema() ([]byte, error) {
+	g := &schemaGenerator{
+		defs: make(map[string]map[string]any),
+	}
+	schema := g.schemaOfStruct(reflect.TypeOf(Config{}))
+	schema["$schema"] = jsonSchemaDialect
+	schema["$id"] = "urn:free5gc:nssf:nssfcfg:" + NssfExpectedConfigVersion
+	schema["title"] = "NSSF configuration " + NssfExpectedConfigVersion
+	schema["$defs"] = g.defs
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) map[string]any {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return map[string]any{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(time.Duration(0)):
+		return map[string]any{"type": "string", "pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaOf(t.Elem())
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.refOf(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+// Refer to the definition of the struct, which is generated once for recursive and repeated types
+func (g *schemaGenerator) refOf(t reflect.Type) map[string]any {
+	name := path.Base(t.PkgPath()) + "." + t.Name()
+	if _, ok := g.defs[name]; !ok {
+		// Reserve the name before generating, in case the type refers to itself
+		g.defs[name] = nil
+		g.defs[name] = g.schemaOfStruct(t)
+	}
+	return map[string]any{"$ref": "#/$defs/" + name}
+}
+
+func (g *schemaGenerator) schemaOfStruct(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || field.Anonymous || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		schema := g.schemaOf(field.Type)
+		for k, v := range modelFieldConstraints[path.Base(t.PkgPath())+"."+t.Name()+"."+name] {
+			schema[k] = v
+		}
+		if applyValidatorTag(schema, field.Tag.Get("valid")) {
+			required = append(required, name)
+		}
+		properties[name] = schema
+	}
+
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) != 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Apply the constraints of govalidator tag to the schema, and return whether the field is required
+// Constraints without counterparts in JSON Schema are left to validation at startup
+func applyValidatorTag(schema map[string]any, tag string) bool {
+	required := false
+	for _, validator := range strings.Split(tag, ",") {
+		name, param := validator, ""
+		if match := validatorParamRegexp.FindStringSubmatch(validator); match != nil {
+			name, param = match[1], match[2]
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "in":
+			schema["enum"] = strings.Split(param, "|")
+		case "minstringlength":
+			if n, err := strconv.Atoi(param); err == nil {
+				schema["minLength"] = n
+			}
+		case "maxstringlength":
+			if n, err := strconv.Atoi(param); err == nil {
+				schema["maxLength"] = n
+			}
+		case "matches":
+			schema["pattern"] = param
+		case "uuid", "uuidv4":
+			schema["format"] = "uuid"
+		case "url":
+			schema["format"] = "uri"
+		case "host":
+			schema["anyOf"] = []map[string]any{
+				{"format": "hostname"},
+				{"format": "ipv4"},
+				{"format": "ipv6"},
+			}
+		case "port":
+			schema["minimum"] = 1
+			schema["maximum"] = 65535
+		case "dialstring":
+			schema["pattern"] = `^.+:[0-9]+$`
+		case "scheme":
+			schema["enum"] = []string{"http", "https"}
+		}
+	}
+	return required
+}
@@ -0,0 +1,127 @@
+package factory
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v2"
+)
+
+// Convert a value decoded from YAML to the one of JSON, whose object keys are strings
+func jsonValueOfYaml(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for key, value := range v {
+			m[fmt.Sprint(key)] = jsonValueOfYaml(value)
+		}
+		return m
+	case []any:
+		for i, value := range v {
+			v[i] = jsonValueOfYaml(value)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := JSONSchema()
+	if err != nil {
+		t.Fatalf("Error generating schema: %v", err)
+	}
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(schema))
+	if err != nil {
+		t.Fatalf("Error unmarshalling schema: %v", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	if err = compiler.AddResource("nssfcfg.json", doc); err != nil {
+		t.Fatalf("Error adding schema: %v", err)
+	}
+	validator, err := compiler.Compile("nssfcfg.json")
+	if err != nil {
+		t.Fatalf("Error compiling schema: %v", err)
+	}
+
+	sample, err := os.ReadFile(filepath.Join("testdata", "nssfcfg.yaml"))
+	if err != nil {
+		t.Fatalf("Error reading sample configuration: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		old, new    string
+		expectValid bool
+	}{
+		{name: "Sample configuration", expectValid: true},
+		{name: "Uppercase SD", old: `sd: "112233"`, new: `sd: "ABCDEF"`, expectValid: true},
+		{name: "SD with 5 digits", old: `sd: "112233"`, new: `sd: "11223"`},
+		{name: "SD with non-hexadecimal digits", old: `sd: "112233"`, new: `sd: "11223g"`},
+		{name: "SST out of range", old: "- sst: 2", new: "- sst: 256"},
+		{name: "Port out of range", old: "port: 8000", new: "port: 65536"},
+		{name: "Port zero", old: "port: 9091", new: "port: 0"},
+		{name: "Port not a number", old: "port: 8000", new: "port: http"},
+		{name: "MCC with 2 digits", old: `mcc: "440"`, new: `mcc: "44"`},
+		{name: "Unsupported version", old: "version: 1.0.2", new: "version: 1.0.1"},
+		{name: "Unsupported log level", old: "level: info", new: "level: verbose"},
+		{name: "Missing registerIPv4", old: "    registerIPv4: 127.0.0.31\n", new: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := string(sample)
+			if tc.old != "" {
+				if !strings.Contains(content, tc.old) {
+					t.Fatalf("Sample configuration does not contain %q", tc.old)
+				}
+				content = strings.Replace(content, tc.old, tc.new, 1)
+			}
+
+			var v any
+			if err = yaml.Unmarshal([]byte(content), &v); err != nil {
+				t.Fatalf("Error unmarshalling configuration: %v", err)
+			}
+			// Round trip through JSON, so that numbers are decoded as the validator expects
+			b, err := json.Marshal(jsonValueOfYaml(v))
+			if err != nil {
+				t.Fatalf("Error marshalling configuration: %v", err)
+			}
+			instance, err := jsonschema.UnmarshalJSON(bytes.NewReader(b))
+			if err != nil {
+				t.Fatalf("Error unmarshalling configuration: %v", err)
+			}
+
+			err = validator.Validate(instance)
+			if tc.expectValid && err != nil {
+				t.Errorf("Expected configuration to be valid, got: %v", err)
+			}
+			if !tc.expectValid && err == nil {
+				t.Errorf("Expected configuration to be rejected")
+			}
+		})
+	}
+}
+
+func TestSampleConfigValidate(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "nssfcfg.yaml"))
+	if err != nil {
+		t.Fatalf("Error reading sample configuration: %v", err)
+	}
+	cfg := &Config{}
+	if err = yaml.Unmarshal(content, cfg); err != nil {
+		t.Fatalf("Error unmarshalling configuration: %v", err)
+	}
+	if _, err = cfg.Validate(); err != nil {
+		t.Errorf("Expected sample configuration to be valid, got: %v", err)
+	}
+	if problems := cfg.Lint(); len(problems) != 0 {
+		t.Errorf("Expected no lint problems, got: %v", problems)
+	}
+}
@@ -0,0 +1,363 @@
+/*
+ * NSSF Configuration Factory
+ *
+ * Indexed snapshot of configuration
+ */
+
+package factory
+
+import (
+	"strings"
+
+	"github.com/free5gc/openapi/models"
+)
+
+// Immutable view of NSSF configuration, indexed by PLMN, TAI, S-NSSAI and AMF ID for lookups without locking
+// A snapshot is never modified once published, and is rebuilt whenever the configuration is changed
+// Slices returned by a snapshot are shared and must not be modified
+type Snapshot struct {
+	mappingsFromPlmn map[models.PlmnId][]models.MappingOfSnssai
+	plmns            map[models.PlmnId]*plmnSnapshot
+	tas              map[taiKey]*taSnapshot
+	nsis             map[snssaiKey][]models.NsiInformation
+	amfs             map[string]*amfSnapshot
+	// PLMNs and S-NSSAIs configured anywhere, which bound the label values of metrics
+	configuredPlmns   map[models.PlmnId]struct{}
+	configuredSnssais snssaiSet
+	// AMFs and AMF sets in the order of configuration, which is the order of selection
+	amfList    []*amfSnapshot
+	amfSetList []*amfSetSnapshot
+}
+
+// S-NSSAI with SD in lower case, since SD is compared case-insensitively
+type snssaiKey struct {
+	sst int32
+	sd  string
+}
+
+type taiKey struct {
+	plmnId models.PlmnId
+	tac    string
+	nid    string
+}
+
+type snssaiSet map[snssaiKey]struct{}
+
+type plmnSnapshot struct {
+	supportedSnssaiList []models.Snssai
+	supported           snssaiSet
+}
+
+type taSnapshot struct {
+	supported            snssaiSet
+	accessTypeList       []models.AccessType
+	accessTypesBySnssai  map[snssaiKey][]models.AccessType
+	restrictedSnssaiList []models.RestrictedSnssai
+}
+
+// S-NSSAIs supported per TA by an AMF or an AMF set
+type nssaiAvailabilitySnapshot map[taiKey]snssaiSet
+
+type amfSnapshot struct {
+	nfId              string
+	nssaiAvailability nssaiAvailabilitySnapshot
+}
+
+type amfSetSnapshot struct {
+	config            AmfSetConfig
+	nssaiAvailability nssaiAvailabilitySnapshot
+}
+
+func newSnssaiKey(sst int32, sd string) snssaiKey {
+	return snssaiKey{sst: sst, sd: strings.ToLower(sd)}
+}
+
+func newTaiKey(tai models.Tai) taiKey {
+	k := taiKey{tac: tai.Tac, nid: tai.Nid}
+	if tai.PlmnId != nil {
+		k.plmnId = *tai.PlmnId
+	}
+	return k
+}
+
+func newSnssaiSet[T models.Snssai | models.ExtSnssai](nssai []T) snssaiSet {
+	set := make(snssaiSet, len(nssai))
+	for _, snssai := range nssai {
+		switch s := any(snssai).(type) {
+		case models.Snssai:
+			set[newSnssaiKey(s.Sst, s.Sd)] = struct{}{}
+		case models.ExtSnssai:
+			set[newSnssaiKey(s.Sst, s.Sd)] = struct{}{}
+		}
+	}
+	return set
+}
+
+func (set snssaiSet) add(other snssaiSet) {
+	for k := range other {
+		set[k] = struct{}{}
+	}
+}
+
+func newNssaiAvailabilitySnapshot(s []models.SupportedNssaiAvailabilityData) nssaiAvailabilitySnapshot {
+	n := make(nssaiAvailabilitySnapshot, len(s))
+	for _, supportedNssaiAvailabilityData := range s {
+		if supportedNssaiAvailabilityData.Tai == nil {
+			continue
+		}
+		k := newTaiKey(*supportedNssaiAvailabilityData.Tai)
+		set, ok := n[k]
+		if !ok {
+			set = make(snssaiSet)
+			n[k] = set
+		}
+		for _, snssai := range supportedNssaiAvailabilityData.SupportedSnssaiList {
+			set[newSnssaiKey(snssai.Sst, snssai.Sd)] = struct{}{}
+		}
+	}
+	return n
+}
+
+func (n nssaiAvailabilitySnapshot) supportsNssai(tai models.Tai, nssai []models.Snssai) bool {
+	set := n[newTaiKey(tai)]
+	for _, snssai := range nssai {
+		if _, ok := set[newSnssaiKey(snssai.Sst, snssai.Sd)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Build a snapshot of the configuration
+// Where an entry is configured more than once, the first one takes effect as in a linear search
+func newSnapshot(c *Configuration) *Snapshot {
+	s := &Snapshot{
+		mappingsFromPlmn:  make(map[models.PlmnId][]models.MappingOfSnssai),
+		plmns:             make(map[models.PlmnId]*plmnSnapshot),
+		tas:               make(map[taiKey]*taSnapshot),
+		nsis:              make(map[snssaiKey][]models.NsiInformation),
+		amfs:              make(map[string]*amfSnapshot),
+		configuredPlmns:   make(map[models.PlmnId]struct{}),
+		configuredSnssais: make(snssaiSet),
+	}
+	if c == nil {
+		return s
+	}
+
+	for _, plmnId := range c.SupportedPlmnList {
+		s.configuredPlmns[plmnId] = struct{}{}
+	}
+
+	for _, mappingFromPlmn := range c.MappingListFromPlmn {
+		if mappingFromPlmn.HomePlmnId == nil {
+			continue
+		}
+		s.configuredPlmns[*mappingFromPlmn.HomePlmnId] = struct{}{}
+		if _, exist := s.mappingsFromPlmn[*mappingFromPlmn.HomePlmnId]; !exist {
+			s.mappingsFromPlmn[*mappingFromPlmn.HomePlmnId] = mappingFromPlmn.MappingOfSnssai
+		}
+	}
+
+	for _, supportedNssaiInPlmn := range c.SupportedNssaiInPlmnList {
+		if supportedNssaiInPlmn.PlmnId == nil {
+			continue
+		}
+		s.configuredPlmns[*supportedNssaiInPlmn.PlmnId] = struct{}{}
+		s.configuredSnssais.add(newSnssaiSet(supportedNssaiInPlmn.SupportedSnssaiList))
+		if _, exist := s.plmns[*supportedNssaiInPlmn.PlmnId]; !exist {
+			s.plmns[*supportedNssaiInPlmn.PlmnId] = &plmnSnapshot{
+				supportedSnssaiList: supportedNssaiInPlmn.SupportedSnssaiList,
+				supported:           newSnssaiSet(supportedNssaiInPlmn.SupportedSnssaiList),
+			}
+		}
+	}
+
+	for i := range c.TaList {
+		taConfig := &c.TaList[i]
+		if taConfig.Tai == nil {
+			continue
+		}
+		k := newTaiKey(*taConfig.Tai)
+		if _, exist := s.tas[k]; exist {
+			continue
+		}
+
+		s.configuredSnssais.add(newSnssaiSet(taConfig.GetSupportedSnssaiList()))
+		ta := &taSnapshot{
+			supported:            newSnssaiSet(taConfig.GetSupportedSnssaiList()),
+			accessTypesBySnssai:  make(map[snssaiKey][]models.AccessType),
+			restrictedSnssaiList: taConfig.RestrictedSnssaiList,
+		}
+		if len(ta.restrictedSnssaiList) == 0 {
+			ta.restrictedSnssaiList = nil
+		}
+		for _, access := range taConfig.GetAccessList() {
+			accessType := *access.AccessType
+			if !containAccessType(accessType, ta.accessTypeList) {
+				ta.accessTypeList = append(ta.accessTypeList, accessType)
+			}
+			for _, snssai := range access.SupportedSnssaiList {
+				sk := newSnssaiKey(snssai.Sst, snssai.Sd)
+				if !containAccessType(accessType, ta.accessTypesBySnssai[sk]) {
+					ta.accessTypesBySnssai[sk] = append(ta.accessTypesBySnssai[sk], accessType)
+				}
+			}
+		}
+		s.tas[k] = ta
+	}
+
+	for _, nsiConfig := range c.NsiList {
+		if nsiConfig.Snssai == nil {
+			continue
+		}
+		k := newSnssaiKey(nsiConfig.Snssai.Sst, nsiConfig.Snssai.Sd)
+		s.configuredSnssais[k] = struct{}{}
+		if _, exist := s.nsis[k]; !exist {
+			s.nsis[k] = nsiConfig.NsiInformationList
+		}
+	}
+
+	for _, amfConfig := range c.AmfList {
+		if _, exist := s.amfs[amfConfig.NfId]; exist {
+			continue
+		}
+		amf := &amfSnapshot{
+			nfId:              amfConfig.NfId,
+			nssaiAvailability: newNssaiAvailabilitySnapshot(amfConfig.SupportedNssaiAvailabilityData),
+		}
+		s.amfs[amfConfig.NfId] = amf
+		s.amfList = append(s.amfList, amf)
+	}
+
+	for _, amfSetConfig := range c.AmfSetList {
+		s.amfSetList = append(s.amfSetList, &amfSetSnapshot{
+			config:            amfSetConfig,
+			nssaiAvailability: newNssaiAvailabilitySnapshot(amfSetConfig.SupportedNssaiAvailabilityData),
+		})
+	}
+
+	return s
+}
+
+func containAccessType(accessType models.AccessType, accessTypeList []models.AccessType) bool {
+	for _, a := range accessTypeList {
+		if a == accessType {
+			return true
+		}
+	}
+	return false
+}
+
+// Check whether the Home PLMN is configured with S-NSSAI mappings
+func (s *Snapshot) HasHomePlmn(homePlmnId models.PlmnId) bool {
+	_, ok := s.mappingsFromPlmn[homePlmnId]
+	return ok
+}
+
+// Get S-NSSAI mappings of the Home PLMN
+func (s *Snapshot) GetMappingOfSnssai(homePlmnId models.PlmnId) []models.MappingOfSnssai {
+	return s.mappingsFromPlmn[homePlmnId]
+}
+
+// Get supported S-NSSAI list of the PLMN, and whether the PLMN is configured
+func (s *Snapshot) GetSupportedSnssaiListInPlmn(plmnId models.PlmnId) ([]models.Snssai, bool) {
+	plmn, ok := s.plmns[plmnId]
+	if !ok {
+		return nil, false
+	}
+	return plmn.supportedSnssaiList, true
+}
+
+// Check whether the S-NSSAI is supported in the PLMN, and whether the PLMN is configured
+func (s *Snapshot) IsSnssaiSupportedInPlmn(snssai models.Snssai, plmnId models.PlmnId) (supported, found bool) {
+	plmn, ok := s.plmns[plmnId]
+	if !ok {
+		return false, false
+	}
+	_, supported = plmn.supported[newSnssaiKey(snssai.Sst, snssai.Sd)]
+	return supported, true
+}
+
+// Check whether the PLMN is configured as a supported PLMN, with supported S-NSSAIs or with S-NSSAI mappings
+func (s *Snapshot) IsPlmnConfigured(plmnId models.PlmnId) bool {
+	_, ok := s.configuredPlmns[plmnId]
+	return ok
+}
+
+// Check whether the S-NSSAI is configured as supported in any PLMN or TA, or with network slice instances
+func (s *Snapshot) IsSnssaiConfigured(snssai models.Snssai) bool {
+	_, ok := s.configuredSnssais[newSnssaiKey(snssai.Sst, snssai.Sd)]
+	return ok
+}
+
+// Check whether the TA is configured
+func (s *Snapshot) HasTa(tai models.Tai) bool {
+	_, ok := s.tas[newTaiKey(tai)]
+	return ok
+}
+
+// Check whether the S-NSSAI is supported in the TA in any Access Type
+func (s *Snapshot) IsSnssaiSupportedInTa(snssai models.Snssai, tai models.Tai) bool {
+	ta, ok := s.tas[newTaiKey(tai)]
+	if !ok {
+		return false
+	}
+	_, supported := ta.supported[newSnssaiKey(snssai.Sst, snssai.Sd)]
+	return supported
+}
+
+// Get Access Types in which the S-NSSAI is supported in the TA, and all Access Types of the TA
+// Whether the TA is configured is returned as well
+func (s *Snapshot) GetAccessTypeList(snssai models.Snssai, tai models.Tai) (
+	accessTypeList []models.AccessType, taAccessTypeList []models.AccessType, found bool,
+) {
+	ta, ok := s.tas[newTaiKey(tai)]
+	if !ok {
+		return nil, nil, false
+	}
+	return ta.accessTypesBySnssai[newSnssaiKey(snssai.Sst, snssai.Sd)], ta.accessTypeList, true
+}
+
+// Get restricted S-NSSAI list of the TA, and whether the TA is configured
+func (s *Snapshot) GetRestrictedSnssaiList(tai models.Tai) ([]models.RestrictedSnssai, bool) {
+	ta, ok := s.tas[newTaiKey(tai)]
+	if !ok {
+		return nil, false
+	}
+	return ta.restrictedSnssaiList, true
+}
+
+// Get NSI information list of the S-NSSAI
+func (s *Snapshot) GetNsiInformationList(snssai models.Snssai) []models.NsiInformation {
+	return s.nsis[newSnssaiKey(snssai.Sst, snssai.Sd)]
+}
+
+// Check whether the S-NSSAI is supported by the AMF in the TA, and whether the AMF is known
+func (s *Snapshot) IsSnssaiSupportedByAmf(snssai models.Snssai, nfId string, tai models.Tai) (supported, found bool) {
+	amf, ok := s.amfs[nfId]
+	if !ok {
+		return false, false
+	}
+	return amf.nssaiAvailability.supportsNssai(tai, []models.Snssai{snssai}), true
+}
+
+// Find the first AMF set in configuration which supports all S-NSSAIs of the NSSAI in the TA
+func (s *Snapshot) FindAmfSetServingNssai(tai models.Tai, nssai []models.Snssai) (AmfSetConfig, bool) {
+	for _, amfSet := range s.amfSetList {
+		if amfSet.nssaiAvailability.supportsNssai(tai, nssai) {
+			return amfSet.config, true
+		}
+	}
+	return AmfSetConfig{}, false
+}
+
+// Find all AMFs which support all S-NSSAIs of the NSSAI in the TA
+func (s *Snapshot) FindAmfsServingNssai(tai models.Tai, nssai []models.Snssai) []string {
+	var nfIds []string
+	for _, amf := range s.amfList {
+		if amf.nssaiAvailability.supportsNssai(tai, nssai) {
+			nfIds = append(nfIds, amf.nfId)
+		}
+	}
+	return nfIds
+}
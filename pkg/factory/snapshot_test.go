@@ -0,0 +1,57 @@
+package factory
+
+import (
+	"testing"
+
+	"github.com/free5gc/openapi/models"
+)
+
+func TestSnapshotConfigured(t *testing.T) {
+	servingPlmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	homePlmnId := models.PlmnId{Mcc: "466", Mnc: "92"}
+	s := newSnapshot(&Configuration{
+		SupportedPlmnList: []models.PlmnId{{Mcc: "001", Mnc: "01"}},
+		SupportedNssaiInPlmnList: []SupportedNssaiInPlmn{
+			{PlmnId: &servingPlmnId, SupportedSnssaiList: []models.Snssai{{Sst: 1, Sd: "010203"}}},
+		},
+		MappingListFromPlmn: []MappingFromPlmnConfig{{HomePlmnId: &homePlmnId}},
+		TaList: []TaConfig{
+			{
+				Tai:                 &models.Tai{PlmnId: &servingPlmnId, Tac: "000001"},
+				SupportedSnssaiList: []models.ExtSnssai{{Sst: 2, Sd: "ABCDEF"}},
+			},
+		},
+		NsiList: []NsiConfig{{Snssai: &models.Snssai{Sst: 3}}},
+	})
+
+	plmnTestCases := []struct {
+		plmnId models.PlmnId
+		expect bool
+	}{
+		{models.PlmnId{Mcc: "001", Mnc: "01"}, true},
+		{servingPlmnId, true},
+		{homePlmnId, true},
+		{models.PlmnId{Mcc: "999", Mnc: "99"}, false},
+	}
+	for _, tc := range plmnTestCases {
+		if s.IsPlmnConfigured(tc.plmnId) != tc.expect {
+			t.Errorf("Expected PLMN %+v configured: %v", tc.plmnId, tc.expect)
+		}
+	}
+
+	snssaiTestCases := []struct {
+		snssai models.Snssai
+		expect bool
+	}{
+		{models.Snssai{Sst: 1, Sd: "010203"}, true},
+		{models.Snssai{Sst: 2, Sd: "abcdef"}, true},
+		{models.Snssai{Sst: 3}, true},
+		{models.Snssai{Sst: 1}, false},
+		{models.Snssai{Sst: 1, Sd: "010204"}, false},
+	}
+	for _, tc := range snssaiTestCases {
+		if s.IsSnssaiConfigured(tc.snssai) != tc.expect {
+			t.Errorf("Expected S-NSSAI %+v configured: %v", tc.snssai, tc.expect)
+		}
+	}
+}
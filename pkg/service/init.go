@@ -11,15 +11,19 @@ import (
 	"os"
 	"runtime/debug"
 	"sync"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
 	nssf_context "github.com/free5gc/nssf/internal/context"
 	"github.com/free5gc/nssf/internal/logger"
+	"github.com/free5gc/nssf/internal/metrics/business"
+	"github.com/free5gc/nssf/internal/nsac"
 	"github.com/free5gc/nssf/internal/sbi"
 	"github.com/free5gc/nssf/internal/sbi/consumer"
 	"github.com/free5gc/nssf/internal/sbi/processor"
+	"github.com/free5gc/nssf/internal/tracing"
 	"github.com/free5gc/nssf/pkg/app"
 	"github.com/free5gc/nssf/pkg/factory"
 	"github.com/free5gc/util/metrics"
@@ -37,17 +41,28 @@ type NssfApp struct {
 	metricsServer *metrics.Server
 	processor     *processor.Processor
 	consumer      *consumer.Consumer
+	// Flush and stop export of traces
+	shutdownTracing func(context.Context) error
+	// Time allowed for in-flight requests and pending notifications to complete on shutdown
+	drainTimeout time.Duration
+	// Registration to NRF, which is retried until NRF is reachable, and the subscription to NF status of AMFs,
+	// which is kept renewed, are waited for before deregistration
+	registration sync.WaitGroup
 }
 
 var _ app.NssfApp = &NssfApp{}
 
+// Interval of retries to subscribe to NF status of AMFs, which is also the least delay of renewals
+var amfStatusRetryInterval = 5 * time.Second
+
 func NewApp(ctx context.Context, cfg *factory.Config, tlsKeyLogPath string) (*NssfApp, error) {
 	nssf_context.InitNssfContext()
 
 	nssf := &NssfApp{
-		cfg:     cfg,
-		wg:      sync.WaitGroup{},
-		nssfCtx: nssf_context.GetSelf(),
+		cfg:          cfg,
+		wg:           sync.WaitGroup{},
+		nssfCtx:      nssf_context.GetSelf(),
+		drainTimeout: cfg.GetShutdownDrainTimeout(),
 	}
 	nssf.SetLogEnable(cfg.GetLogEnable())
 	nssf.SetLogLevel(cfg.GetLogLevel())
@@ -55,23 +70,49 @@ func NewApp(ctx context.Context, cfg *factory.Config, tlsKeyLogPath string) (*Ns
 
 	nssf.ctx, nssf.cancel = context.WithCancel(ctx)
 
+	shutdownTracing, err := tracing.Init(cfg.GetTracingConfig(), nssf.nssfCtx.NfId())
+	if err != nil {
+		return nil, err
+	}
+	nssf.shutdownTracing = shutdownTracing
+
 	processor := processor.NewProcessor(nssf)
 	nssf.processor = processor
 
 	consumer := consumer.NewConsumer(nssf)
 	nssf.consumer = consumer
+	processor.SetNotifier(consumer.NotificationService)
 
-	sbiServer := sbi.NewServer(nssf, tlsKeyLogPath)
+	if cfg.IsNsacEnabled() {
+		if cfg.GetNsacMode() == factory.NsacModeNsacf {
+			processor.SetAdmissionController(consumer.NsacfService)
+		} else {
+			processor.SetAdmissionController(nsac.NewLocalController())
+		}
+	}
+
+	sbiServer, err := sbi.NewServer(nssf, tlsKeyLogPath)
+	if err != nil {
+		return nil, err
+	}
 	nssf.sbiServer = sbiServer
 
 	features := map[utils.MetricTypeEnabled]bool{utils.SBI: true}
 	customMetrics := make(map[utils.MetricTypeEnabled][]prometheus.Collector)
 	if cfg.AreMetricsEnabled() {
-		var err error
+		customMetrics[business.BUSINESS] = business.GetBusinessMetrics(cfg.GetMetricsNamespace())
+
 		if nssf.metricsServer, err = metrics.NewServer(
 			getInitMetrics(cfg, features, customMetrics), tlsKeyLogPath, logger.InitLog); err != nil {
 			return nil, err
 		}
+
+		// Gauges of the state loaded from configuration
+		cfg.RLock()
+		business.SetNssaiAvailabilityAmfGauge(len(cfg.Configuration.AmfList))
+		cfg.RUnlock()
+		business.SetNssaiAvailabilitySubscriptionGauge(nssf.nssfCtx.Subscriptions.Count())
+		business.SetNrfRegistrationGauge(false)
 	}
 
 	return nssf, nil
@@ -136,12 +177,13 @@ func (a *NssfApp) SetLogLevel(level string) {
 	}
 
 	logger.MainLog.Infof("Log level is set to [%s]", level)
-	if lvl == logger.Log.GetLevel() {
+	// A temporary level is replaced even if it is the same
+	if current := logger.GetLevel(""); lvl == current.Level && current.RevertAt.IsZero() {
 		return
 	}
 
 	a.cfg.SetLogLevel(level)
-	logger.Log.SetLevel(lvl)
+	logger.SetLevel("", lvl, 0)
 }
 
 func (a *NssfApp) SetReportCaller(reportCaller bool) {
@@ -157,34 +199,103 @@ func (a *NssfApp) SetReportCaller(reportCaller bool) {
 func (a *NssfApp) registerToNrf(ctx context.Context) error {
 	nssfContext := a.nssfCtx
 
-	var err error
-	_, nssfContext.NfId, err = a.consumer.SendRegisterNFInstance(ctx, nssfContext)
+	_, nfId, err := a.consumer.SendRegisterNFInstance(ctx, nssfContext)
 	if err != nil {
 		return fmt.Errorf("failed to register NSSF to NRF: %s", err.Error())
 	}
+	nssfContext.SetNfId(nfId)
+	nssfContext.NrfRegistered.Store(true)
+	business.SetNrfRegistrationGauge(true)
 
 	return nil
 }
 
+func (a *NssfApp) subscribeAmfStatus() bool {
+	_, problemDetails, err := a.consumer.SendCreateSubscriptionForAmfStatus(a.nssfCtx)
+	if problemDetails != nil {
+		logger.InitLog.Errorf("Subscribe NF status of AMF Failed Problem[%+v]", problemDetails)
+		return false
+	} else if err != nil {
+		logger.InitLog.Errorf("Subscribe NF status of AMF Error[%+v]", err)
+		return false
+	}
+	logger.InitLog.Infof("Subscribe NF status of AMF to NRF successfully")
+	return true
+}
+
+// Keep subscribed to NF status of AMFs until ctx is done, retrying the subscription on failure,
+// and renewing it before NRF expires it by subscribing again and removing the previous one
+func (a *NssfApp) maintainAmfStatusSubscription(ctx context.Context) {
+	for {
+		previousId := a.nssfCtx.NrfAmfStatusSubscription.Id()
+		delay := amfStatusRetryInterval
+		if a.subscribeAmfStatus() {
+			if previousId != "" {
+				a.removeAmfStatusSubscription(previousId)
+			}
+			validityTime := a.nssfCtx.NrfAmfStatusSubscription.ValidityTime()
+			if validityTime.IsZero() {
+				return
+			}
+			delay = amfStatusRenewalDelay(validityTime, amfStatusRetryInterval)
+			logger.InitLog.Infof("Subscription of AMF status expires at %s, renewed in %s",
+				validityTime.Format(time.RFC3339), delay)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+	}
+}
+
+// Renew a subscription when most of its validity has passed, leaving time for retries before it expires
+func amfStatusRenewalDelay(validityTime time.Time, minDelay time.Duration) time.Duration {
+	delay := time.Until(validityTime) * 4 / 5
+	if delay < minDelay {
+		return minDelay
+	}
+	return delay
+}
+
+func (a *NssfApp) unsubscribeAmfStatus() {
+	subscriptionId := a.nssfCtx.NrfAmfStatusSubscription.Id()
+	if subscriptionId == "" {
+		return
+	}
+	a.removeAmfStatusSubscription(subscriptionId)
+}
+
+func (a *NssfApp) removeAmfStatusSubscription(subscriptionId string) {
+	problemDetails, err := a.consumer.SendRemoveSubscription(subscriptionId)
+	if problemDetails != nil {
+		logger.InitLog.Errorf("Remove subscription of AMF status Failed Problem[%+v]", problemDetails)
+	} else if err != nil {
+		logger.InitLog.Errorf("Remove subscription of AMF status Error[%+v]", err)
+	} else {
+		a.nssfCtx.NrfAmfStatusSubscription.Clear(subscriptionId)
+		logger.InitLog.Infof("Remove subscription [%s] of AMF status from NRF successfully", subscriptionId)
+	}
+}
+
 func (a *NssfApp) deregisterFromNrf() {
-	problemDetails, err := a.consumer.SendDeregisterNFInstance(a.nssfCtx.NfId)
+	if !a.nssfCtx.NrfRegistered.Load() {
+		return
+	}
+	problemDetails, err := a.consumer.SendDeregisterNFInstance(a.nssfCtx.NfId())
 	if problemDetails != nil {
 		logger.InitLog.Errorf("Deregister NF instance Failed Problem[%+v]", problemDetails)
 	} else if err != nil {
 		logger.InitLog.Errorf("Deregister NF instance Error[%+v]", err)
 	} else {
+		a.nssfCtx.NrfRegistered.Store(false)
+		business.SetNrfRegistrationGauge(false)
 		logger.InitLog.Infof("Deregister from NRF successfully")
 	}
 }
 
 func (a *NssfApp) Start() {
-	err := a.registerToNrf(a.ctx)
-	if err != nil {
-		logger.MainLog.Errorf("register to NRF failed: %+v", err)
-	} else {
-		logger.MainLog.Infoln("register to NRF successfully")
-	}
-
 	// Graceful deregister when panic
 	defer func() {
 		if p := recover(); p != nil {
@@ -193,6 +304,8 @@ func (a *NssfApp) Start() {
 		}
 	}()
 
+	// SBI server is run ahead of registration to NRF, which could be retried for long,
+	// so that probes are answered in the meantime and NSSF is reported not ready until registered
 	a.sbiServer.Run(&a.wg)
 
 	if a.cfg.AreMetricsEnabled() && a.metricsServer != nil {
@@ -201,11 +314,29 @@ func (a *NssfApp) Start() {
 		}()
 	}
 
+	// Registration is cancelled on shutdown, which waits for it before deregistration,
+	// so that NSSF is not left registered by a registration completed during shutdown
+	a.registration.Add(1)
+	go func() {
+		defer a.registration.Done()
+
+		if err := a.registerToNrf(a.ctx); err != nil {
+			logger.MainLog.Errorf("register to NRF failed: %+v", err)
+			return
+		}
+		logger.MainLog.Infoln("register to NRF successfully")
+		a.maintainAmfStatusSubscription(a.ctx)
+	}()
+
+	// Termination is waited for, so that NSSF does not exit before draining is finished
+	a.wg.Add(1)
 	go a.listenShutdown(a.ctx)
+
 	a.Wait()
 }
 
 func (a *NssfApp) listenShutdown(ctx context.Context) {
+	defer a.wg.Done()
 	<-ctx.Done()
 	a.terminateProcedure()
 }
@@ -214,14 +345,49 @@ func (a *NssfApp) Terminate() {
 	a.cancel()
 }
 
+// Shut down NSSF in order, so that traffic is moved away before requests are no longer accepted,
+// and accepted requests and their notifications are completed before exit
 func (a *NssfApp) terminateProcedure() {
 	logger.MainLog.Infof("Terminating NSSF...")
+	// Report not ready, so that no more traffic is routed to this instance
+	a.nssfCtx.ShuttingDown.Store(true)
+	// Registration and renewal of subscription are cancelled along with ctx of NSSF
+	a.registration.Wait()
+	a.unsubscribeAmfStatus()
 	a.deregisterFromNrf()
-	a.sbiServer.Shutdown()
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), a.drainTimeout)
+	defer cancel()
+	logger.MainLog.Infof("Draining %d in-flight request(s) within %s", a.sbiServer.InflightRequests(), a.drainTimeout)
+	if err := a.sbiServer.Shutdown(drainCtx); err != nil {
+		logger.MainLog.Warnf("In-flight requests are not drained: %+v", err)
+	}
+	if err := a.processor.WaitNotifications(drainCtx); err != nil {
+		logger.MainLog.Warnf("Pending notifications are not sent: %+v", err)
+	}
+
+	// NSSAI availability and subscriptions are kept in memory only, so no state is flushed to a store,
+	// while metrics are served until here and traces are flushed when tracing is stopped
 	if a.metricsServer != nil {
 		a.metricsServer.Stop()
 		logger.MainLog.Infof("NSSF Metrics Server terminated")
 	}
+	a.stopTracing()
+}
+
+func (a *NssfApp) stopTracing() {
+	const shutdownTimeout time.Duration = 5 * time.Second
+
+	if a.shutdownTracing == nil {
+		return
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := a.shutdownTracing(shutdownCtx); err != nil {
+		logger.MainLog.Errorf("Tracing shutdown failed: %+v", err)
+	}
 }
 
 func (a *NssfApp) Wait() {
@@ -0,0 +1,435 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	nssf_context "github.com/free5gc/nssf/internal/context"
+	"github.com/free5gc/nssf/pkg/factory"
+	"github.com/free5gc/openapi/models"
+)
+
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %+v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestGracefulShutdown(t *testing.T) {
+	const (
+		amfId    = "469de254-2fe5-4ca0-8381-af3f500af77c"
+		amfSetId = "1"
+	)
+
+	// Subscriber taking longer than the interval of NRF registration retries to handle the notification,
+	// so that NSSF would exit before the notification is sent if it is not waited for
+	var notified atomic.Bool
+	subscriber := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2500 * time.Millisecond)
+		notified.Store(true)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer subscriber.Close()
+
+	plmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	tai := models.Tai{PlmnId: &plmnId, Tac: "000001"}
+	port := freePort(t)
+	cfg := &factory.Config{
+		Info: &factory.Info{Version: "1.0.2"},
+		Configuration: &factory.Configuration{
+			Sbi: &factory.Sbi{
+				Scheme:       models.UriScheme_HTTP,
+				RegisterIPv4: "127.0.0.1",
+				BindingIPv4:  "127.0.0.1",
+				Port:         port,
+			},
+			ServiceNameList: []models.ServiceName{models.ServiceName_NNSSF_NSSAIAVAILABILITY},
+			// NRF is not reachable, so that registration keeps being retried
+			NrfUri: fmt.Sprintf("http://127.0.0.1:%d", freePort(t)),
+			SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+				{PlmnId: &plmnId, SupportedSnssaiList: []models.Snssai{{Sst: 1, Sd: "010203"}}},
+			},
+			TaList: []factory.TaConfig{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "010203"}}},
+			},
+			AmfSetList: []factory.AmfSetConfig{
+				{AmfSetId: amfSetId, AmfList: []string{amfId}},
+			},
+			Notification: &factory.NotificationConfig{AllowPrivateTargets: true},
+			Shutdown:     &factory.Shutdown{DrainTimeout: 5 * time.Second},
+		},
+		Subscriptions: []factory.Subscription{
+			{
+				SubscriptionId: "1",
+				SubscriptionData: &models.NssfEventSubscriptionCreateData{
+					NfNssaiAvailabilityUri: subscriber.URL,
+					TaiList:                []models.Tai{tai},
+					Event:                  models.NssfEventType_SNSSAI_STATUS_CHANGE_REPORT,
+					AmfSetId:               amfSetId,
+				},
+			},
+		},
+		Logger: &factory.Logger{Enable: true, Level: "info"},
+	}
+	if _, err := cfg.Validate(); err != nil {
+		t.Fatalf("Invalid config: %+v", err)
+	}
+	factory.NssfConfig = cfg
+	// Subscriptions are kept in NSSF context across tests
+	nssf_context.GetSelf().Subscriptions.Remove("1")
+
+	nssf, err := NewApp(context.Background(), cfg, "")
+	if err != nil {
+		t.Fatalf("NewApp failed: %+v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		nssf.Start()
+		close(exited)
+	}()
+
+	baseUri := fmt.Sprintf("http://127.0.0.1:%d", port)
+	waitFor(t, "server to start", func() bool {
+		resp, getErr := http.Get(baseUri + "/livez")
+		if getErr != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	})
+
+	// Hold the configuration, so that the update of NSSAI availability is in flight when shutdown starts
+	cfg.Lock()
+	body, err := json.Marshal(models.NssaiAvailabilityInfo{
+		SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+			{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "010203"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %+v", err)
+	}
+	status := make(chan int, 1)
+	go func() {
+		req, _ := http.NewRequest(http.MethodPut,
+			baseUri+factory.NssfNssaiavailResUriPrefix+"/nssai-availability/"+amfId, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, putErr := http.DefaultClient.Do(req)
+		if putErr != nil {
+			status <- 0
+			return
+		}
+		resp.Body.Close()
+		status <- resp.StatusCode
+	}()
+	waitFor(t, "request to be in flight", func() bool {
+		return nssf.sbiServer.InflightRequests() == 1
+	})
+
+	nssf.Terminate()
+
+	// Not ready and no longer accepting requests while the request is drained
+	waitFor(t, "requests to be no longer accepted", func() bool {
+		conn, dialErr := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if dialErr != nil {
+			return true
+		}
+		conn.Close()
+		return false
+	})
+	if !nssf.Context().ShuttingDown.Load() {
+		t.Errorf("Expected NSSF to be marked shutting down")
+	}
+	select {
+	case <-exited:
+		t.Fatalf("Expected NSSF not to exit before the in-flight request is completed")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cfg.Unlock()
+	if code := <-status; code != http.StatusCreated {
+		t.Errorf("Expected in-flight request to be completed with status %d, got: %d", http.StatusCreated, code)
+	}
+
+	select {
+	case <-exited:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("Timeout waiting for NSSF to exit")
+	}
+	if !notified.Load() {
+		t.Errorf("Expected pending notification to be sent before exit")
+	}
+}
+
+func TestShutdownDuringNrfRegistration(t *testing.T) {
+	registering := make(chan struct{})
+	var registered, deregistered atomic.Bool
+	nrf := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/nnrf-nfm/v1/nf-instances/"):
+			close(registering)
+			// Registration is not completed until it is cancelled by shutdown
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+				registered.Store(true)
+				w.WriteHeader(http.StatusCreated)
+			}
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/nnrf-nfm/v1/nf-instances/"):
+			deregistered.Store(true)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	// NRF client speaks HTTP/2 without TLS
+	nrf.Config.Protocols = &http.Protocols{}
+	nrf.Config.Protocols.SetHTTP1(true)
+	nrf.Config.Protocols.SetUnencryptedHTTP2(true)
+	nrf.Start()
+	defer nrf.Close()
+
+	plmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	cfg := &factory.Config{
+		Info: &factory.Info{Version: "1.0.2"},
+		Configuration: &factory.Configuration{
+			Sbi: &factory.Sbi{
+				Scheme:       models.UriScheme_HTTP,
+				RegisterIPv4: "127.0.0.1",
+				BindingIPv4:  "127.0.0.1",
+				Port:         freePort(t),
+			},
+			ServiceNameList: []models.ServiceName{models.ServiceName_NNSSF_NSSELECTION},
+			NrfUri:          nrf.URL,
+			SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+				{PlmnId: &plmnId, SupportedSnssaiList: []models.Snssai{{Sst: 1, Sd: "010203"}}},
+			},
+		},
+		Logger: &factory.Logger{Enable: true, Level: "info"},
+	}
+	if _, err := cfg.Validate(); err != nil {
+		t.Fatalf("Invalid config: %+v", err)
+	}
+	factory.NssfConfig = cfg
+
+	nssf, err := NewApp(context.Background(), cfg, "")
+	if err != nil {
+		t.Fatalf("NewApp failed: %+v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		nssf.Start()
+		close(exited)
+	}()
+
+	select {
+	case <-registering:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timeout waiting for registration to NRF")
+	}
+	nssf.Terminate()
+
+	// Shutdown waits for registration to be cancelled, instead of waiting for NRF to respond
+	select {
+	case <-exited:
+	case <-time.After(3 * time.Second):
+		t.Fatalf("Timeout waiting for NSSF to exit")
+	}
+	if registered.Load() || nssf.Context().NrfRegistered.Load() {
+		t.Errorf("Expected registration to be cancelled by shutdown")
+	}
+	if deregistered.Load() {
+		t.Errorf("Expected no deregistration of NSSF which is not registered")
+	}
+}
+
+func TestAmfStatusSubscriptionRenewal(t *testing.T) {
+	const amfId = "469de254-2fe5-4ca0-8381-af3f500af77c"
+
+	defer func(interval time.Duration) {
+		amfStatusRetryInterval = interval
+	}(amfStatusRetryInterval)
+	amfStatusRetryInterval = 50 * time.Millisecond
+
+	var (
+		mu sync.Mutex
+		// Notification URIs of subscriptions created in NRF in order
+		notifyUris []string
+		removed    []string
+	)
+	nrf := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/nnrf-nfm/v1/nf-instances/"):
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Location", "http://"+r.Host+r.URL.Path)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"nfInstanceId":"` + strings.TrimPrefix(r.URL.Path, "/nnrf-nfm/v1/nf-instances/") +
+				`","nfType":"NSSF","nfStatus":"REGISTERED"}`))
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/nnrf-nfm/v1/nf-instances/"):
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == http.MethodPost && r.URL.Path == "/nnrf-nfm/v1/subscriptions":
+			var subscriptionData models.NrfNfManagementSubscriptionData
+			if err := json.NewDecoder(r.Body).Decode(&subscriptionData); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			mu.Lock()
+			notifyUris = append(notifyUris, subscriptionData.NfStatusNotificationUri)
+			subscriptionData.SubscriptionId = fmt.Sprintf("%d", len(notifyUris))
+			mu.Unlock()
+			// The first subscription expires shortly, so that it is renewed during the test,
+			// while the renewed one is kept until the end of the test
+			validityTime := time.Now().Add(time.Hour)
+			if subscriptionData.SubscriptionId == "1" {
+				validityTime = time.Now().Add(300 * time.Millisecond)
+			}
+			subscriptionData.ValidityTime = &validityTime
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Location", "http://"+r.Host+r.URL.Path+"/"+subscriptionData.SubscriptionId)
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(subscriptionData)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/nnrf-nfm/v1/subscriptions/"):
+			mu.Lock()
+			removed = append(removed, strings.TrimPrefix(r.URL.Path, "/nnrf-nfm/v1/subscriptions/"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	// NRF client speaks HTTP/2 without TLS
+	nrf.Config.Protocols = &http.Protocols{}
+	nrf.Config.Protocols.SetHTTP1(true)
+	nrf.Config.Protocols.SetUnencryptedHTTP2(true)
+	nrf.Start()
+	defer nrf.Close()
+
+	plmnId := models.PlmnId{Mcc: "208", Mnc: "93"}
+	tai := models.Tai{PlmnId: &plmnId, Tac: "000001"}
+	cfg := &factory.Config{
+		Info: &factory.Info{Version: "1.0.2"},
+		Configuration: &factory.Configuration{
+			Sbi: &factory.Sbi{
+				Scheme:       models.UriScheme_HTTP,
+				RegisterIPv4: "127.0.0.1",
+				BindingIPv4:  "127.0.0.1",
+				Port:         freePort(t),
+			},
+			ServiceNameList: []models.ServiceName{models.ServiceName_NNSSF_NSSAIAVAILABILITY},
+			NrfUri:          nrf.URL,
+			SupportedNssaiInPlmnList: []factory.SupportedNssaiInPlmn{
+				{PlmnId: &plmnId, SupportedSnssaiList: []models.Snssai{{Sst: 1, Sd: "010203"}}},
+			},
+			TaList: []factory.TaConfig{
+				{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "010203"}}},
+			},
+			AmfList: []factory.AmfConfig{
+				{
+					NfId: amfId,
+					SupportedNssaiAvailabilityData: []models.SupportedNssaiAvailabilityData{
+						{Tai: &tai, SupportedSnssaiList: []models.ExtSnssai{{Sst: 1, Sd: "010203"}}},
+					},
+				},
+			},
+		},
+		Logger: &factory.Logger{Enable: true, Level: "info"},
+	}
+	if _, err := cfg.Validate(); err != nil {
+		t.Fatalf("Invalid config: %+v", err)
+	}
+	factory.NssfConfig = cfg
+
+	nssf, err := NewApp(context.Background(), cfg, "")
+	if err != nil {
+		t.Fatalf("NewApp failed: %+v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		nssf.Start()
+		close(exited)
+	}()
+	defer func() {
+		nssf.Terminate()
+		<-exited
+	}()
+
+	// Subscription is renewed before it expires, and the previous one is removed
+	waitFor(t, "subscription to be renewed", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(notifyUris) >= 2 && len(removed) >= 1
+	})
+	mu.Lock()
+	oldUri, removedId := notifyUris[0], removed[0]
+	mu.Unlock()
+	if removedId != "1" {
+		t.Errorf("Expected previous subscription 1 to be removed, got: %s", removedId)
+	}
+	waitFor(t, "renewed subscription to be kept", func() bool {
+		return nssf.Context().NrfAmfStatusSubscription.Id() != "1"
+	})
+
+	notify := func(uri string) int {
+		body, marshalErr := json.Marshal(models.NrfNfManagementNotificationData{
+			Event:         models.NotificationEventType_DEREGISTERED,
+			NfInstanceUri: nrf.URL + "/nnrf-nfm/v1/nf-instances/" + amfId,
+		})
+		if marshalErr != nil {
+			t.Fatalf("Marshal failed: %+v", marshalErr)
+		}
+		resp, postErr := http.Post(uri, "application/json", bytes.NewReader(body))
+		if postErr != nil {
+			t.Fatalf("Post failed: %+v", postErr)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Notifications to the removed subscription are no longer accepted
+	if code := notify(oldUri); code != http.StatusNotFound {
+		t.Errorf("Expected notification of removed subscription to be rejected with %d, got: %d",
+			http.StatusNotFound, code)
+	}
+	cfg.RLock()
+	amfCount := len(cfg.Configuration.AmfList)
+	cfg.RUnlock()
+	if amfCount != 1 {
+		t.Errorf("Expected AMF not to be purged by notification of removed subscription")
+	}
+	// Notification URI of the current subscription is the latest one given to NRF
+	mu.Lock()
+	currentUri := notifyUris[len(notifyUris)-1]
+	mu.Unlock()
+	if code := notify(currentUri); code != http.StatusNoContent {
+		t.Errorf("Expected notification of current subscription to be accepted with %d, got: %d",
+			http.StatusNoContent, code)
+	}
+	cfg.RLock()
+	amfCount = len(cfg.Configuration.AmfList)
+	cfg.RUnlock()
+	if amfCount != 0 {
+		t.Errorf("Expected AMF to be purged by notification of current subscription")
+	}
+}